@@ -13,8 +13,10 @@ package appsec
 
 import (
 	"context"
+	"net/http"
 	"sync"
 
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec/events"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
@@ -32,6 +34,8 @@ var appsecDisabledLog sync.Once
 // AppSec is disabled or the given context is incorrect.
 // Note that passing the raw bytes of the HTTP request body is not expected and would
 // result in inaccurate attack detection.
+// Calls are also ignored when the request body was already parsed and monitored
+// by the HTTP integration, which is enabled with DD_APPSEC_BODY_PARSING_SIZE_LIMIT.
 // This function always returns nil when appsec is disabled.
 func MonitorParsedHTTPBody(ctx context.Context, body interface{}) error {
 	if !appsec.Enabled() {
@@ -41,6 +45,33 @@ func MonitorParsedHTTPBody(ctx context.Context, body interface{}) error {
 	return httpsec.MonitorParsedBody(ctx, body)
 }
 
+// OnSecurityEvent sets the function called with every security event detected by the WAF in the requests monitored
+// by appsec, regardless of the trace sampling and rate limiting, so that they can also be fed to other systems such
+// as audit logs. The function is called with the context of the monitored request, which holds its span, by the
+// goroutine monitoring the request and must therefore return quickly. Only the last function set is called, and
+// setting a nil function removes it. The function only applies to the requests starting after the call.
+func OnSecurityEvent(fn func(ctx context.Context, e events.SecurityEvent)) {
+	sharedsec.SetSecurityEventCallback(fn)
+}
+
+// SetHTTPBlockingHandler sets the function returning the handler writing the HTTP response of the requests blocked
+// or redirected by appsec, instead of the default blocking responses configured by the HTML and JSON templates. The
+// function is given the blocking action, which includes the request route and the security response id when
+// provided, so that the response can depend on them. It can return nil to fall back to the default blocking response.
+// Setting a nil function removes the current one.
+func SetHTTPBlockingHandler(fn func(a events.BlockingAction) http.Handler) {
+	sharedsec.SetHTTPBlockingHandler(fn)
+}
+
+// SetGRPCBlockingError sets the function returning the error of the gRPC calls blocked by appsec, instead of the
+// default one built out of the gRPC status code of the blocking action. The error should be a gRPC status error, as
+// returned by google.golang.org/grpc/status.Error, to control the status returned to the client. The function is
+// given the blocking action, whose route is the full gRPC method name. It can return nil to fall back to the default
+// blocking error. Setting a nil function removes the current one.
+func SetGRPCBlockingError(fn func(a events.BlockingAction) error) {
+	sharedsec.SetGRPCBlockingError(fn)
+}
+
 // SetUser wraps tracer.SetUser() and extends it with user blocking.
 // On top of associating the authenticated user information to the service entry span,
 // it checks whether the given user ID is blocked or not by returning an error when it is.
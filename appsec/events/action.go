@@ -0,0 +1,25 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package events
+
+// BlockingAction describes the WAF action blocking a request, as given to the functions customizing the blocking
+// responses registered with appsec.SetHTTPBlockingHandler and appsec.SetGRPCBlockingError.
+type BlockingAction struct {
+	// Type is the type of the WAF action: either "block_request" or "redirect_request".
+	Type string
+	// StatusCode is the HTTP status code of the default blocking response.
+	StatusCode int
+	// GRPCStatusCode is the gRPC status code of the default blocking response.
+	GRPCStatusCode int
+	// Location is the location the request is redirected to by "redirect_request" actions.
+	Location string
+	// SecurityResponseID is the identifier of the blocking response, when provided by the WAF, which can be
+	// included in the response to correlate it with the security event.
+	SecurityResponseID string
+	// Route is the route of the blocked request when known by the integration, or the request path otherwise. It
+	// is the full method name for gRPC requests.
+	Route string
+}
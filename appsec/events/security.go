@@ -0,0 +1,29 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package events
+
+// SecurityEvent is a security event detected by the WAF, as reported to the
+// function registered with appsec.OnSecurityEvent.
+type SecurityEvent struct {
+	// RuleID is the id of the WAF rule that matched.
+	RuleID string
+	// RuleName is the name of the WAF rule that matched.
+	RuleName string
+	// Category is the category of the WAF rule that matched, such as "attack_attempt" or "security_response".
+	Category string
+	// Addresses are the WAF addresses matched by the rule, such as "server.request.query" or "http.client_ip".
+	Addresses []string
+	// ClientIP is the IP address of the client of the request, if known.
+	ClientIP string
+	// Route is the route of the request when known by the integration, or the request path otherwise. It is the
+	// full method name for gRPC requests.
+	Route string
+	// Actions are the types of the actions returned by the WAF along with the event, such as "block_request",
+	// "redirect_request" or "generate_stack". It is empty when the rule only monitors requests.
+	Actions []string
+	// Blocked is true when the request was blocked or redirected by the WAF along with the event.
+	Blocked bool
+}
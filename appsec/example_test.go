@@ -6,11 +6,14 @@
 package appsec_test
 
 import (
+	"context"
 	"encoding/json"
 	"io"
+	"log"
 	"net/http"
 
 	"gopkg.in/DataDog/dd-trace-go.v1/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec/events"
 	echotrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/labstack/echo.v4"
 	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
 
@@ -79,3 +82,17 @@ func ExampleSetUser() {
 		w.Write([]byte("User monitored using AppSec SetUser SDK\n"))
 	})
 }
+
+// Feed the security events detected by the WAF to an audit log
+func ExampleOnSecurityEvent() {
+	appsec.OnSecurityEvent(func(ctx context.Context, e events.SecurityEvent) {
+		// This function is called by the goroutine monitoring the request and must return quickly
+		log.Printf("security event: rule=%s route=%s client_ip=%s blocked=%t", e.RuleID, e.Route, e.ClientIP, e.Blocked)
+	})
+
+	mux := httptrace.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Hello World!\n"))
+	})
+	http.ListenAndServe(":8080", mux)
+}
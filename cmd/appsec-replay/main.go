@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Command appsec-replay replays recorded HTTP requests through the AppSec WAF
+// with a given ruleset, without any agent or backend, and reports the rules
+// matched, the actions triggered and the time spent for each request. It is
+// meant to be used as a regression harness for WAF rulesets in CI.
+//
+// The recorded requests are read from the files given as arguments, or from
+// the standard input, and are either HAR files or JSONL files made of one
+// request per line:
+//
+//	{"method":"POST","url":"https://example.com/login?x=1","headers":{"Content-Type":["application/json"]},"body":"{}","remote_addr":"1.2.3.4:5678","response":{"status":200,"headers":{},"body":""}}
+//
+// The results are written to the standard output as one JSON object per
+// request. The command exits with the status 1 when a request couldn't be
+// replayed, and with the status 2 when -fail-on-match is set and at least one
+// rule matched.
+//
+// Usage:
+//
+//	appsec-replay [-rules rules.json] [-format auto|har|jsonl] [-waf-timeout 1s] [-response-body] [-fail-on-match] [file...]
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("appsec-replay", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		rulesFile    = fs.String("rules", "", "path to the WAF ruleset `file` (default: the recommended ruleset)")
+		format       = fs.String("format", "auto", "format of the recorded requests: auto, har or jsonl")
+		wafTimeout   = fs.Duration("waf-timeout", 0, "WAF timeout per run (default: DD_APPSEC_WAF_TIMEOUT or 1ms)")
+		responseBody = fs.Bool("response-body", false, "inspect the recorded response bodies")
+		failOnMatch  = fs.Bool("fail-on-match", false, "exit with the status 2 when a rule matched")
+	)
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	var rules []byte
+	if *rulesFile != "" {
+		var err error
+		if rules, err = os.ReadFile(*rulesFile); err != nil {
+			fmt.Fprintf(stderr, "appsec-replay: %v\n", err)
+			return 1
+		}
+	}
+	r, err := startAppSec(rules, *wafTimeout, *responseBody)
+	if err != nil {
+		fmt.Fprintf(stderr, "appsec-replay: %v\n", err)
+		return 1
+	}
+	defer appsec.Stop()
+
+	inputs := fs.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+	enc := json.NewEncoder(stdout)
+	index, matched := 0, false
+	for _, in := range inputs {
+		records, err := readInput(in, stdin, *format)
+		if err != nil {
+			fmt.Fprintf(stderr, "appsec-replay: %s: %v\n", in, err)
+			return 1
+		}
+		for _, rec := range records {
+			res, err := r.replay(index, rec)
+			if err != nil {
+				fmt.Fprintf(stderr, "appsec-replay: %s: request %d: %v\n", in, index, err)
+				return 1
+			}
+			if err := enc.Encode(res); err != nil {
+				fmt.Fprintf(stderr, "appsec-replay: %v\n", err)
+				return 1
+			}
+			matched = matched || len(res.Rules) > 0
+			index++
+		}
+	}
+	if *failOnMatch && matched {
+		return 2
+	}
+	return 0
+}
+
+// readInput reads the recorded requests out of the given file, or out of stdin
+// when the file is "-".
+func readInput(name string, stdin io.Reader, format string) ([]record, error) {
+	if name == "-" {
+		return readRecords(stdin, format)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readRecords(f, format)
+}
@@ -0,0 +1,175 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// record is a recorded HTTP request, along with its recorded response if any.
+type record struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	RemoteAddr string      `json:"remote_addr,omitempty"`
+	Response   *response   `json:"response,omitempty"`
+}
+
+// response is a recorded HTTP response.
+type response struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// readRecords reads the recorded requests out of r according to format, which
+// is either "har", "jsonl", or "auto" to detect it out of the first character
+// of the input: HAR files are JSON objects, while JSONL files are made of one
+// JSON object per line.
+func readRecords(r io.Reader, format string) ([]record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if format == "auto" {
+		format = detectFormat(data)
+	}
+	switch format {
+	case "har":
+		return readHAR(data)
+	case "jsonl":
+		return readJSONL(data)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// detectFormat returns "har" when data is a single JSON object holding the
+// "log" key, and "jsonl" otherwise.
+func detectFormat(data []byte) string {
+	var har struct {
+		Log json.RawMessage `json:"log"`
+	}
+	if err := json.Unmarshal(data, &har); err == nil && har.Log != nil {
+		return "har"
+	}
+	return "jsonl"
+}
+
+// readJSONL reads one record per non-empty line of data.
+func readJSONL(data []byte) ([]record, error) {
+	var records []record
+	s := bufio.NewScanner(bytes.NewReader(data))
+	s.Buffer(nil, len(data)+1)
+	for line := 1; s.Scan(); line++ {
+		b := bytes.TrimSpace(s.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		var rec record
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records, s.Err()
+}
+
+// The subset of the HAR 1.2 format describing the requests and responses.
+type (
+	harFile struct {
+		Log struct {
+			Entries []harEntry `json:"entries"`
+		} `json:"log"`
+	}
+	harEntry struct {
+		Request  harRequest  `json:"request"`
+		Response harResponse `json:"response"`
+	}
+	harRequest struct {
+		Method   string      `json:"method"`
+		URL      string      `json:"url"`
+		Headers  []harHeader `json:"headers"`
+		PostData *struct {
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+		} `json:"postData"`
+	}
+	harResponse struct {
+		Status  int         `json:"status"`
+		Headers []harHeader `json:"headers"`
+		Content struct {
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+			Encoding string `json:"encoding"`
+		} `json:"content"`
+	}
+	harHeader struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+)
+
+// readHAR reads the records out of the entries of a HAR file.
+func readHAR(data []byte) ([]record, error) {
+	var f harFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	records := make([]record, 0, len(f.Log.Entries))
+	for i, e := range f.Log.Entries {
+		rec := record{
+			Method:  e.Request.Method,
+			URL:     e.Request.URL,
+			Headers: harHeaders(e.Request.Headers),
+		}
+		if e.Request.PostData != nil {
+			rec.Body = e.Request.PostData.Text
+			if rec.Headers.Get("Content-Type") == "" && e.Request.PostData.MimeType != "" {
+				rec.Headers.Set("Content-Type", e.Request.PostData.MimeType)
+			}
+		}
+		// Browsers record a zero status for the requests that didn't get a response
+		if e.Response.Status > 0 {
+			body := e.Response.Content.Text
+			if e.Response.Content.Encoding == "base64" {
+				b, err := base64.StdEncoding.DecodeString(body)
+				if err != nil {
+					return nil, fmt.Errorf("entry %d: response content: %w", i, err)
+				}
+				body = string(b)
+			}
+			rec.Response = &response{
+				Status:  e.Response.Status,
+				Headers: harHeaders(e.Response.Headers),
+				Body:    body,
+			}
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// harHeaders returns the given HAR headers as an http.Header, leaving out the
+// HTTP/2 pseudo-headers.
+func harHeaders(headers []harHeader) http.Header {
+	h := make(http.Header, len(headers))
+	for _, hdr := range headers {
+		if strings.HasPrefix(hdr.Name, ":") {
+			continue
+		}
+		h.Add(hdr.Name, hdr.Value)
+	}
+	return h
+}
@@ -0,0 +1,308 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/config"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/httpsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/trace"
+)
+
+// noRateLimit is the AppSec trace rate limit making sure no security event is
+// dropped while replaying the requests as fast as possible: one token per
+// nanosecond.
+const noRateLimit = int64(time.Second)
+
+// replayer replays recorded HTTP requests through the AppSec HTTP monitoring
+// and reports the outcome of the WAF for each of them.
+type replayer struct {
+	// onMatch maps the rule ids to the ids of the actions they trigger.
+	onMatch map[string][]string
+	// responseBody enables the inspection of the recorded response bodies.
+	responseBody bool
+}
+
+// startAppSec starts AppSec with the given ruleset, or the default one when
+// rules is nil, and returns a replayer using it. AppSec must be stopped once
+// the replay is done.
+func startAppSec(rules []byte, wafTimeout time.Duration, responseBody bool) (*replayer, error) {
+	rm, err := config.NewRulesManeger(rules)
+	if err != nil {
+		return nil, fmt.Errorf("could not load the ruleset: %w", err)
+	}
+	if err := os.Setenv(config.EnvEnabled, "true"); err != nil {
+		return nil, err
+	}
+	appsec.Start(func(c *config.Config) {
+		c.RulesManager = rm
+		c.TraceRateLimit = noRateLimit
+		// Remote configuration updates would make the results non-reproducible
+		c.RC = nil
+		if wafTimeout > 0 {
+			c.WAFTimeout = wafTimeout
+		}
+	})
+	if !appsec.Enabled() {
+		return nil, errors.New("appsec could not start: the WAF is not supported on this platform or the ruleset is invalid (set DD_TRACE_DEBUG=true for details)")
+	}
+	return &replayer{
+		onMatch:      ruleActions(rm.Latest.Rules, rm.Latest.CustomRules),
+		responseBody: responseBody,
+	}, nil
+}
+
+// ruleActions returns the action ids listed in the on_match field of the given
+// rules, indexed by rule id.
+func ruleActions(rules ...[]any) map[string][]string {
+	actions := make(map[string][]string)
+	for _, list := range rules {
+		for _, r := range list {
+			rule, _ := r.(map[string]any)
+			id, _ := rule["id"].(string)
+			onMatch, _ := rule["on_match"].([]any)
+			for _, a := range onMatch {
+				if a, ok := a.(string); ok {
+					actions[id] = append(actions[id], a)
+				}
+			}
+		}
+	}
+	return actions
+}
+
+// result is the outcome of the replay of a recorded request.
+type result struct {
+	Index       int      `json:"index"`
+	Method      string   `json:"method"`
+	URL         string   `json:"url"`
+	Rules       []string `json:"rules"`
+	Actions     []string `json:"actions"`
+	Blocked     bool     `json:"blocked"`
+	Status      int      `json:"status"`
+	Duration    float64  `json:"duration_us"`
+	WAFDuration float64  `json:"waf_duration_us"`
+	WAFTimeouts uint64   `json:"waf_timeouts"`
+}
+
+// replay replays the recorded request through the same AppSec HTTP handler
+// wrapper the HTTP integrations use. The wrapped handler writes the recorded
+// response, if any, so that the response addresses are monitored too.
+func (r *replayer) replay(index int, rec record) (result, error) {
+	req, err := newRequest(rec)
+	if err != nil {
+		return result{}, err
+	}
+	res := result{
+		Index:  index,
+		Method: req.Method,
+		URL:    rec.URL,
+	}
+
+	span := newRecordingSpan()
+	w := newReplayWriter(r.responseBody)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if rec.Response == nil {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		for k, v := range rec.Response.Headers {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.Response.Status)
+		w.Write([]byte(rec.Response.Body))
+	})
+	cfg := &httpsec.Config{}
+	if r.responseBody {
+		cfg.ResponseBody = w
+	}
+
+	start := time.Now()
+	httpsec.WrapHandler(handler, span, nil, cfg).ServeHTTP(w, req)
+	res.Duration = float64(time.Since(start).Nanoseconds()) / float64(time.Microsecond)
+
+	res.Status = w.Status()
+	res.Blocked, _ = span.tags[trace.BlockedRequestTag].(bool)
+	res.WAFDuration, _ = span.tags["_dd.appsec.waf.duration"].(float64)
+	res.WAFTimeouts, _ = span.tags["_dd.appsec.waf.timeouts"].(uint64)
+	res.Rules, err = matchedRules(span.tags["_dd.appsec.json"])
+	if err != nil {
+		return result{}, err
+	}
+	res.Actions = r.actions(res.Rules)
+	return res, nil
+}
+
+// actions returns the sorted set of actions triggered by the given rules.
+func (r *replayer) actions(rules []string) []string {
+	set := make(map[string]struct{})
+	for _, id := range rules {
+		for _, a := range r.onMatch[id] {
+			set[a] = struct{}{}
+		}
+	}
+	actions := make([]string, 0, len(set))
+	for a := range set {
+		actions = append(actions, a)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// matchedRules returns the sorted set of rule ids out of the security events
+// span tag value.
+func matchedRules(tag any) ([]string, error) {
+	rules := []string{}
+	s, _ := tag.(string)
+	if s == "" {
+		return rules, nil
+	}
+	var events struct {
+		Triggers []struct {
+			Rule struct {
+				ID string `json:"id"`
+			} `json:"rule"`
+		} `json:"triggers"`
+	}
+	if err := json.Unmarshal([]byte(s), &events); err != nil {
+		return nil, fmt.Errorf("could not parse the security events: %w", err)
+	}
+	set := make(map[string]struct{}, len(events.Triggers))
+	for _, t := range events.Triggers {
+		if _, ok := set[t.Rule.ID]; ok {
+			continue
+		}
+		set[t.Rule.ID] = struct{}{}
+		rules = append(rules, t.Rule.ID)
+	}
+	sort.Strings(rules)
+	return rules, nil
+}
+
+// newRequest returns the server request of the given record.
+func newRequest(rec record) (*http.Request, error) {
+	u, err := url.Parse(rec.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url %q: %w", rec.URL, err)
+	}
+	method := rec.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	// Server requests only have the request URI
+	req := httptest.NewRequest(method, u.RequestURI(), strings.NewReader(rec.Body))
+	if u.Host != "" {
+		req.Host = u.Host
+	}
+	for k, v := range rec.Headers {
+		for _, v := range v {
+			req.Header.Add(k, v)
+		}
+	}
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+		req.Header.Del("Host")
+	}
+	if rec.RemoteAddr != "" {
+		req.RemoteAddr = rec.RemoteAddr
+	}
+	return req, nil
+}
+
+// replayWriter is the response writer of the replayed requests. It holds back
+// the entire response, which is never sent anywhere, and implements the
+// httpsec.ResponseBodyBuffer interface when the response body inspection is
+// enabled.
+type replayWriter struct {
+	header  http.Header
+	status  int
+	body    bytes.Buffer
+	inspect bool
+}
+
+var _ httpsec.ResponseBodyBuffer = (*replayWriter)(nil)
+
+func newReplayWriter(inspect bool) *replayWriter {
+	return &replayWriter{header: make(http.Header), inspect: inspect}
+}
+
+func (w *replayWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *replayWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *replayWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+// Status returns the response status code.
+func (w *replayWriter) Status() int {
+	return w.status
+}
+
+// Body returns the response body when its inspection is enabled.
+func (w *replayWriter) Body() ([]byte, string, bool) {
+	if !w.inspect {
+		return nil, "", false
+	}
+	return w.body.Bytes(), w.header.Get("Content-Type"), true
+}
+
+func (w *replayWriter) SetBody(body []byte) {
+	w.body.Reset()
+	w.body.Write(body)
+}
+
+// Discard drops the response so that the blocking response replaces it.
+func (w *replayWriter) Discard() {
+	w.header = make(http.Header)
+	w.status = 0
+	w.body.Reset()
+	w.inspect = false
+}
+
+func (w *replayWriter) Commit() error {
+	w.inspect = false
+	return nil
+}
+
+// recordingSpan is a span recording its tags, which is all the AppSec HTTP
+// monitoring needs to report its results.
+type recordingSpan struct {
+	tags map[string]any
+}
+
+var _ ddtrace.Span = (*recordingSpan)(nil)
+
+func newRecordingSpan() *recordingSpan {
+	return &recordingSpan{tags: make(map[string]any)}
+}
+
+func (s *recordingSpan) SetTag(key string, value any) { s.tags[key] = value }
+func (*recordingSpan) SetOperationName(string)        {}
+func (*recordingSpan) BaggageItem(string) string      { return "" }
+func (*recordingSpan) SetBaggageItem(string, string)  {}
+func (*recordingSpan) Finish(...ddtrace.FinishOption) {}
+func (*recordingSpan) Context() ddtrace.SpanContext   { return nil }
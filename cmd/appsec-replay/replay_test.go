@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+
+	waf "github.com/DataDog/go-libddwaf/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRecords(t *testing.T) {
+	t.Run("jsonl", func(t *testing.T) {
+		f, err := os.Open("testdata/requests.jsonl")
+		require.NoError(t, err)
+		defer f.Close()
+		records, err := readRecords(f, "auto")
+		require.NoError(t, err)
+		require.Len(t, records, 4)
+		assert.Equal(t, "1.2.3.4:1234", records[2].RemoteAddr)
+		assert.Equal(t, "x=$globals", records[3].Body)
+		assert.Equal(t, "application/x-www-form-urlencoded", records[3].Headers.Get("Content-Type"))
+		require.NotNil(t, records[1].Response)
+		assert.Equal(t, http.StatusOK, records[1].Response.Status)
+	})
+
+	t.Run("har", func(t *testing.T) {
+		f, err := os.Open("testdata/requests.har")
+		require.NoError(t, err)
+		defer f.Close()
+		records, err := readRecords(f, "auto")
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, http.Header{"User-Agent": {"Mozilla/5.0"}}, records[0].Headers)
+		require.NotNil(t, records[0].Response)
+		assert.Equal(t, `{"ok":true}`, records[0].Response.Body)
+		assert.Equal(t, "application/x-www-form-urlencoded", records[1].Headers.Get("Content-Type"))
+		assert.Nil(t, records[1].Response)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := readRecords(bytes.NewBufferString("{}\nnot json\n"), "jsonl")
+		require.ErrorContains(t, err, "line 2")
+		_, err = readRecords(bytes.NewBufferString("{}"), "xml")
+		require.Error(t, err)
+	})
+}
+
+func TestRun(t *testing.T) {
+	if ok, _ := waf.Health(); !ok {
+		t.Skip("the waf is not supported on this platform")
+	}
+
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		expected []result
+	}{
+		{
+			name: "jsonl",
+			args: []string{"testdata/requests.jsonl"},
+			expected: []result{
+				{Method: "GET", URL: "http://example.com/", Rules: []string{}, Actions: []string{}, Status: 200},
+				{Method: "GET", URL: "http://example.com/search?q=%3Cscript%3Ealert(1)%3C/script%3E", Rules: []string{"crs-941-110"}, Actions: []string{}, Status: 200},
+				{Method: "GET", URL: "http://example.com/", Rules: []string{"blk-001-001"}, Actions: []string{"block"}, Blocked: true, Status: 403},
+				{Method: "POST", URL: "http://example.com/form", Rules: []string{"crs-933-130-block"}, Actions: []string{"block"}, Blocked: true, Status: 403},
+			},
+		},
+		{
+			name: "har",
+			args: []string{"-response-body", "testdata/requests.har"},
+			expected: []result{
+				{Method: "GET", URL: "http://example.com/search?q=%3Cscript%3Ealert(1)%3C/script%3E", Rules: []string{"crs-941-110"}, Actions: []string{}, Status: 200},
+				{Method: "POST", URL: "http://example.com/form", Rules: []string{"crs-933-130-block"}, Actions: []string{"block"}, Blocked: true, Status: 403},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			args := append([]string{"-rules", "../../internal/appsec/testdata/blocking.json", "-fail-on-match"}, tc.args...)
+			code := run(args, nil, &stdout, &stderr)
+			require.Equal(t, 2, code, stderr.String())
+
+			var results []result
+			s := bufio.NewScanner(&stdout)
+			for s.Scan() {
+				var res result
+				require.NoError(t, json.Unmarshal(s.Bytes(), &res))
+				assert.GreaterOrEqual(t, res.Duration, res.WAFDuration)
+				res.Duration, res.WAFDuration = 0, 0
+				results = append(results, res)
+			}
+			require.Len(t, results, len(tc.expected))
+			for i := range tc.expected {
+				tc.expected[i].Index = i
+			}
+			assert.Equal(t, tc.expected, results)
+		})
+	}
+
+	t.Run("no-match", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		stdin := bytes.NewBufferString(`{"url":"http://example.com/"}`)
+		code := run([]string{"-rules", "../../internal/appsec/testdata/blocking.json", "-fail-on-match"}, stdin, &stdout, &stderr)
+		require.Equal(t, 0, code, stderr.String())
+		var res result
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &res))
+		assert.Empty(t, res.Rules)
+		assert.Equal(t, http.StatusOK, res.Status)
+	})
+}
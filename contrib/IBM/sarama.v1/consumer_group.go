@@ -0,0 +1,152 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package sarama
+
+import (
+	"context"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+
+	"github.com/IBM/sarama"
+)
+
+type consumerGroup struct {
+	sarama.ConsumerGroup
+	opts []Option
+}
+
+// Consume invokes ConsumerGroup.Consume with the given handler wrapped by
+// WrapConsumerGroupHandler.
+func (cg *consumerGroup) Consume(ctx context.Context, topics []string, handler sarama.ConsumerGroupHandler) error {
+	return cg.ConsumerGroup.Consume(ctx, topics, WrapConsumerGroupHandler(handler, cg.opts...))
+}
+
+// WrapConsumerGroup wraps a sarama.ConsumerGroup so that the handlers given to
+// ConsumerGroup.Consume are wrapped by WrapConsumerGroupHandler. The consumer
+// group name should be provided with WithGroupID for the Data Streams
+// Monitoring pathways and consumer lag to be computed.
+func WrapConsumerGroup(cg sarama.ConsumerGroup, opts ...Option) sarama.ConsumerGroup {
+	return &consumerGroup{
+		ConsumerGroup: cg,
+		opts:          opts,
+	}
+}
+
+type consumerGroupHandler struct {
+	sarama.ConsumerGroupHandler
+	cfg *config
+}
+
+// WrapConsumerGroupHandler wraps a sarama.ConsumerGroupHandler causing each
+// message received from the claims to be traced. When Data Streams Monitoring
+// is enabled, the offsets marked with ConsumerGroupSession.MarkOffset and
+// ConsumerGroupSession.MarkMessage are tracked as the committed offsets of the
+// consumer group given with WithGroupID.
+func WrapConsumerGroupHandler(handler sarama.ConsumerGroupHandler, opts ...Option) sarama.ConsumerGroupHandler {
+	cfg := new(config)
+	defaults(cfg)
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	log.Debug("contrib/IBM/sarama: Wrapping Consumer Group Handler: %#v", cfg)
+	return &consumerGroupHandler{
+		ConsumerGroupHandler: handler,
+		cfg:                  cfg,
+	}
+}
+
+// Setup invokes ConsumerGroupHandler.Setup with the wrapped session.
+func (h *consumerGroupHandler) Setup(session sarama.ConsumerGroupSession) error {
+	return h.ConsumerGroupHandler.Setup(h.wrapSession(session))
+}
+
+// Cleanup invokes ConsumerGroupHandler.Cleanup with the wrapped session.
+func (h *consumerGroupHandler) Cleanup(session sarama.ConsumerGroupSession) error {
+	return h.ConsumerGroupHandler.Cleanup(h.wrapSession(session))
+}
+
+// ConsumeClaim invokes ConsumerGroupHandler.ConsumeClaim with the wrapped
+// session and a claim whose messages are traced.
+func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	wrapped := &consumerGroupClaim{
+		ConsumerGroupClaim: claim,
+		messages:           make(chan *sarama.ConsumerMessage),
+	}
+	done := session.Context().Done()
+	go func() {
+		var prev ddtrace.Span
+		// finish any remaining span
+		defer func() {
+			if prev != nil {
+				prev.Finish()
+			}
+			close(wrapped.messages)
+		}()
+		for msg := range claim.Messages() {
+			next := startConsumerSpan(h.cfg, msg)
+			setConsumeCheckpoint(h.cfg.dataStreamsEnabled, h.cfg.groupID, msg)
+
+			select {
+			case wrapped.messages <- msg:
+			case <-done:
+				// the session ended and the handler no longer reads the
+				// messages, which are drained by sarama
+				next.Finish()
+				return
+			}
+
+			// if the next message was received, finish the previous span
+			if prev != nil {
+				prev.Finish()
+			}
+			prev = next
+		}
+	}()
+	return h.ConsumerGroupHandler.ConsumeClaim(h.wrapSession(session), wrapped)
+}
+
+func (h *consumerGroupHandler) wrapSession(session sarama.ConsumerGroupSession) sarama.ConsumerGroupSession {
+	if !h.cfg.dataStreamsEnabled || h.cfg.groupID == "" {
+		return session
+	}
+	return &consumerGroupSession{
+		ConsumerGroupSession: session,
+		groupID:              h.cfg.groupID,
+	}
+}
+
+type consumerGroupClaim struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+// Messages returns the read channel for the traced messages of the claim.
+func (c *consumerGroupClaim) Messages() <-chan *sarama.ConsumerMessage {
+	return c.messages
+}
+
+// consumerGroupSession tracks the offsets marked by the handler as the
+// committed offsets of the consumer group.
+type consumerGroupSession struct {
+	sarama.ConsumerGroupSession
+	groupID string
+}
+
+// MarkOffset invokes ConsumerGroupSession.MarkOffset and tracks the marked
+// offset, which is the offset of the next message to consume.
+func (s *consumerGroupSession) MarkOffset(topic string, partition int32, offset int64, metadata string) {
+	s.ConsumerGroupSession.MarkOffset(topic, partition, offset, metadata)
+	tracer.TrackKafkaCommitOffset(s.groupID, topic, partition, offset)
+}
+
+// MarkMessage invokes ConsumerGroupSession.MarkMessage and tracks the marked
+// offset, which is the offset following the one of the message.
+func (s *consumerGroupSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.ConsumerGroupSession.MarkMessage(msg, metadata)
+	tracer.TrackKafkaCommitOffset(s.groupID, msg.Topic, msg.Partition, msg.Offset+1)
+}
@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package sarama
+
+import (
+	"context"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSession struct {
+	sarama.ConsumerGroupSession
+	ctx    context.Context
+	marked []int64
+}
+
+func (s *testSession) Context() context.Context { return s.ctx }
+
+func (s *testSession) MarkOffset(_ string, _ int32, offset int64, _ string) {
+	s.marked = append(s.marked, offset)
+}
+
+func (s *testSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.MarkOffset(msg.Topic, msg.Partition, msg.Offset+1, metadata)
+}
+
+type testClaim struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *testClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+type testHandler struct {
+	consumed []*sarama.ConsumerMessage
+	session  sarama.ConsumerGroupSession
+}
+
+func (h *testHandler) Setup(sarama.ConsumerGroupSession) error   { return nil }
+func (h *testHandler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+func (h *testHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	h.session = session
+	for msg := range claim.Messages() {
+		h.consumed = append(h.consumed, msg)
+		session.MarkMessage(msg, "")
+	}
+	return nil
+}
+
+func TestConsumerGroupHandler(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	claim := &testClaim{messages: make(chan *sarama.ConsumerMessage, 2)}
+	claim.messages <- &sarama.ConsumerMessage{Topic: "test-topic", Partition: 1, Offset: 10, Value: []byte("hello")}
+	claim.messages <- &sarama.ConsumerMessage{Topic: "test-topic", Partition: 1, Offset: 11, Value: []byte("world")}
+	close(claim.messages)
+
+	session := &testSession{ctx: context.Background()}
+	handler := &testHandler{}
+	wrapped := WrapConsumerGroupHandler(handler, WithDataStreams(), WithGroupID("test-group"))
+	require.NoError(t, wrapped.Setup(session))
+	require.NoError(t, wrapped.ConsumeClaim(session, claim))
+	require.NoError(t, wrapped.Cleanup(session))
+
+	// The marked offsets are forwarded to the session
+	assert.Equal(t, []int64{11, 12}, session.marked)
+	assert.IsType(t, &consumerGroupSession{}, handler.session)
+
+	require.Len(t, handler.consumed, 2)
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	for i, s := range spans {
+		msg := handler.consumed[i]
+		spanctx, err := tracer.Extract(NewConsumerMessageCarrier(msg))
+		require.NoError(t, err)
+		assert.Equal(t, spanctx.SpanID(), s.SpanID(), "span context should be injected into the consumer message headers")
+		assert.Equal(t, "kafka.consume", s.OperationName())
+		assert.Equal(t, "Consume Topic test-topic", s.Tag(ext.ResourceName))
+		assert.Equal(t, int32(1), s.Tag(ext.MessagingKafkaPartition))
+		assert.Equal(t, msg.Offset, s.Tag("offset"))
+		assert.Equal(t, "IBM/sarama", s.Tag(ext.Component))
+		assert.Equal(t, ext.SpanKindConsumer, s.Tag(ext.SpanKind))
+		assert.Equal(t, "kafka", s.Tag(ext.MessagingSystem))
+
+		p, ok := datastreams.PathwayFromContext(datastreams.ExtractFromBase64Carrier(context.Background(), NewConsumerMessageCarrier(msg)))
+		assert.True(t, ok)
+		expectedCtx, _ := tracer.SetDataStreamsCheckpoint(context.Background(), "direction:in", "topic:test-topic", "type:kafka", "group:test-group")
+		expected, _ := datastreams.PathwayFromContext(expectedCtx)
+		assert.NotEqual(t, expected.GetHash(), 0)
+		assert.Equal(t, expected.GetHash(), p.GetHash())
+	}
+}
+
+func TestConsumerGroupHandlerSessionEnd(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	claim := &testClaim{messages: make(chan *sarama.ConsumerMessage, 1)}
+	claim.messages <- &sarama.ConsumerMessage{Topic: "test-topic", Offset: 10}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	session := &testSession{ctx: ctx}
+
+	// The handler returns without reading the messages as the session ended
+	var wrappedClaim sarama.ConsumerGroupClaim
+	h := WrapConsumerGroupHandler(handlerFunc(func(_ sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+		wrappedClaim = claim
+		return nil
+	}))
+	require.NoError(t, h.ConsumeClaim(session, claim))
+	// sarama closes the claim once the handler returned
+	close(claim.messages)
+
+	// The messages channel is closed once the session ended
+	for range wrappedClaim.Messages() {
+	}
+	assert.Len(t, mt.FinishedSpans(), 1)
+}
+
+type handlerFunc func(sarama.ConsumerGroupSession, sarama.ConsumerGroupClaim) error
+
+func (handlerFunc) Setup(sarama.ConsumerGroupSession) error   { return nil }
+func (handlerFunc) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+func (f handlerFunc) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	return f(session, claim)
+}
@@ -15,6 +15,7 @@ type config struct {
 	serviceName   string
 	analyticsRate float64
 	errCheck      func(err error) bool
+	dataStreams   bool
 }
 
 // Option represents an option that can be passed to Dial.
@@ -26,6 +27,7 @@ func defaults(cfg *config) {
 	} else {
 		cfg.analyticsRate = math.NaN()
 	}
+	cfg.dataStreams = internal.BoolEnv("DD_DATA_STREAMS_ENABLED", false)
 }
 
 // WithServiceName sets the given service name for the dialled connection.
@@ -68,3 +70,12 @@ func WithErrorCheck(fn func(err error) bool) Option {
 		cfg.errCheck = fn
 	}
 }
+
+// WithDataStreams enables the Data Streams Monitoring product features: the
+// produce and consume checkpoints of the SQS, SNS, EventBridge and Kinesis
+// messages, and the propagation of the pathway along with the trace context.
+func WithDataStreams() Option {
+	return func(cfg *config) {
+		cfg.dataStreams = true
+	}
+}
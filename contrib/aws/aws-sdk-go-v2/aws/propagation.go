@@ -0,0 +1,421 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams"
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams/options"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	eventbridgetypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	kinesistypes "github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+const (
+	// datadogKey is the name of the message attribute, or of the JSON
+	// field, holding the propagated trace context.
+	datadogKey = "_datadog"
+
+	// maxMessageAttributes is the maximum number of message attributes of
+	// SQS and SNS messages.
+	maxMessageAttributes = 10
+	// maxEventBridgeEntrySize is the maximum size of an EventBridge entry.
+	maxEventBridgeEntrySize = 256 * 1024
+	// maxKinesisRecordSize is the maximum size of a Kinesis record.
+	maxKinesisRecordSize = 1024 * 1024
+)
+
+// injectTraceContext injects the context of the given span into the messages,
+// events or records sent with the given request parameters, and sets the Data
+// Streams Monitoring produce checkpoints of each of them when enabled. It
+// returns the parameters to send: messages already having the maximum number
+// of attributes, events and records that would exceed their maximum size, and
+// payloads that are not JSON objects are left untouched. The parameters of the
+// caller are never modified, so that they can be sent again: the returned
+// parameters are shallow copies holding copies of the modified entries,
+// attributes and payloads, and the trace context is appended to the original
+// bytes of the JSON payloads.
+func (mw *traceMiddleware) injectTraceContext(ctx context.Context, span ddtrace.Span, params any) any {
+	switch params := params.(type) {
+	case *sqs.SendMessageInput:
+		in := *params
+		queue := queueNameFromURL(in.QueueUrl)
+		in.MessageAttributes = mw.injectSQSMessageAttributes(ctx, span, queue, in.MessageBody, in.MessageAttributes)
+		return &in
+	case *sqs.SendMessageBatchInput:
+		in := *params
+		queue := queueNameFromURL(in.QueueUrl)
+		in.Entries = append([]sqstypes.SendMessageBatchRequestEntry(nil), in.Entries...)
+		for i := range in.Entries {
+			e := &in.Entries[i]
+			e.MessageAttributes = mw.injectSQSMessageAttributes(ctx, span, queue, e.MessageBody, e.MessageAttributes)
+		}
+		return &in
+	case *sns.PublishInput:
+		in := *params
+		topic := topicNameFromARN(in.TopicArn, in.TargetArn)
+		in.MessageAttributes = mw.injectSNSMessageAttributes(ctx, span, topic, in.Message, in.MessageAttributes)
+		return &in
+	case *sns.PublishBatchInput:
+		in := *params
+		topic := topicNameFromARN(in.TopicArn, nil)
+		in.PublishBatchRequestEntries = append([]snstypes.PublishBatchRequestEntry(nil), in.PublishBatchRequestEntries...)
+		for i := range in.PublishBatchRequestEntries {
+			e := &in.PublishBatchRequestEntries[i]
+			e.MessageAttributes = mw.injectSNSMessageAttributes(ctx, span, topic, e.Message, e.MessageAttributes)
+		}
+		return &in
+	case *eventbridge.PutEventsInput:
+		in := *params
+		in.Entries = append([]eventbridgetypes.PutEventsRequestEntry(nil), in.Entries...)
+		for i := range in.Entries {
+			mw.injectEventBridgeEntry(ctx, span, &in.Entries[i])
+		}
+		return &in
+	case *kinesis.PutRecordInput:
+		in := *params
+		stream := kinesisStreamName(in.StreamName, in.StreamARN)
+		in.Data = mw.injectKinesisData(ctx, span, stream, in.Data)
+		return &in
+	case *kinesis.PutRecordsInput:
+		in := *params
+		stream := kinesisStreamName(in.StreamName, in.StreamARN)
+		in.Records = append([]kinesistypes.PutRecordsRequestEntry(nil), in.Records...)
+		for i := range in.Records {
+			r := &in.Records[i]
+			r.Data = mw.injectKinesisData(ctx, span, stream, r.Data)
+		}
+		return &in
+	case *sqs.ReceiveMessageInput:
+		// Request the trace context attribute along with the ones requested
+		// by the caller.
+		for _, name := range params.MessageAttributeNames {
+			if name == "All" || name == ".*" || name == datadogKey {
+				return params
+			}
+		}
+		in := *params
+		names := make([]string, len(in.MessageAttributeNames), len(in.MessageAttributeNames)+1)
+		copy(names, in.MessageAttributeNames)
+		in.MessageAttributeNames = append(names, datadogKey)
+		return &in
+	}
+	return params
+}
+
+// setConsumeCheckpoints sets the Data Streams Monitoring consume checkpoints
+// of the messages and records received by the request.
+func (mw *traceMiddleware) setConsumeCheckpoints(ctx context.Context, in middleware.InitializeInput, out middleware.InitializeOutput) {
+	if !mw.cfg.dataStreams {
+		return
+	}
+	switch res := out.Result.(type) {
+	case *sqs.ReceiveMessageOutput:
+		params, ok := in.Parameters.(*sqs.ReceiveMessageInput)
+		if !ok {
+			return
+		}
+		edges := []string{"direction:in", "topic:" + queueNameFromURL(params.QueueUrl), "type:sqs"}
+		for _, msg := range res.Messages {
+			carrier, _ := sqsMessageCarrier(msg)
+			setConsumeCheckpoint(ctx, carrier, sqsMessageSize(msg.Body, msg.MessageAttributes), edges)
+		}
+	case *kinesis.GetRecordsOutput:
+		params, ok := in.Parameters.(*kinesis.GetRecordsInput)
+		if !ok {
+			return
+		}
+		edges := []string{"direction:in", "topic:" + kinesisStreamName(nil, params.StreamARN), "type:kinesis"}
+		for _, r := range res.Records {
+			carrier, _ := kinesisDataCarrier(r.Data)
+			setConsumeCheckpoint(ctx, carrier, int64(len(r.Data)+len(aws.ToString(r.PartitionKey))), edges)
+		}
+	}
+}
+
+func setConsumeCheckpoint(ctx context.Context, carrier tracer.TextMapCarrier, size int64, edges []string) {
+	if carrier != nil {
+		ctx = datastreams.ExtractFromBase64Carrier(ctx, carrier)
+	}
+	tracer.SetDataStreamsCheckpointWithParams(ctx, options.CheckpointParams{PayloadSize: size}, edges...)
+}
+
+// carrier returns the carrier of the context of the given span, along with
+// the Data Streams Monitoring pathway resulting from the produce checkpoint
+// when enabled.
+func (mw *traceMiddleware) carrier(ctx context.Context, span ddtrace.Span, size int64, edges ...string) tracer.TextMapCarrier {
+	carrier := tracer.TextMapCarrier{}
+	if err := tracer.Inject(span.Context(), carrier); err != nil {
+		log.Debug("contrib/aws/aws-sdk-go-v2/aws: failed injecting the trace context: %v", err)
+	}
+	if mw.cfg.dataStreams {
+		if ctx, ok := tracer.SetDataStreamsCheckpointWithParams(ctx, options.CheckpointParams{PayloadSize: size}, edges...); ok {
+			datastreams.InjectToBase64Carrier(ctx, carrier)
+		}
+	}
+	return carrier
+}
+
+func (mw *traceMiddleware) injectSQSMessageAttributes(ctx context.Context, span ddtrace.Span, queue string, body *string, attrs map[string]sqstypes.MessageAttributeValue) map[string]sqstypes.MessageAttributeValue {
+	if _, ok := attrs[datadogKey]; !ok && len(attrs) >= maxMessageAttributes {
+		log.Debug("contrib/aws/aws-sdk-go-v2/aws: not injecting the trace context: the SQS message already has %d attributes", len(attrs))
+		return attrs
+	}
+	carrier := mw.carrier(ctx, span, sqsMessageSize(body, attrs), "direction:out", "topic:"+queue, "type:sqs")
+	value, err := json.Marshal(carrier)
+	if err != nil {
+		log.Debug("contrib/aws/aws-sdk-go-v2/aws: failed encoding the trace context: %v", err)
+		return attrs
+	}
+	injected := make(map[string]sqstypes.MessageAttributeValue, len(attrs)+1)
+	for k, v := range attrs {
+		injected[k] = v
+	}
+	injected[datadogKey] = sqstypes.MessageAttributeValue{
+		DataType:    aws.String("String"),
+		StringValue: aws.String(string(value)),
+	}
+	return injected
+}
+
+// injectSNSMessageAttributes injects the trace context as a binary attribute
+// so that it is kept as-is when the message is delivered to an SQS queue with
+// raw message delivery.
+func (mw *traceMiddleware) injectSNSMessageAttributes(ctx context.Context, span ddtrace.Span, topic string, message *string, attrs map[string]snstypes.MessageAttributeValue) map[string]snstypes.MessageAttributeValue {
+	if _, ok := attrs[datadogKey]; !ok && len(attrs) >= maxMessageAttributes {
+		log.Debug("contrib/aws/aws-sdk-go-v2/aws: not injecting the trace context: the SNS message already has %d attributes", len(attrs))
+		return attrs
+	}
+	size := int64(len(aws.ToString(message)))
+	for k, v := range attrs {
+		size += int64(len(k) + len(aws.ToString(v.StringValue)) + len(v.BinaryValue))
+	}
+	carrier := mw.carrier(ctx, span, size, "direction:out", "topic:"+topic, "type:sns")
+	value, err := json.Marshal(carrier)
+	if err != nil {
+		log.Debug("contrib/aws/aws-sdk-go-v2/aws: failed encoding the trace context: %v", err)
+		return attrs
+	}
+	injected := make(map[string]snstypes.MessageAttributeValue, len(attrs)+1)
+	for k, v := range attrs {
+		injected[k] = v
+	}
+	injected[datadogKey] = snstypes.MessageAttributeValue{
+		DataType:    aws.String("Binary"),
+		BinaryValue: value,
+	}
+	return injected
+}
+
+func (mw *traceMiddleware) injectEventBridgeEntry(ctx context.Context, span ddtrace.Span, e *eventbridgetypes.PutEventsRequestEntry) {
+	detail := []byte("{}")
+	if e.Detail != nil {
+		detail = []byte(*e.Detail)
+	}
+	if !canAppendJSONField(detail, datadogKey) {
+		log.Debug("contrib/aws/aws-sdk-go-v2/aws: not injecting the trace context: the EventBridge event detail is not a JSON object or already holds a trace context")
+		return
+	}
+	bus := aws.ToString(e.EventBusName)
+	if bus == "" {
+		bus = "default"
+	}
+	size := int64(len(aws.ToString(e.Detail)) + len(aws.ToString(e.DetailType)) + len(aws.ToString(e.Source)))
+	carrier, err := json.Marshal(mw.carrier(ctx, span, size, "direction:out", "topic:"+bus, "type:eventbridge"))
+	if err != nil {
+		log.Debug("contrib/aws/aws-sdk-go-v2/aws: failed encoding the trace context: %v", err)
+		return
+	}
+	injected := appendJSONField(detail, datadogKey, carrier)
+	if int(size)-len(aws.ToString(e.Detail))+len(injected) > maxEventBridgeEntrySize {
+		log.Debug("contrib/aws/aws-sdk-go-v2/aws: not injecting the trace context: the EventBridge event would exceed its maximum size")
+		return
+	}
+	e.Detail = aws.String(string(injected))
+}
+
+func (mw *traceMiddleware) injectKinesisData(ctx context.Context, span ddtrace.Span, stream string, data []byte) []byte {
+	if !canAppendJSONField(data, datadogKey) {
+		log.Debug("contrib/aws/aws-sdk-go-v2/aws: not injecting the trace context: the Kinesis record data is not a JSON object or already holds a trace context")
+		return data
+	}
+	carrier, err := json.Marshal(mw.carrier(ctx, span, int64(len(data)), "direction:out", "topic:"+stream, "type:kinesis"))
+	if err != nil {
+		log.Debug("contrib/aws/aws-sdk-go-v2/aws: failed encoding the trace context: %v", err)
+		return data
+	}
+	injected := appendJSONField(data, datadogKey, carrier)
+	if len(injected) > maxKinesisRecordSize {
+		log.Debug("contrib/aws/aws-sdk-go-v2/aws: not injecting the trace context: the Kinesis record would exceed its maximum size")
+		return data
+	}
+	return injected
+}
+
+// canAppendJSONField reports whether data is a JSON object not having the
+// given field yet. The object is only decoded to be checked: the payloads are
+// never re-encoded, so that their bytes are kept as-is.
+func canAppendJSONField(data []byte, key string) bool {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil || obj == nil {
+		return false
+	}
+	_, ok := obj[key]
+	return !ok
+}
+
+// appendJSONField returns a copy of the JSON object data, checked with
+// canAppendJSONField, with the given field and JSON value appended to it.
+func appendJSONField(data []byte, key string, value []byte) []byte {
+	end := bytes.LastIndexByte(data, '}')
+	injected := make([]byte, 0, len(data)+len(key)+len(value)+4)
+	injected = append(injected, data[:end]...)
+	if len(bytes.TrimSpace(data[bytes.IndexByte(data, '{')+1:end])) > 0 {
+		injected = append(injected, ',')
+	}
+	injected = append(injected, '"')
+	injected = append(injected, key...)
+	injected = append(injected, '"', ':')
+	injected = append(injected, value...)
+	return append(injected, data[end:]...)
+}
+
+// ExtractSQSMessage returns the span context propagated by the producer of the
+// given SQS message, received with an SQS client instrumented by
+// AppendMiddleware. The trace context is propagated by messages sent to the
+// queue, including SNS notifications and EventBridge events delivered to the
+// queue. It returns tracer.ErrSpanContextNotFound when the message has no
+// propagated trace context.
+func ExtractSQSMessage(msg sqstypes.Message) (ddtrace.SpanContext, error) {
+	carrier, ok := sqsMessageCarrier(msg)
+	if !ok {
+		return nil, tracer.ErrSpanContextNotFound
+	}
+	return tracer.Extract(carrier)
+}
+
+// ExtractKinesisRecord returns the span context propagated by the producer of
+// the given Kinesis record, put with a Kinesis client instrumented by
+// AppendMiddleware. It returns tracer.ErrSpanContextNotFound when the record
+// has no propagated trace context.
+func ExtractKinesisRecord(r kinesistypes.Record) (ddtrace.SpanContext, error) {
+	carrier, ok := kinesisDataCarrier(r.Data)
+	if !ok {
+		return nil, tracer.ErrSpanContextNotFound
+	}
+	return tracer.Extract(carrier)
+}
+
+// sqsMessageCarrier returns the trace context carrier of the given SQS
+// message, looking for it in its attributes first and then in its body in
+// case of an SNS notification or an EventBridge event.
+func sqsMessageCarrier(msg sqstypes.Message) (tracer.TextMapCarrier, bool) {
+	if attr, ok := msg.MessageAttributes[datadogKey]; ok {
+		value := attr.BinaryValue
+		if attr.StringValue != nil {
+			value = []byte(*attr.StringValue)
+		}
+		return decodeCarrier(value)
+	}
+	if msg.Body == nil {
+		return nil, false
+	}
+	var body struct {
+		// SNS notification
+		Type              string `json:"Type"`
+		MessageAttributes map[string]struct {
+			Type  string `json:"Type"`
+			Value string `json:"Value"`
+		} `json:"MessageAttributes"`
+		// EventBridge event
+		Detail map[string]json.RawMessage `json:"detail"`
+	}
+	if err := json.Unmarshal([]byte(*msg.Body), &body); err != nil {
+		return nil, false
+	}
+	if attr, ok := body.MessageAttributes[datadogKey]; ok && body.Type == "Notification" {
+		value := []byte(attr.Value)
+		if attr.Type == "Binary" {
+			decoded, err := base64.StdEncoding.DecodeString(attr.Value)
+			if err != nil {
+				return nil, false
+			}
+			value = decoded
+		}
+		return decodeCarrier(value)
+	}
+	if value, ok := body.Detail[datadogKey]; ok {
+		return decodeCarrier(value)
+	}
+	return nil, false
+}
+
+func kinesisDataCarrier(data []byte) (tracer.TextMapCarrier, bool) {
+	var record map[string]json.RawMessage
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, false
+	}
+	value, ok := record[datadogKey]
+	if !ok {
+		return nil, false
+	}
+	return decodeCarrier(value)
+}
+
+func decodeCarrier(value []byte) (tracer.TextMapCarrier, bool) {
+	var carrier tracer.TextMapCarrier
+	if err := json.Unmarshal(value, &carrier); err != nil {
+		log.Debug("contrib/aws/aws-sdk-go-v2/aws: failed decoding the propagated trace context: %v", err)
+		return nil, false
+	}
+	return carrier, true
+}
+
+func sqsMessageSize(body *string, attrs map[string]sqstypes.MessageAttributeValue) int64 {
+	size := int64(len(aws.ToString(body)))
+	for k, v := range attrs {
+		size += int64(len(k) + len(aws.ToString(v.StringValue)) + len(v.BinaryValue))
+	}
+	return size
+}
+
+func queueNameFromURL(queueURL *string) string {
+	parts := strings.Split(aws.ToString(queueURL), "/")
+	return parts[len(parts)-1]
+}
+
+func topicNameFromARN(topicARN, targetARN *string) string {
+	arn := aws.ToString(topicARN)
+	if arn == "" {
+		arn = aws.ToString(targetARN)
+	}
+	parts := strings.Split(arn, ":")
+	return parts[len(parts)-1]
+}
+
+func kinesisStreamName(name, arn *string) string {
+	if name != nil {
+		return *name
+	}
+	parts := strings.Split(aws.ToString(arn), "/")
+	return parts[len(parts)-1]
+}
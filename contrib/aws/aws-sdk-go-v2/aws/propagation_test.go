@@ -0,0 +1,387 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	eventbridgetypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	kinesistypes "github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockConfig(t *testing.T, opts ...Option) aws.Config {
+	server := mockAWS(200)
+	t.Cleanup(server.Close)
+	awsCfg := aws.Config{
+		Region:      "eu-west-1",
+		Credentials: aws.AnonymousCredentials{},
+		EndpointResolver: aws.EndpointResolverFunc(func(service, region string) (aws.Endpoint, error) {
+			return aws.Endpoint{
+				PartitionID:   "aws",
+				URL:           server.URL,
+				SigningRegion: "eu-west-1",
+			}, nil
+		}),
+	}
+	AppendMiddleware(&awsCfg, opts...)
+	return awsCfg
+}
+
+// recordParams records the parameters of the requests sent with awsCfg, once
+// the trace context is injected into them.
+func recordParams(awsCfg *aws.Config) *[]any {
+	var params []any
+	awsCfg.APIOptions = append(awsCfg.APIOptions, func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("RecordParams", func(
+			ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+		) (middleware.InitializeOutput, middleware.Metadata, error) {
+			params = append(params, in.Parameters)
+			return next.HandleInitialize(ctx, in)
+		}), middleware.After)
+	})
+	return &params
+}
+
+// assertCarrier asserts the given JSON-encoded carrier holds the context of
+// the given span.
+func assertCarrier(t *testing.T, span mocktracer.Span, value []byte) tracer.TextMapCarrier {
+	t.Helper()
+	var carrier tracer.TextMapCarrier
+	require.NoError(t, json.Unmarshal(value, &carrier))
+	spanctx, err := tracer.Extract(carrier)
+	require.NoError(t, err)
+	assert.Equal(t, span.TraceID(), spanctx.TraceID())
+	assert.Equal(t, span.SpanID(), spanctx.SpanID())
+	return carrier
+}
+
+func TestInjectTraceContext(t *testing.T) {
+	const queueURL = "https://sqs.us-west-2.amazonaws.com/123456789012/MyQueueName"
+	const topicARN = "arn:aws:sns:us-west-2:123456789012:MyTopic"
+
+	t.Run("sqs", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		awsCfg := newMockConfig(t)
+		sent := recordParams(&awsCfg)
+		client := sqs.NewFromConfig(awsCfg)
+		attrs := map[string]sqstypes.MessageAttributeValue{
+			"attr": {DataType: aws.String("String"), StringValue: aws.String("value")},
+		}
+		in := &sqs.SendMessageInput{QueueUrl: aws.String(queueURL), MessageBody: aws.String("body"), MessageAttributes: attrs}
+		client.SendMessage(context.Background(), in)
+		batch := &sqs.SendMessageBatchInput{
+			QueueUrl: aws.String(queueURL),
+			Entries: []sqstypes.SendMessageBatchRequestEntry{
+				{Id: aws.String("1"), MessageBody: aws.String("body")},
+				{Id: aws.String("2"), MessageBody: aws.String("body")},
+			},
+		}
+		client.SendMessageBatch(context.Background(), batch)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 2)
+		require.Len(t, *sent, 2)
+		sentIn := (*sent)[0].(*sqs.SendMessageInput)
+		attr := sentIn.MessageAttributes[datadogKey]
+		assert.Equal(t, "String", aws.ToString(attr.DataType))
+		assertCarrier(t, spans[0], []byte(aws.ToString(attr.StringValue)))
+		assert.Contains(t, sentIn.MessageAttributes, "attr")
+		assert.NotContains(t, attrs, datadogKey)
+		assert.NotContains(t, in.MessageAttributes, datadogKey)
+		for _, e := range (*sent)[1].(*sqs.SendMessageBatchInput).Entries {
+			assertCarrier(t, spans[1], []byte(aws.ToString(e.MessageAttributes[datadogKey].StringValue)))
+		}
+		for _, e := range batch.Entries {
+			assert.Nil(t, e.MessageAttributes)
+		}
+	})
+
+	t.Run("sqs-max-attributes", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		attrs := make(map[string]sqstypes.MessageAttributeValue, maxMessageAttributes)
+		for i := 0; i < maxMessageAttributes; i++ {
+			attrs[fmt.Sprintf("attr%d", i)] = sqstypes.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String("value")}
+		}
+		awsCfg := newMockConfig(t)
+		sent := recordParams(&awsCfg)
+		client := sqs.NewFromConfig(awsCfg)
+		in := &sqs.SendMessageInput{QueueUrl: aws.String(queueURL), MessageBody: aws.String("body"), MessageAttributes: attrs}
+		client.SendMessage(context.Background(), in)
+
+		assert.Len(t, mt.FinishedSpans(), 1)
+		require.Len(t, *sent, 1)
+		sentIn := (*sent)[0].(*sqs.SendMessageInput)
+		assert.Len(t, sentIn.MessageAttributes, maxMessageAttributes)
+		assert.NotContains(t, sentIn.MessageAttributes, datadogKey)
+	})
+
+	t.Run("sqs-receive", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		awsCfg := newMockConfig(t)
+		sent := recordParams(&awsCfg)
+		client := sqs.NewFromConfig(awsCfg)
+		// The backing array of the caller must not be written to.
+		names := make([]string, 1, 2)
+		names[0] = "attr"
+		in := &sqs.ReceiveMessageInput{QueueUrl: aws.String(queueURL), MessageAttributeNames: names}
+		client.ReceiveMessage(context.Background(), in)
+		require.Len(t, *sent, 1)
+		assert.Equal(t, []string{"attr", datadogKey}, (*sent)[0].(*sqs.ReceiveMessageInput).MessageAttributeNames)
+		assert.Equal(t, []string{"attr"}, in.MessageAttributeNames)
+		assert.Equal(t, []string{"attr", ""}, names[:2])
+
+		in = &sqs.ReceiveMessageInput{QueueUrl: aws.String(queueURL), MessageAttributeNames: []string{"All"}}
+		client.ReceiveMessage(context.Background(), in)
+		require.Len(t, *sent, 2)
+		assert.Equal(t, []string{"All"}, (*sent)[1].(*sqs.ReceiveMessageInput).MessageAttributeNames)
+	})
+
+	t.Run("sns", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		awsCfg := newMockConfig(t)
+		sent := recordParams(&awsCfg)
+		client := sns.NewFromConfig(awsCfg)
+		in := &sns.PublishInput{TopicArn: aws.String(topicARN), Message: aws.String("message")}
+		client.Publish(context.Background(), in)
+		batch := &sns.PublishBatchInput{
+			TopicArn: aws.String(topicARN),
+			PublishBatchRequestEntries: []snstypes.PublishBatchRequestEntry{
+				{Id: aws.String("1"), Message: aws.String("message")},
+			},
+		}
+		client.PublishBatch(context.Background(), batch)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 2)
+		require.Len(t, *sent, 2)
+		attr := (*sent)[0].(*sns.PublishInput).MessageAttributes[datadogKey]
+		assert.Equal(t, "Binary", aws.ToString(attr.DataType))
+		assertCarrier(t, spans[0], attr.BinaryValue)
+		assertCarrier(t, spans[1], (*sent)[1].(*sns.PublishBatchInput).PublishBatchRequestEntries[0].MessageAttributes[datadogKey].BinaryValue)
+		assert.Nil(t, in.MessageAttributes)
+		assert.Nil(t, batch.PublishBatchRequestEntries[0].MessageAttributes)
+	})
+
+	t.Run("eventbridge", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		awsCfg := newMockConfig(t)
+		sent := recordParams(&awsCfg)
+		client := eventbridge.NewFromConfig(awsCfg)
+		in := &eventbridge.PutEventsInput{
+			Entries: []eventbridgetypes.PutEventsRequestEntry{
+				{Detail: aws.String(`{"key": "value", "amount": 1.50}`), DetailType: aws.String("type"), Source: aws.String("source")},
+				{Detail: aws.String(`not json`), DetailType: aws.String("type"), Source: aws.String("source")},
+				{DetailType: aws.String("type"), Source: aws.String("source")},
+			},
+		}
+		client.PutEvents(context.Background(), in)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		require.Len(t, *sent, 1)
+		entries := (*sent)[0].(*eventbridge.PutEventsInput).Entries
+		// The original bytes are kept as-is.
+		assert.True(t, strings.HasPrefix(aws.ToString(entries[0].Detail), `{"key": "value", "amount": 1.50,"_datadog":`))
+		var detail map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal([]byte(aws.ToString(entries[0].Detail)), &detail))
+		assert.Equal(t, json.RawMessage(`"value"`), detail["key"])
+		assertCarrier(t, spans[0], detail[datadogKey])
+		assert.Equal(t, "not json", aws.ToString(entries[1].Detail))
+		detail = nil
+		require.NoError(t, json.Unmarshal([]byte(aws.ToString(entries[2].Detail)), &detail))
+		assert.Len(t, detail, 1)
+		assertCarrier(t, spans[0], detail[datadogKey])
+		assert.Equal(t, `{"key": "value", "amount": 1.50}`, aws.ToString(in.Entries[0].Detail))
+		assert.Nil(t, in.Entries[2].Detail)
+	})
+
+	t.Run("kinesis", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		awsCfg := newMockConfig(t)
+		sent := recordParams(&awsCfg)
+		client := kinesis.NewFromConfig(awsCfg)
+		in := &kinesis.PutRecordsInput{
+			StreamName: aws.String("stream"),
+			Records: []kinesistypes.PutRecordsRequestEntry{
+				{Data: []byte(`{ "key": "value" }` + "\n"), PartitionKey: aws.String("1")},
+				{Data: []byte(`binary`), PartitionKey: aws.String("2")},
+			},
+		}
+		client.PutRecords(context.Background(), in)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		require.Len(t, *sent, 1)
+		records := (*sent)[0].(*kinesis.PutRecordsInput).Records
+		// The original bytes are kept as-is.
+		assert.True(t, strings.HasPrefix(string(records[0].Data), `{ "key": "value" ,"_datadog":`))
+		assert.True(t, strings.HasSuffix(string(records[0].Data), "}\n"))
+		spanctx, err := ExtractKinesisRecord(kinesistypes.Record{Data: records[0].Data})
+		require.NoError(t, err)
+		assert.Equal(t, spans[0].SpanID(), spanctx.SpanID())
+		assert.Equal(t, []byte(`binary`), records[1].Data)
+		_, err = ExtractKinesisRecord(kinesistypes.Record{Data: records[1].Data})
+		assert.Equal(t, tracer.ErrSpanContextNotFound, err)
+		assert.Equal(t, []byte(`{ "key": "value" }`+"\n"), in.Records[0].Data)
+	})
+
+	// The inputs of the caller are left untouched, so that sending them again
+	// propagates the context of the new span.
+	t.Run("resend", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		awsCfg := newMockConfig(t)
+		sent := recordParams(&awsCfg)
+		sqsClient := sqs.NewFromConfig(awsCfg)
+		snsClient := sns.NewFromConfig(awsCfg)
+		eventbridgeClient := eventbridge.NewFromConfig(awsCfg)
+		kinesisClient := kinesis.NewFromConfig(awsCfg)
+		sqsIn := &sqs.SendMessageInput{QueueUrl: aws.String(queueURL), MessageBody: aws.String("body")}
+		snsIn := &sns.PublishInput{TopicArn: aws.String(topicARN), Message: aws.String("message")}
+		eventbridgeIn := &eventbridge.PutEventsInput{
+			Entries: []eventbridgetypes.PutEventsRequestEntry{{Detail: aws.String(`{}`), DetailType: aws.String("type"), Source: aws.String("source")}},
+		}
+		kinesisIn := &kinesis.PutRecordInput{StreamName: aws.String("stream"), Data: []byte(`{}`), PartitionKey: aws.String("1")}
+		kinesisBatch := &kinesis.PutRecordsInput{
+			StreamName: aws.String("stream"),
+			Records:    []kinesistypes.PutRecordsRequestEntry{{Data: []byte(`{}`), PartitionKey: aws.String("1")}},
+		}
+		for i := 0; i < 2; i++ {
+			sqsClient.SendMessage(context.Background(), sqsIn)
+			snsClient.Publish(context.Background(), snsIn)
+			eventbridgeClient.PutEvents(context.Background(), eventbridgeIn)
+			kinesisClient.PutRecord(context.Background(), kinesisIn)
+			kinesisClient.PutRecords(context.Background(), kinesisBatch)
+		}
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 10)
+		require.Len(t, *sent, 10)
+		for i, params := range *sent {
+			var value []byte
+			switch params := params.(type) {
+			case *sqs.SendMessageInput:
+				value = []byte(aws.ToString(params.MessageAttributes[datadogKey].StringValue))
+			case *sns.PublishInput:
+				value = params.MessageAttributes[datadogKey].BinaryValue
+			case *eventbridge.PutEventsInput:
+				var detail map[string]json.RawMessage
+				require.NoError(t, json.Unmarshal([]byte(aws.ToString(params.Entries[0].Detail)), &detail))
+				value = detail[datadogKey]
+			case *kinesis.PutRecordInput:
+				var data map[string]json.RawMessage
+				require.NoError(t, json.Unmarshal(params.Data, &data))
+				value = data[datadogKey]
+			case *kinesis.PutRecordsInput:
+				var data map[string]json.RawMessage
+				require.NoError(t, json.Unmarshal(params.Records[0].Data, &data))
+				value = data[datadogKey]
+			}
+			assertCarrier(t, spans[i], value)
+		}
+		assert.Nil(t, sqsIn.MessageAttributes)
+		assert.Nil(t, snsIn.MessageAttributes)
+		assert.Equal(t, `{}`, aws.ToString(eventbridgeIn.Entries[0].Detail))
+		assert.Equal(t, []byte(`{}`), kinesisIn.Data)
+		assert.Equal(t, []byte(`{}`), kinesisBatch.Records[0].Data)
+	})
+
+	t.Run("datastreams", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		awsCfg := newMockConfig(t, WithDataStreams())
+		sent := recordParams(&awsCfg)
+		client := sqs.NewFromConfig(awsCfg)
+		in := &sqs.SendMessageInput{QueueUrl: aws.String(queueURL), MessageBody: aws.String("body")}
+		client.SendMessage(context.Background(), in)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		require.Len(t, *sent, 1)
+		carrier := assertCarrier(t, spans[0], []byte(aws.ToString((*sent)[0].(*sqs.SendMessageInput).MessageAttributes[datadogKey].StringValue)))
+		p, ok := datastreams.PathwayFromContext(datastreams.ExtractFromBase64Carrier(context.Background(), carrier))
+		require.True(t, ok)
+		expectedCtx, _ := tracer.SetDataStreamsCheckpoint(context.Background(), "direction:out", "topic:MyQueueName", "type:sqs")
+		expected, _ := datastreams.PathwayFromContext(expectedCtx)
+		assert.NotEqual(t, uint64(0), expected.GetHash())
+		assert.Equal(t, expected.GetHash(), p.GetHash())
+	})
+}
+
+func TestExtractSQSMessage(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	span := tracer.StartSpan("producer")
+	carrier := tracer.TextMapCarrier{}
+	require.NoError(t, tracer.Inject(span.Context(), carrier))
+	value, err := json.Marshal(carrier)
+	require.NoError(t, err)
+
+	for name, msg := range map[string]sqstypes.Message{
+		"string-attribute": {
+			MessageAttributes: map[string]sqstypes.MessageAttributeValue{
+				datadogKey: {DataType: aws.String("String"), StringValue: aws.String(string(value))},
+			},
+		},
+		"binary-attribute": {
+			MessageAttributes: map[string]sqstypes.MessageAttributeValue{
+				datadogKey: {DataType: aws.String("Binary"), BinaryValue: value},
+			},
+		},
+		"sns-notification": {
+			Body: aws.String(fmt.Sprintf(`{"Type":"Notification","Message":"message","MessageAttributes":{"_datadog":{"Type":"Binary","Value":%q}}}`, base64.StdEncoding.EncodeToString(value))),
+		},
+		"eventbridge-event": {
+			Body: aws.String(fmt.Sprintf(`{"version":"0","detail-type":"type","detail":{"key":"value","_datadog":%s}}`, value)),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			spanctx, err := ExtractSQSMessage(msg)
+			require.NoError(t, err)
+			assert.Equal(t, span.Context().TraceID(), spanctx.TraceID())
+			assert.Equal(t, span.Context().SpanID(), spanctx.SpanID())
+		})
+	}
+
+	t.Run("not-found", func(t *testing.T) {
+		_, err := ExtractSQSMessage(sqstypes.Message{Body: aws.String(strings.Repeat("body", 10))})
+		assert.Equal(t, tracer.ErrSpanContextNotFound, err)
+	})
+}
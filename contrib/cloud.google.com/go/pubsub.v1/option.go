@@ -6,6 +6,7 @@
 package pubsub
 
 import (
+	"gopkg.in/DataDog/dd-trace-go.v1/internal"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/namingschema"
 )
 
@@ -14,6 +15,7 @@ type config struct {
 	publishSpanName string
 	receiveSpanName string
 	measured        bool
+	dataStreams     bool
 }
 
 func defaultConfig() *config {
@@ -22,6 +24,7 @@ func defaultConfig() *config {
 		publishSpanName: namingschema.OpName(namingschema.GCPPubSubOutbound),
 		receiveSpanName: namingschema.OpName(namingschema.GCPPubSubInbound),
 		measured:        false,
+		dataStreams:     internal.BoolEnv("DD_DATA_STREAMS_ENABLED", false),
 	}
 }
 
@@ -44,3 +47,12 @@ func WithMeasured() Option {
 		cfg.measured = true
 	}
 }
+
+// WithDataStreams enables the Data Streams Monitoring product features: the
+// publish and receive checkpoints and the propagation of the pathway through
+// the message attributes.
+func WithDataStreams() Option {
+	return func(cfg *config) {
+		cfg.dataStreams = true
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"context"
 	"sync"
 
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams"
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams/options"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
@@ -63,6 +65,7 @@ func Publish(ctx context.Context, t *pubsub.Topic, msg *pubsub.Message, opts ...
 	if err := tracer.Inject(span.Context(), tracer.TextMapCarrier(msg.Attributes)); err != nil {
 		log.Debug("contrib/cloud.google.com/go/pubsub.v1/: failed injecting tracing attributes: %v", err)
 	}
+	setPublishCheckpoint(ctx, cfg.dataStreams, t, msg)
 	span.SetTag("num_attributes", len(msg.Attributes))
 	return &PublishResult{
 		PublishResult: t.Publish(ctx, msg),
@@ -122,7 +125,44 @@ func WrapReceiveHandler(s *pubsub.Subscription, f func(context.Context, *pubsub.
 		if msg.DeliveryAttempt != nil {
 			span.SetTag("delivery_attempt", *msg.DeliveryAttempt)
 		}
+		ctx = setReceiveCheckpoint(ctx, cfg.dataStreams, s, msg)
 		defer span.Finish()
 		f(ctx, msg)
 	}
 }
+
+// setPublishCheckpoint sets the Data Streams Monitoring publish checkpoint of
+// the message and injects the resulting pathway into its attributes. The
+// pathway of the context, if any, is used as the parent pathway so that a
+// message published while handling a received one continues its pathway.
+func setPublishCheckpoint(ctx context.Context, enabled bool, t *pubsub.Topic, msg *pubsub.Message) {
+	if !enabled {
+		return
+	}
+	edges := []string{"direction:out", "topic:" + t.ID(), "type:google-pubsub"}
+	ctx, ok := tracer.SetDataStreamsCheckpointWithParams(ctx, options.CheckpointParams{PayloadSize: getMessageSize(msg)}, edges...)
+	if !ok {
+		return
+	}
+	datastreams.InjectToBase64Carrier(ctx, tracer.TextMapCarrier(msg.Attributes))
+}
+
+// setReceiveCheckpoint sets the Data Streams Monitoring receive checkpoint of
+// the message out of the pathway propagated in its attributes, and returns the
+// context holding the resulting pathway.
+func setReceiveCheckpoint(ctx context.Context, enabled bool, s *pubsub.Subscription, msg *pubsub.Message) context.Context {
+	if !enabled {
+		return ctx
+	}
+	edges := []string{"direction:in", "subscription:" + s.ID(), "type:google-pubsub"}
+	ctx = datastreams.ExtractFromBase64Carrier(ctx, tracer.TextMapCarrier(msg.Attributes))
+	ctx, _ = tracer.SetDataStreamsCheckpointWithParams(ctx, options.CheckpointParams{PayloadSize: getMessageSize(msg)}, edges...)
+	return ctx
+}
+
+func getMessageSize(msg *pubsub.Message) (size int64) {
+	for k, v := range msg.Attributes {
+		size += int64(len(k) + len(v))
+	}
+	return size + int64(len(msg.Data)+len(msg.OrderingKey))
+}
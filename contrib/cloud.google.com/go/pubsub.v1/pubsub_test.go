@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"gopkg.in/DataDog/dd-trace-go.v1/contrib/internal/namingschematest"
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
@@ -238,6 +239,37 @@ func TestPropagationNoPublisherSpan(t *testing.T) {
 	}, spans[0].Tags())
 }
 
+func TestDataStreams(t *testing.T) {
+	assert := assert.New(t)
+	ctx, cancel, _, topic, sub := setup(t)
+
+	// Publisher
+	msg := &pubsub.Message{Data: []byte("hello")}
+	_, err := Publish(ctx, topic, msg, WithDataStreams()).Get(ctx)
+	require.NoError(t, err)
+	assert.Len(msg.Attributes, 3, "the pathway should be propagated along with the trace context")
+
+	// Subscriber
+	var called bool
+	err = sub.Receive(ctx, WrapReceiveHandler(sub, func(ctx context.Context, msg *pubsub.Message) {
+		assert.False(called, "callback called twice")
+		p, ok := datastreams.PathwayFromContext(ctx)
+		assert.True(ok, "no pathway")
+
+		expectedCtx, _ := tracer.SetDataStreamsCheckpoint(context.Background(), "direction:out", "topic:topic", "type:google-pubsub")
+		expectedCtx, _ = tracer.SetDataStreamsCheckpoint(expectedCtx, "direction:in", "subscription:subscription", "type:google-pubsub")
+		expected, _ := datastreams.PathwayFromContext(expectedCtx)
+		assert.NotEqual(uint64(0), expected.GetHash())
+		assert.Equal(expected.GetHash(), p.GetHash())
+
+		msg.Ack()
+		called = true
+		cancel()
+	}, WithDataStreams()))
+	assert.True(called, "callback not called")
+	assert.NoError(err)
+}
+
 func TestNamingSchema(t *testing.T) {
 	genSpans := namingschematest.GenSpansFn(func(t *testing.T, serviceOverride string) []mocktracer.Span {
 		var opts []Option
@@ -30,5 +30,6 @@ func useAppSec(c *gin.Context, span tracer.Span) {
 	})
 	httpsec.WrapHandler(httpWrapper, span, params, &httpsec.Config{
 		OnBlock: []func(){func() { c.Abort() }},
+		Route:   c.FullPath(),
 	}).ServeHTTP(c.Writer, c.Request)
 }
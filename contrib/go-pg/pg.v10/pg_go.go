@@ -12,6 +12,8 @@ import (
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/sqlsec"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
 
@@ -58,9 +60,25 @@ func (h *queryHook) BeforeQuery(ctx context.Context, qe *pg.QueryEvent) (context
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, h.cfg.analyticsRate))
 	}
 	_, ctx = tracer.StartSpanFromContext(ctx, "go-pg", opts...)
+	if qe.Err == nil {
+		qe.Err = checkQuerySecurity(ctx, qe)
+	}
 	return ctx, qe.Err
 }
 
+// checkQuerySecurity runs ASM RASP SQLi checks on the formatted query, holding the parameters, to verify if it can
+// safely be run. If it's unsafe to run, an *events.BlockingSecurityEvent is returned, which aborts the query.
+func checkQuerySecurity(ctx context.Context, qe *pg.QueryEvent) error {
+	if !appsec.RASPEnabled() {
+		return nil
+	}
+	query, err := qe.FormattedQuery()
+	if err != nil {
+		return nil
+	}
+	return sqlsec.ProtectSQLOperation(ctx, string(query), ext.DBSystemPostgreSQL)
+}
+
 // AfterQuery implements pg.QueryHook
 func (h *queryHook) AfterQuery(ctx context.Context, qe *pg.QueryEvent) error {
 	if span, ok := tracer.SpanFromContext(ctx); ok {
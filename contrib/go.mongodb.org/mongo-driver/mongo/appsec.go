@@ -0,0 +1,246 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package mongo
+
+import (
+	"context"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/nosqlsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/nosqlsec/types"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Database wraps a *mongo.Database so that, when AppSec RASP is enabled, its
+// aggregate operations and the operations of its collections are checked for
+// NoSQL injections before being sent. See Collection.
+type Database struct {
+	*mongo.Database
+}
+
+// WrapDatabase returns db protected by AppSec RASP.
+func WrapDatabase(db *mongo.Database) *Database {
+	return &Database{Database: db}
+}
+
+// Collection returns the protected collection of the given name.
+func (db *Database) Collection(name string, opts ...*options.CollectionOptions) *Collection {
+	return WrapCollection(db.Database.Collection(name, opts...))
+}
+
+// Aggregate runs mongo.Database.Aggregate once its pipeline is checked.
+func (db *Database) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	if err := protectOperation(ctx, "aggregate", pipeline); err != nil {
+		return nil, err
+	}
+	return db.Database.Aggregate(ctx, pipeline, opts...)
+}
+
+// Collection wraps a *mongo.Collection so that, when AppSec RASP is enabled,
+// the filter documents of its find, aggregate, count, distinct, update,
+// replace, delete and find-and-modify operations are checked for NoSQL
+// injections before the command is sent. Unsafe operations are aborted with an
+// *events.BlockingSecurityEvent error, and the request is blocked by the
+// AppSec middleware monitoring it. The insert and bulk write operations are
+// not checked.
+type Collection struct {
+	*mongo.Collection
+}
+
+// WrapCollection returns coll protected by AppSec RASP.
+func WrapCollection(coll *mongo.Collection) *Collection {
+	return &Collection{Collection: coll}
+}
+
+// Find runs mongo.Collection.Find once its filter is checked.
+func (coll *Collection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	if err := protectOperation(ctx, "find", filter); err != nil {
+		return nil, err
+	}
+	return coll.Collection.Find(ctx, filter, opts...)
+}
+
+// FindOne runs mongo.Collection.FindOne once its filter is checked.
+func (coll *Collection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
+	if err := protectOperation(ctx, "find", filter); err != nil {
+		return mongo.NewSingleResultFromDocument(bson.D{}, err, nil)
+	}
+	return coll.Collection.FindOne(ctx, filter, opts...)
+}
+
+// Aggregate runs mongo.Collection.Aggregate once its pipeline is checked.
+func (coll *Collection) Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	if err := protectOperation(ctx, "aggregate", pipeline); err != nil {
+		return nil, err
+	}
+	return coll.Collection.Aggregate(ctx, pipeline, opts...)
+}
+
+// UpdateOne runs mongo.Collection.UpdateOne once its filter is checked.
+func (coll *Collection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	if err := protectOperation(ctx, "update", filter); err != nil {
+		return nil, err
+	}
+	return coll.Collection.UpdateOne(ctx, filter, update, opts...)
+}
+
+// UpdateMany runs mongo.Collection.UpdateMany once its filter is checked.
+func (coll *Collection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	if err := protectOperation(ctx, "update", filter); err != nil {
+		return nil, err
+	}
+	return coll.Collection.UpdateMany(ctx, filter, update, opts...)
+}
+
+// ReplaceOne runs mongo.Collection.ReplaceOne once its filter is checked.
+func (coll *Collection) ReplaceOne(ctx context.Context, filter interface{}, replacement interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	if err := protectOperation(ctx, "update", filter); err != nil {
+		return nil, err
+	}
+	return coll.Collection.ReplaceOne(ctx, filter, replacement, opts...)
+}
+
+// DeleteOne runs mongo.Collection.DeleteOne once its filter is checked.
+func (coll *Collection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	if err := protectOperation(ctx, "delete", filter); err != nil {
+		return nil, err
+	}
+	return coll.Collection.DeleteOne(ctx, filter, opts...)
+}
+
+// DeleteMany runs mongo.Collection.DeleteMany once its filter is checked.
+func (coll *Collection) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	if err := protectOperation(ctx, "delete", filter); err != nil {
+		return nil, err
+	}
+	return coll.Collection.DeleteMany(ctx, filter, opts...)
+}
+
+// CountDocuments runs mongo.Collection.CountDocuments once its filter is
+// checked.
+func (coll *Collection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	// The driver runs it as an aggregate command matching the filter
+	if err := protectOperation(ctx, "aggregate", filter); err != nil {
+		return 0, err
+	}
+	return coll.Collection.CountDocuments(ctx, filter, opts...)
+}
+
+// Distinct runs mongo.Collection.Distinct once its filter is checked.
+func (coll *Collection) Distinct(ctx context.Context, fieldName string, filter interface{}, opts ...*options.DistinctOptions) ([]interface{}, error) {
+	if err := protectOperation(ctx, "distinct", filter); err != nil {
+		return nil, err
+	}
+	return coll.Collection.Distinct(ctx, fieldName, filter, opts...)
+}
+
+// FindOneAndDelete runs mongo.Collection.FindOneAndDelete once its filter is
+// checked.
+func (coll *Collection) FindOneAndDelete(ctx context.Context, filter interface{}, opts ...*options.FindOneAndDeleteOptions) *mongo.SingleResult {
+	if err := protectOperation(ctx, "findAndModify", filter); err != nil {
+		return mongo.NewSingleResultFromDocument(bson.D{}, err, nil)
+	}
+	return coll.Collection.FindOneAndDelete(ctx, filter, opts...)
+}
+
+// FindOneAndReplace runs mongo.Collection.FindOneAndReplace once its filter is
+// checked.
+func (coll *Collection) FindOneAndReplace(ctx context.Context, filter interface{}, replacement interface{}, opts ...*options.FindOneAndReplaceOptions) *mongo.SingleResult {
+	if err := protectOperation(ctx, "findAndModify", filter); err != nil {
+		return mongo.NewSingleResultFromDocument(bson.D{}, err, nil)
+	}
+	return coll.Collection.FindOneAndReplace(ctx, filter, replacement, opts...)
+}
+
+// FindOneAndUpdate runs mongo.Collection.FindOneAndUpdate once its filter is
+// checked.
+func (coll *Collection) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) *mongo.SingleResult {
+	if err := protectOperation(ctx, "findAndModify", filter); err != nil {
+		return mongo.NewSingleResultFromDocument(bson.D{}, err, nil)
+	}
+	return coll.Collection.FindOneAndUpdate(ctx, filter, update, opts...)
+}
+
+// protectOperation runs the ASM RASP NoSQL injection checks on the filter
+// documents of the given command, and returns an *events.BlockingSecurityEvent
+// if the command must not be sent.
+func protectOperation(ctx context.Context, command string, filter interface{}) error {
+	if !appsec.RASPEnabled() {
+		return nil
+	}
+	f := filterValue(filter)
+	if f == nil {
+		return nil
+	}
+	return nosqlsec.ProtectNoSQLOperation(ctx, types.NoSQLOperationArgs{
+		Filter:  f,
+		Command: command,
+		System:  "mongodb",
+	})
+}
+
+// filterValue returns the filter documents or pipeline given to an operation
+// as the maps, slices and scalar values expected by the WAF, or nil if they
+// can't be marshaled, in which case the driver fails the operation anyway.
+func filterValue(filter interface{}) any {
+	if filter == nil {
+		return nil
+	}
+	// Marshal the filter as the value of a document so that both documents
+	// and pipelines are supported, like the driver does in its commands.
+	raw, err := bson.Marshal(bson.D{{Key: "filter", Value: filter}})
+	if err != nil {
+		log.Debug("contrib/go.mongodb.org/mongo-driver/mongo: could not marshal the filter: %v", err)
+		return nil
+	}
+	return rawValue(bson.Raw(raw).Lookup("filter"))
+}
+
+// rawValue converts v into the maps, slices and scalar values expected by the
+// WAF. It returns nil when v is empty.
+func rawValue(v bson.RawValue) any {
+	switch v.Type {
+	case bsontype.EmbeddedDocument:
+		elems, err := v.Document().Elements()
+		if err != nil {
+			return nil
+		}
+		m := make(map[string]any, len(elems))
+		for _, e := range elems {
+			m[e.Key()] = rawValue(e.Value())
+		}
+		return m
+	case bsontype.Array:
+		values, err := v.Array().Values()
+		if err != nil {
+			return nil
+		}
+		s := make([]any, len(values))
+		for i, e := range values {
+			s[i] = rawValue(e)
+		}
+		return s
+	case bsontype.String:
+		return v.StringValue()
+	case bsontype.Boolean:
+		return v.Boolean()
+	case bsontype.Int32:
+		return int64(v.Int32())
+	case bsontype.Int64:
+		return v.Int64()
+	case bsontype.Double:
+		return v.Double()
+	case bsontype.Null, bsontype.Undefined, 0:
+		return nil
+	default:
+		return v.String()
+	}
+}
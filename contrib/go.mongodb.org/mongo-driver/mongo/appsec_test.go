@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package mongo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec/events"
+	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestFilterValue(t *testing.T) {
+	for name, tc := range map[string]struct {
+		filter   any
+		expected any
+	}{
+		"document": {
+			filter:   bson.D{{Key: "name", Value: bson.D{{Key: "$ne", Value: nil}}}, {Key: "age", Value: 18}},
+			expected: map[string]any{"name": map[string]any{"$ne": nil}, "age": int64(18)},
+		},
+		"map": {
+			filter:   bson.M{"$where": "sleep(1)"},
+			expected: map[string]any{"$where": "sleep(1)"},
+		},
+		"pipeline": {
+			filter:   mongo.Pipeline{{{Key: "$match", Value: bson.D{{Key: "admin", Value: true}}}}},
+			expected: []any{map[string]any{"$match": map[string]any{"admin": true}}},
+		},
+		"nil": {},
+		"invalid": {
+			filter: func() {},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, filterValue(tc.filter))
+		})
+	}
+}
+
+func TestAppSec(t *testing.T) {
+	t.Setenv("DD_APPSEC_RULES", "../../../../internal/appsec/testdata/rasp_nosql.json")
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.RASPEnabled() {
+		t.Skip("RASP needs to be enabled for this test")
+	}
+
+	// No server listens to this address: the commands that are sent fail to select a server.
+	var (
+		mu       sync.Mutex
+		commands []string
+	)
+	monitor := NewMonitor()
+	opts := options.Client().
+		ApplyURI("mongodb://127.0.0.1:1/?connect=direct").
+		SetServerSelectionTimeout(10 * time.Millisecond).
+		SetMonitor(&event.CommandMonitor{
+			Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+				mu.Lock()
+				commands = append(commands, evt.CommandName)
+				mu.Unlock()
+				monitor.Started(ctx, evt)
+			},
+			Succeeded: monitor.Succeeded,
+			Failed:    monitor.Failed,
+		})
+	client, err := mongo.Connect(context.Background(), opts)
+	require.NoError(t, err)
+	defer client.Disconnect(context.Background())
+	db := WrapDatabase(client.Database("test-database"))
+	coll := db.Collection("test-collection")
+
+	operations := map[string]func(ctx context.Context, where string) error{
+		"find": func(ctx context.Context, where string) error {
+			_, err := coll.Find(ctx, bson.D{{Key: "$where", Value: where}})
+			return err
+		},
+		"find-one": func(ctx context.Context, where string) error {
+			return coll.FindOne(ctx, bson.D{{Key: "$where", Value: where}}).Err()
+		},
+		"aggregate": func(ctx context.Context, where string) error {
+			_, err := coll.Aggregate(ctx, mongo.Pipeline{{{Key: "$match", Value: bson.D{{Key: "$where", Value: where}}}}})
+			return err
+		},
+		"database-aggregate": func(ctx context.Context, where string) error {
+			_, err := db.Aggregate(ctx, mongo.Pipeline{{{Key: "$match", Value: bson.D{{Key: "$where", Value: where}}}}})
+			return err
+		},
+		"update-one": func(ctx context.Context, where string) error {
+			_, err := coll.UpdateOne(ctx, bson.D{{Key: "$where", Value: where}}, bson.D{{Key: "$set", Value: bson.D{{Key: "admin", Value: true}}}})
+			return err
+		},
+		"update-many": func(ctx context.Context, where string) error {
+			_, err := coll.UpdateMany(ctx, bson.D{{Key: "$where", Value: where}}, bson.D{{Key: "$set", Value: bson.D{{Key: "admin", Value: true}}}})
+			return err
+		},
+		"replace-one": func(ctx context.Context, where string) error {
+			_, err := coll.ReplaceOne(ctx, bson.D{{Key: "$where", Value: where}}, bson.D{{Key: "admin", Value: true}})
+			return err
+		},
+		"delete-one": func(ctx context.Context, where string) error {
+			_, err := coll.DeleteOne(ctx, bson.D{{Key: "$where", Value: where}})
+			return err
+		},
+		"delete-many": func(ctx context.Context, where string) error {
+			_, err := coll.DeleteMany(ctx, bson.D{{Key: "$where", Value: where}})
+			return err
+		},
+		"count-documents": func(ctx context.Context, where string) error {
+			_, err := coll.CountDocuments(ctx, bson.D{{Key: "$where", Value: where}})
+			return err
+		},
+		"distinct": func(ctx context.Context, where string) error {
+			_, err := coll.Distinct(ctx, "name", bson.D{{Key: "$where", Value: where}})
+			return err
+		},
+		"find-one-and-delete": func(ctx context.Context, where string) error {
+			return coll.FindOneAndDelete(ctx, bson.D{{Key: "$where", Value: where}}).Err()
+		},
+		"find-one-and-replace": func(ctx context.Context, where string) error {
+			return coll.FindOneAndReplace(ctx, bson.D{{Key: "$where", Value: where}}, bson.D{{Key: "admin", Value: true}}).Err()
+		},
+		"find-one-and-update": func(ctx context.Context, where string) error {
+			return coll.FindOneAndUpdate(ctx, bson.D{{Key: "$where", Value: where}}, bson.D{{Key: "$set", Value: bson.D{{Key: "admin", Value: true}}}}).Err()
+		},
+	}
+
+	for name, operation := range operations {
+		var opErr error
+		mux := httptrace.NewServeMux()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			opErr = operation(r.Context(), r.URL.Query().Get("where"))
+			if events.IsSecurityError(opErr) {
+				return
+			}
+			w.Write([]byte("Hello World!\n"))
+		})
+		srv := httptest.NewServer(mux)
+
+		t.Run(name, func(t *testing.T) {
+			t.Run("no-error", func(t *testing.T) {
+				res, err := srv.Client().Get(srv.URL + "/?where=" + url.QueryEscape("this.age > 18"))
+				require.NoError(t, err)
+				defer res.Body.Close()
+				require.Equal(t, 200, res.StatusCode)
+				require.Error(t, opErr)
+				require.False(t, events.IsSecurityError(opErr))
+			})
+
+			t.Run("injection", func(t *testing.T) {
+				mu.Lock()
+				commands = nil
+				mu.Unlock()
+
+				res, err := srv.Client().Get(srv.URL + "/?where=" + url.QueryEscape("sleep(5000) || true"))
+				require.NoError(t, err)
+				defer res.Body.Close()
+				require.Equal(t, 403, res.StatusCode)
+				var blockErr *events.BlockingSecurityEvent
+				require.True(t, errors.As(opErr, &blockErr))
+
+				mu.Lock()
+				defer mu.Unlock()
+				require.Empty(t, commands)
+			})
+		})
+		srv.Close()
+	}
+}
@@ -7,6 +7,8 @@
 // It support v0.2.0 of github.com/mongodb/mongo-go-driver
 //
 // `NewMonitor` will return an event.CommandMonitor which is used to trace requests.
+// `WrapDatabase` and `WrapCollection` protect the operations against NoSQL
+// injections when AppSec RASP is enabled.
 package mongo
 
 import (
@@ -100,6 +102,8 @@ func (m *monitor) Finished(evt *event.CommandFinishedEvent, err error) {
 }
 
 // NewMonitor creates a new mongodb event CommandMonitor.
+// As a command monitor cannot interrupt the commands, AppSec RASP protection
+// against NoSQL injections requires using WrapDatabase or WrapCollection.
 func NewMonitor(opts ...Option) *event.CommandMonitor {
 	cfg := new(config)
 	defaults(cfg)
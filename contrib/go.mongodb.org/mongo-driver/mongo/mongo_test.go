@@ -25,16 +25,14 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func TestMain(m *testing.M) {
-	_, ok := os.LookupEnv("INTEGRATION")
-	if !ok {
-		fmt.Println("--- SKIP: to enable integration test, set the INTEGRATION environment variable")
-		os.Exit(0)
+func skipIntegrationTest(t *testing.T) {
+	if _, ok := os.LookupEnv("INTEGRATION"); !ok {
+		t.Skip("🚧 Skipping integration test (INTEGRATION environment variable is not set)")
 	}
-	os.Exit(m.Run())
 }
 
 func Test(t *testing.T) {
+	skipIntegrationTest(t)
 	mt := mocktracer.Start()
 	defer mt.Stop()
 
@@ -84,6 +82,7 @@ func Test(t *testing.T) {
 }
 
 func TestAnalyticsSettings(t *testing.T) {
+	skipIntegrationTest(t)
 	assertRate := func(t *testing.T, mt mocktracer.Tracer, rate interface{}, opts ...Option) {
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
 		defer cancel()
@@ -141,6 +140,7 @@ func TestAnalyticsSettings(t *testing.T) {
 }
 
 func TestNamingSchema(t *testing.T) {
+	skipIntegrationTest(t)
 	genSpans := namingschematest.GenSpansFn(func(t *testing.T, serviceOverride string) []mocktracer.Span {
 		var opts []Option
 		if serviceOverride != "" {
@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package zap_test
+
+import (
+	"context"
+
+	zaptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/go.uber.org/zap"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func ExampleFields() {
+	tracer.Start()
+	defer tracer.Stop()
+
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "mySpan")
+	defer span.Finish()
+
+	// Pass the trace and span IDs of the span in ctx to the logger.
+	logger.Info("Completed some work!", zaptrace.Fields(ctx)...)
+}
+
+func ExampleWrapCore() {
+	tracer.Start()
+	defer tracer.Stop()
+
+	logger, _ := zap.NewProduction(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		// Mark spans as errored when logging at error level.
+		return zaptrace.WrapCore(c, zaptrace.WithErrorLevel(zap.ErrorLevel))
+	}))
+	defer logger.Sync()
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "mySpan")
+	defer span.Finish()
+
+	// Every entry logged by this logger will be correlated with span.
+	logger = logger.With(zaptrace.Context(ctx))
+	logger.Info("Completed some work!")
+}
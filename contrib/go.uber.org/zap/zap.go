@@ -0,0 +1,164 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package zap provides log/span correlation for the go.uber.org/zap package (https://github.com/uber-go/zap).
+package zap
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/contrib/internal/logcorrelation"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const componentName = "go.uber.org/zap"
+
+func init() {
+	telemetry.LoadIntegration(componentName)
+	tracer.MarkIntegrationImported(componentName)
+}
+
+// Fields returns the zap fields correlating a log entry with the span found
+// in ctx, using the default configuration, which is built on the first call.
+// It returns nil if ctx holds no span.
+//
+//	logger.Info("Completed some work!", zaptrace.Fields(ctx)...)
+func Fields(ctx context.Context) []zap.Field {
+	span, ok := tracer.SpanFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	defaultCfgOnce.Do(func() {
+		defaultCfg = newConfig()
+	})
+	return defaultCfg.fields(span.Context())
+}
+
+var (
+	// defaultCfg is the configuration used by Fields, built once as it reads
+	// the environment.
+	defaultCfg     *config
+	defaultCfgOnce sync.Once
+)
+
+// fields returns the zap fields correlating a log entry with the given span
+// context.
+func (cfg *config) fields(ctx ddtrace.SpanContext) []zap.Field {
+	tags := cfg.serviceTags.Tags()
+	fields := make([]zap.Field, 0, 2+len(tags))
+	fields = append(fields,
+		zap.String(logcorrelation.KeyTraceID, logcorrelation.TraceID(ctx, cfg.use128BitTraceID)),
+		zap.String(logcorrelation.KeySpanID, logcorrelation.SpanID(ctx)),
+	)
+	for _, tag := range tags {
+		fields = append(fields, zap.String(tag.Key, tag.Value))
+	}
+	return fields
+}
+
+// contextKey is the key of the field created by Context.
+const contextKey = "dd.context"
+
+// contextValue is the value of the field created by Context.
+type contextValue struct {
+	ctx context.Context
+}
+
+// Context returns a field carrying ctx. When written through a core returned
+// by WrapCore, the field is replaced by the fields correlating the log entry
+// with the span found in ctx. Other cores ignore it.
+//
+//	logger.Info("Completed some work!", zaptrace.Context(ctx))
+func Context(ctx context.Context) zap.Field {
+	return zap.Field{Key: contextKey, Type: zapcore.SkipType, Interface: contextValue{ctx}}
+}
+
+// WrapCore returns a zapcore.Core which replaces the fields created by Context
+// with the trace and unified service tagging information of the span found in
+// the context, before writing entries to c.
+//
+//	logger := zap.New(zaptrace.WrapCore(core))
+func WrapCore(c zapcore.Core, opts ...Option) zapcore.Core {
+	cfg := newConfig(opts...)
+	log.Debug("contrib/go.uber.org/zap: Configuring Core: %#v", cfg)
+	return &core{Core: c, cfg: cfg}
+}
+
+type core struct {
+	zapcore.Core
+	cfg *config
+	// span is the span found in a field added with With, if any.
+	span ddtrace.Span
+}
+
+// With implements zapcore.Core.
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	fields, span := c.inject(fields)
+	if span == nil {
+		span = c.span
+	}
+	return &core{Core: c.Core.With(fields), cfg: c.cfg, span: span}
+}
+
+// Check implements zapcore.Core.
+func (c *core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write implements zapcore.Core.
+func (c *core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	fields, span := c.inject(fields)
+	if span == nil {
+		span = c.span
+	}
+	if span != nil && c.cfg.errorLevel != nil && c.cfg.errorLevel.Enabled(ent.Level) {
+		span.SetTag(ext.Error, entryError(ent, fields))
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// inject replaces the first field created by Context with the fields of the
+// span found in its context. It returns the resulting fields and the span.
+func (c *core) inject(fields []zapcore.Field) ([]zapcore.Field, ddtrace.Span) {
+	for i, f := range fields {
+		v, ok := f.Interface.(contextValue)
+		if !ok || f.Type != zapcore.SkipType {
+			continue
+		}
+		span, ok := tracer.SpanFromContext(v.ctx)
+		if !ok {
+			return fields, nil
+		}
+		injected := make([]zapcore.Field, 0, len(fields)+4)
+		injected = append(injected, fields[:i]...)
+		injected = append(injected, c.cfg.fields(span.Context())...)
+		injected = append(injected, fields[i+1:]...)
+		return injected, span
+	}
+	return fields, nil
+}
+
+// entryError returns the first error field, or an error holding the entry's
+// message if there is none.
+func entryError(ent zapcore.Entry, fields []zapcore.Field) error {
+	for _, f := range fields {
+		if err, ok := f.Interface.(error); ok && f.Type == zapcore.ErrorType {
+			return err
+		}
+	}
+	return errors.New(ent.Message)
+}
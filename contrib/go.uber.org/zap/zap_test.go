@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package zap
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFields(t *testing.T) {
+	tracer.Start(tracer.WithLogger(testLogger{}))
+	defer tracer.Stop()
+	t.Setenv("DD_ENV", "prod")
+	t.Setenv("DD_VERSION", "1.2.3")
+
+	assert.Nil(t, Fields(context.Background()))
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "test")
+	defer span.Finish()
+	fields := zapcore.NewMapObjectEncoder()
+	for _, f := range Fields(ctx) {
+		f.AddTo(fields)
+	}
+	assert.Equal(t, span.Context().(ddtrace.SpanContextW3C).TraceID128(), fields.Fields["dd.trace_id"])
+	assert.Equal(t, strconv.FormatUint(span.Context().SpanID(), 10), fields.Fields["dd.span_id"])
+	assert.Equal(t, "prod", fields.Fields["dd.env"])
+	assert.Equal(t, "1.2.3", fields.Fields["dd.version"])
+}
+
+func TestWrapCore(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	obs, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(WrapCore(obs, WithService("svc"), WithErrorLevel(zap.ErrorLevel)))
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "test")
+	logger.Info("with span", Context(ctx), zap.Int("n", 1))
+	logger.Info("without span", Context(context.Background()))
+	logger.With(Context(ctx)).Error("failed", zap.Error(errors.New("boom")))
+	logger.Debug("disabled", Context(ctx))
+	span.Finish()
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 3)
+
+	traceID := strconv.FormatUint(span.Context().TraceID(), 10)
+	spanID := strconv.FormatUint(span.Context().SpanID(), 10)
+	m := entries[0].ContextMap()
+	assert.Equal(t, traceID, m["dd.trace_id"])
+	assert.Equal(t, spanID, m["dd.span_id"])
+	assert.Equal(t, "svc", m["dd.service"])
+	assert.Equal(t, int64(1), m["n"])
+	assert.NotContains(t, entries[1].ContextMap(), "dd.trace_id")
+	assert.Equal(t, traceID, entries[2].ContextMap()["dd.trace_id"])
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.EqualError(t, spans[0].Tag("error").(error), "boom")
+}
+
+type testLogger struct{}
+
+func (testLogger) Log(string) {}
@@ -0,0 +1,175 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package fiber
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pappsec "gopkg.in/DataDog/dd-trace-go.v1/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppSec(t *testing.T) {
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.Enabled() {
+		t.Skip("appsec disabled")
+	}
+
+	app := fiber.New()
+	app.Use(Middleware())
+	app.All("/lfi/*", func(c *fiber.Ctx) error {
+		return c.SendString("Hello World!\n")
+	})
+	app.All("/body", func(c *fiber.Ctx) error {
+		pappsec.MonitorParsedHTTPBody(c.UserContext(), "$globals")
+		return c.SendString("Hello Body!\n")
+	})
+	app.All("/path/:myPathParam", func(c *fiber.Ctx) error {
+		return c.SendString("Hello Params!\n")
+	})
+
+	for _, tc := range []struct {
+		name      string
+		url       string
+		body      string
+		ruleMatch string
+		address   string
+	}{
+		{
+			name:      "request-uri",
+			url:       "/lfi/..%2F..%2F..%2Fsecret.txt",
+			body:      "Hello World!\n",
+			ruleMatch: "crs-930-100",
+			address:   "server.request.uri.raw",
+		},
+		{
+			name:      "SDK-body",
+			url:       "/body",
+			body:      "Hello Body!\n",
+			ruleMatch: "crs-933-130",
+			address:   "server.request.body",
+		},
+		{
+			name:      "path-params",
+			url:       "/path/appscan_fingerprint",
+			body:      "Hello Params!\n",
+			ruleMatch: "crs-913-120",
+			address:   "server.request.path_params",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			res, err := app.Test(httptest.NewRequest("POST", tc.url, nil))
+			require.NoError(t, err)
+			defer res.Body.Close()
+			require.Equal(t, 200, res.StatusCode)
+			b, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.Equal(t, tc.body, string(b))
+
+			spans := mt.FinishedSpans()
+			require.NotEmpty(t, spans)
+			event, _ := spans[0].Tag("_dd.appsec.json").(string)
+			require.Contains(t, event, tc.ruleMatch)
+			require.Contains(t, event, tc.address)
+		})
+	}
+}
+
+// Test that IP and user blocking works by using custom rules/rules data
+func TestBlocking(t *testing.T) {
+	t.Setenv("DD_APPSEC_RULES", "../../../internal/appsec/testdata/blocking.json")
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.Enabled() {
+		t.Skip("AppSec needs to be enabled for this test")
+	}
+
+	app := fiber.New()
+	app.Use(Middleware())
+	app.All("/ip", func(c *fiber.Ctx) error {
+		return c.SendString("Hello World!\n")
+	})
+	app.All("/user", func(c *fiber.Ctx) error {
+		if err := pappsec.SetUser(c.UserContext(), c.Get("test-usr")); err != nil {
+			return err
+		}
+		return c.SendString("Hello World!\n")
+	})
+
+	for _, tc := range []struct {
+		name      string
+		url       string
+		headers   map[string]string
+		status    int
+		ruleMatch string
+	}{
+		{
+			name:   "ip/no-block",
+			url:    "/ip",
+			status: 200,
+		},
+		{
+			name:      "ip/block",
+			url:       "/ip",
+			headers:   map[string]string{"x-forwarded-for": "1.2.3.4"},
+			status:    403,
+			ruleMatch: "blk-001-001",
+		},
+		{
+			name:    "user/no-block",
+			url:     "/user",
+			headers: map[string]string{"test-usr": "legit-user"},
+			status:  200,
+		},
+		{
+			name:      "user/block",
+			url:       "/user",
+			headers:   map[string]string{"test-usr": "blocked-user-1"},
+			status:    403,
+			ruleMatch: "blk-001-002",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			req := httptest.NewRequest("POST", tc.url, nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+			require.Equal(t, tc.status, res.StatusCode)
+			b, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+
+			spans := mt.FinishedSpans()
+			require.Len(t, spans, 1)
+			require.Equal(t, spans[0].Tag(ext.HTTPCode), res.Status[:3])
+			if tc.ruleMatch == "" {
+				require.Equal(t, "Hello World!\n", string(b))
+				require.Nil(t, spans[0].Tag("_dd.appsec.json"))
+				return
+			}
+			require.True(t, strings.Contains(string(b), "Security provided by Datadog"))
+			require.Equal(t, true, spans[0].Tag("appsec.blocked"))
+			require.Contains(t, spans[0].Tag("_dd.appsec.json"), tc.ruleMatch)
+		})
+	}
+}
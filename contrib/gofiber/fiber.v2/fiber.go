@@ -7,14 +7,17 @@
 package fiber // import "gopkg.in/DataDog/dd-trace-go.v1/contrib/gofiber/fiber.v2"
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"net/http"
 	"strconv"
 
+	"gopkg.in/DataDog/dd-trace-go.v1/contrib/internal/fasthttptrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
 
@@ -77,7 +80,19 @@ func Middleware(opts ...Option) func(c *fiber.Ctx) error {
 		c.SetUserContext(ctx)
 
 		// pass the execution down the line
-		err := c.Next()
+		var (
+			err     error
+			blocked bool
+		)
+		if appsec.Enabled() {
+			// The route, and so the path parameters, are only matched by c.Next() when the middleware is used globally.
+			blocked = fasthttptrace.UseAppSec(ctx, c.Context(), span, c.AllParams, func(ctx context.Context) {
+				c.SetUserContext(ctx)
+				err = c.Next()
+			})
+		} else {
+			err = c.Next()
+		}
 
 		span.SetTag(ext.ResourceName, cfg.resourceNamer(c))
 		span.SetTag(ext.HTTPRoute, c.Route().Path)
@@ -97,6 +112,10 @@ func Middleware(opts ...Option) func(c *fiber.Ctx) error {
 			// mark 5xx server error
 			span.SetTag(ext.Error, fmt.Errorf("%d: %s", status, http.StatusText(status)))
 		}
+		if blocked {
+			// Don't let the fiber error handler overwrite the blocking response
+			return nil
+		}
 		return err
 	}
 }
@@ -40,6 +40,10 @@ func appsecUnaryHandlerMiddleware(method string, span ddtrace.Span, handler grpc
 		}
 		ctx, op := grpcsec.StartHandlerOperation(ctx, args, nil, func(op *types.HandlerOperation) {
 			dyngo.OnData(op, func(a *sharedsec.GRPCAction) {
+				if err := a.BlockingError(method); err != nil {
+					blockedErr = err
+					return
+				}
 				code, err := a.GRPCWrapper()
 				blockedErr = status.Error(codes.Code(code), err.Error())
 			})
@@ -97,6 +101,10 @@ func appsecStreamHandlerMiddleware(method string, span ddtrace.Span, handler grp
 		}
 		ctx, op := grpcsec.StartHandlerOperation(ctx, args, nil, func(op *types.HandlerOperation) {
 			dyngo.OnData(op, func(a *sharedsec.GRPCAction) {
+				if err := a.BlockingError(method); err != nil {
+					appsecStream.blockedErr = err
+					return
+				}
 				code, e := a.GRPCWrapper()
 				appsecStream.blockedErr = status.Error(codes.Code(code), e.Error())
 			})
@@ -13,6 +13,7 @@ import (
 	"testing"
 
 	pappsec "gopkg.in/DataDog/dd-trace-go.v1/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec/events"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
 
@@ -238,6 +239,30 @@ func TestBlocking(t *testing.T) {
 	})
 }
 
+func TestBlockingError(t *testing.T) {
+	t.Setenv("DD_APPSEC_RULES", "../../../internal/appsec/testdata/blocking.json")
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.Enabled() {
+		t.Skip("appsec disabled")
+	}
+
+	pappsec.SetGRPCBlockingError(func(a events.BlockingAction) error {
+		return status.Errorf(codes.PermissionDenied, "blocked: %s", a.Route)
+	})
+	defer pappsec.SetGRPCBlockingError(nil)
+
+	rig, err := newAppsecRig(false)
+	require.NoError(t, err)
+	defer rig.Close()
+
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("x-client-ip", "1.2.3.4"))
+	reply, err := rig.client.Ping(ctx, &FixtureRequest{Name: "hello"})
+	require.Nil(t, reply)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.Equal(t, "blocked: /grpc.Fixture/Ping", status.Convert(err).Message())
+}
+
 func TestPasslist(t *testing.T) {
 	// This custom rule file includes two rules detecting the same sec event, a grpc metadata value containing "zouzou",
 	// but only one of them is passlisted (custom-1 is passlisted, custom-2 is not and must trigger).
@@ -125,6 +125,10 @@ func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
 			// it's possible there's already a span on the context even though
 			// we're not tracing calls, so inject it if it's there
 			ctx = injectSpanIntoContext(ctx)
+			// untraced methods get no checkpoints, like unary calls
+			if _, ok := cfg.untracedMethods[method]; cfg.dataStreams && !ok {
+				ctx = setDataStreamsClientCheckpoint(ctx)
+			}
 
 			var err error
 			stream, err = streamer(ctx, desc, cc, method, opts...)
@@ -192,6 +196,9 @@ func doClientRequest(
 	opts = append(opts, grpc.Peer(&p))
 
 	handlerCtx := injectSpanIntoContext(ctx)
+	if cfg.dataStreams {
+		handlerCtx = setDataStreamsClientCheckpoint(handlerCtx)
+	}
 	err := handler(handlerCtx, opts)
 
 	setSpanTargetFromPeer(span, p)
@@ -16,6 +16,7 @@ import (
 	"strings"
 
 	"gopkg.in/DataDog/dd-trace-go.v1/contrib/google.golang.org/internal/grpcutil"
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
@@ -76,6 +77,35 @@ func startSpanFromContext(
 	return tracer.StartSpanFromContext(ctx, operation, opts...)
 }
 
+// setDataStreamsServerCheckpoint sets the Data Streams Monitoring inbound
+// checkpoint of the call out of the pathway propagated in the incoming
+// metadata, if any, and returns the context holding the resulting pathway.
+func setDataStreamsServerCheckpoint(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx) // nil is ok
+	ctx = datastreams.ExtractFromBase64Carrier(ctx, grpcutil.MDCarrier(md))
+	ctx, _ = tracer.SetDataStreamsCheckpoint(ctx, "direction:in", "type:grpc")
+	return ctx
+}
+
+// setDataStreamsClientCheckpoint sets the Data Streams Monitoring outbound
+// checkpoint of the call, continuing the pathway of the context if any, and
+// injects the resulting pathway into the outgoing metadata.
+func setDataStreamsClientCheckpoint(ctx context.Context) context.Context {
+	ctx, ok := tracer.SetDataStreamsCheckpoint(ctx, "direction:out", "type:grpc")
+	if !ok {
+		return ctx
+	}
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		// we have to copy the metadata because its not safe to modify
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	datastreams.InjectToBase64Carrier(ctx, grpcutil.MDCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
 // finishWithError applies finish option and a tag with gRPC status code, disregarding OK, EOF and Canceled errors.
 func finishWithError(span ddtrace.Span, err error, cfg *config) {
 	if errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) {
@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -20,6 +21,7 @@ import (
 
 	"gopkg.in/DataDog/dd-trace-go.v1/contrib/internal/lists"
 	"gopkg.in/DataDog/dd-trace-go.v1/contrib/internal/namingschematest"
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
@@ -492,6 +494,76 @@ func TestPreservesMetadata(t *testing.T) {
 	assert.Equal(t, []string{"test-value"}, s.Tag(tagMetadataPrefix+"test-key"))
 }
 
+func TestDataStreams(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	rig, err := newRig(true, WithDataStreams())
+	require.NoError(t, err, "error setting up rig")
+	defer rig.Close()
+
+	// A pathway started by a message consumed by the client
+	ctx, _ := tracer.SetDataStreamsCheckpoint(context.Background(), "direction:in", "topic:topic", "type:kafka")
+	_, err = rig.client.Ping(ctx, &FixtureRequest{Name: "pass"})
+	require.NoError(t, err)
+
+	md := rig.fixtureServer.lastRequestMetadata.Load().(metadata.MD)
+	assert.NotEmpty(t, md.Get("dd-pathway-ctx-base64"))
+	p, ok := rig.fixtureServer.lastRequestPathway.Load().(datastreams.Pathway)
+	require.True(t, ok)
+	expectedCtx, _ := tracer.SetDataStreamsCheckpoint(ctx, "direction:out", "type:grpc")
+	expectedCtx, _ = tracer.SetDataStreamsCheckpoint(expectedCtx, "direction:in", "type:grpc")
+	expected, _ := datastreams.PathwayFromContext(expectedCtx)
+	assert.NotEqual(t, uint64(0), expected.GetHash())
+	assert.Equal(t, expected.GetHash(), p.GetHash())
+}
+
+func TestDataStreamsUntracedMethods(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	const method = "/grpc.Fixture/Ping"
+	errStop := errors.New("stop")
+	// A pathway started by a message consumed by the client
+	ctx, _ := tracer.SetDataStreamsCheckpoint(context.Background(), "direction:in", "topic:topic", "type:kafka")
+	for name, tc := range map[string]struct {
+		opts     []Option
+		expected bool
+	}{
+		"traced": {
+			opts:     []Option{WithDataStreams()},
+			expected: true,
+		},
+		"untraced": {
+			opts: []Option{WithDataStreams(), WithUntracedMethods(method)},
+		},
+		"untraced-calls": {
+			opts:     []Option{WithDataStreams(), WithStreamCalls(false)},
+			expected: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var md metadata.MD
+			invoker := func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+				md, _ = metadata.FromOutgoingContext(ctx)
+				return errStop
+			}
+			err := UnaryClientInterceptor(tc.opts...)(ctx, method, nil, nil, nil, invoker)
+			require.Equal(t, errStop, err)
+			assert.Equal(t, tc.expected, len(md.Get("dd-pathway-ctx-base64")) > 0, "unary")
+
+			md = nil
+			streamer := func(ctx context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+				md, _ = metadata.FromOutgoingContext(ctx)
+				return nil, errStop
+			}
+			_, err = StreamClientInterceptor(tc.opts...)(ctx, &grpc.StreamDesc{}, nil, method, streamer)
+			require.Equal(t, errStop, err)
+			assert.Equal(t, tc.expected, len(md.Get("dd-pathway-ctx-base64")) > 0, "stream")
+		})
+	}
+}
+
 func TestStreamSendsErrorCode(t *testing.T) {
 	wantCode := codes.InvalidArgument.String()
 
@@ -540,6 +612,7 @@ func TestStreamSendsErrorCode(t *testing.T) {
 type fixtureServer struct {
 	UnimplementedFixtureServer
 	lastRequestMetadata atomic.Value
+	lastRequestPathway  atomic.Value
 }
 
 func (s *fixtureServer) StreamPing(stream Fixture_StreamPingServer) (err error) {
@@ -569,6 +642,9 @@ func (s *fixtureServer) Ping(ctx context.Context, in *FixtureRequest) (*FixtureR
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
 		s.lastRequestMetadata.Store(md)
 	}
+	if p, ok := datastreams.PathwayFromContext(ctx); ok {
+		s.lastRequestPathway.Store(p)
+	}
 	switch {
 	case in.Name == "child":
 		span, _ := tracer.StartSpanFromContext(ctx, "child")
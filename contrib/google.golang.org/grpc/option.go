@@ -40,6 +40,7 @@ type config struct {
 	withErrorDetailTags bool
 	spanOpts            []ddtrace.StartSpanOption
 	tags                map[string]interface{}
+	dataStreams         bool
 }
 
 // InterceptorOption represents an option that can be passed to the grpc unary
@@ -220,3 +221,14 @@ func WithSpanOptions(opts ...ddtrace.StartSpanOption) Option {
 		cfg.spanOpts = append(cfg.spanOpts, opts...)
 	}
 }
+
+// WithDataStreams enables the Data Streams Monitoring checkpoints of the
+// calls, along with the propagation of the pathways in the call metadata, so
+// that the pathways flow through the gRPC clients and servers. The methods
+// excluded with WithUntracedMethods, or WithIgnoredMethods on servers, get no
+// checkpoints.
+func WithDataStreams() Option {
+	return func(cfg *config) {
+		cfg.dataStreams = true
+	}
+}
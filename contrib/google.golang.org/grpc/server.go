@@ -117,6 +117,10 @@ func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
 			}
 		}
 
+		if cfg.dataStreams && !im && !um {
+			ctx = setDataStreamsServerCheckpoint(ctx)
+		}
+
 		// call the original handler with a new stream, which traces each send
 		// and recv if message tracing is enabled
 		return handler(srv, &serverStream{
@@ -152,6 +156,9 @@ func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
 				tracer.Tag(ext.SpanKind, ext.SpanKindServer))...,
 		)
 		span.SetTag(tagMethodKind, methodKindUnary)
+		if cfg.dataStreams {
+			ctx = setDataStreamsServerCheckpoint(ctx)
+		}
 		withMetadataTags(ctx, cfg, span)
 		withRequestTags(cfg, req, span)
 		if appsec.Enabled() {
@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package gorm
+
+import (
+	"context"
+	"database/sql"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/sqlsec"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+)
+
+// registerAppSecCallbacks registers the callbacks running the ASM RASP SQLi
+// checks on the queries built by gorm, before they are executed.
+func registerAppSecCallbacks(db *gorm.DB) error {
+	cb := db.Callback()
+	err := cb.Query().Before("gorm:query").Register("dd-trace-go:appsec_query", protectBuiltQuery)
+	if err != nil {
+		return err
+	}
+	err = cb.Row().Before("gorm:row").Register("dd-trace-go:appsec_row", protectBuiltQuery)
+	if err != nil {
+		return err
+	}
+	err = cb.Raw().Before("gorm:raw").Register("dd-trace-go:appsec_raw", protectQuery)
+	if err != nil {
+		return err
+	}
+	// The create, update and delete callbacks build and execute their queries
+	// at once, so the queries are checked by the connection pool instead. The
+	// connection pool is only replaced around these callbacks, once the
+	// transaction began and before the associations are saved.
+	err = cb.Create().After("gorm:save_before_associations").Before("gorm:create").Register("dd-trace-go:appsec_before_create", protectConnPool)
+	if err != nil {
+		return err
+	}
+	err = cb.Create().After("gorm:create").Before("gorm:save_after_associations").Register("dd-trace-go:appsec_after_create", unprotectConnPool)
+	if err != nil {
+		return err
+	}
+	err = cb.Update().After("gorm:save_before_associations").Before("gorm:update").Register("dd-trace-go:appsec_before_update", protectConnPool)
+	if err != nil {
+		return err
+	}
+	err = cb.Update().After("gorm:update").Before("gorm:save_after_associations").Register("dd-trace-go:appsec_after_update", unprotectConnPool)
+	if err != nil {
+		return err
+	}
+	err = cb.Delete().After("gorm:delete_before_associations").Before("gorm:delete").Register("dd-trace-go:appsec_before_delete", protectConnPool)
+	if err != nil {
+		return err
+	}
+	return cb.Delete().After("gorm:delete").Before("gorm:after_delete").Register("dd-trace-go:appsec_after_delete", unprotectConnPool)
+}
+
+// protectBuiltQuery builds the SQL query of the statement the same way the
+// gorm query callbacks do, and runs the ASM RASP SQLi checks on it.
+func protectBuiltQuery(db *gorm.DB) {
+	if !appsec.RASPEnabled() || db.Error != nil || db.DryRun {
+		return
+	}
+	callbacks.BuildQuerySQL(db)
+	protectQuery(db)
+}
+
+// protectQuery runs the ASM RASP SQLi checks on the SQL query of the
+// statement. If it's unsafe to run, the *events.BlockingSecurityEvent is added
+// to the errors of db, which prevents gorm from running the query.
+func protectQuery(db *gorm.DB) {
+	if !appsec.RASPEnabled() || db.Error != nil || db.DryRun || db.Statement == nil || db.Statement.Context == nil {
+		return
+	}
+	query := db.Statement.SQL.String()
+	if query == "" {
+		return
+	}
+	if err := sqlsec.ProtectSQLOperation(db.Statement.Context, query, dbSystem(db.Dialector)); err != nil {
+		db.AddError(err)
+		return
+	}
+	// Avoid checking the query again when it is run through contrib/database/sql
+	db.Statement.Context = sqlsec.WithProtectedQuery(db.Statement.Context, query)
+}
+
+// protectConnPool makes the connection pool of the statement run the ASM RASP
+// SQLi checks on the queries executed by the create, update and delete
+// callbacks. The original connection pool is restored by unprotectConnPool
+// once the callback returns, since the following callbacks may rely on its
+// actual type, such as the transaction commit.
+func protectConnPool(db *gorm.DB) {
+	if !appsec.RASPEnabled() || db.Error != nil || db.DryRun || db.Statement == nil || db.Statement.ConnPool == nil {
+		return
+	}
+	db.Statement.ConnPool = &protectedConnPool{ConnPool: db.Statement.ConnPool, system: dbSystem(db.Dialector)}
+}
+
+// unprotectConnPool restores the connection pool replaced by protectConnPool.
+func unprotectConnPool(db *gorm.DB) {
+	if db.Statement == nil {
+		return
+	}
+	if p, ok := db.Statement.ConnPool.(*protectedConnPool); ok {
+		db.Statement.ConnPool = p.ConnPool
+	}
+}
+
+// protectedConnPool is a gorm.ConnPool running the ASM RASP SQLi checks on the
+// queries before executing them. Unsafe queries fail with the
+// *events.BlockingSecurityEvent, which gorm adds to the errors of the
+// statement.
+type protectedConnPool struct {
+	gorm.ConnPool
+	system string
+}
+
+func (p *protectedConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, err := p.protect(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return p.ConnPool.ExecContext(ctx, query, args...)
+}
+
+func (p *protectedConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, err := p.protect(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return p.ConnPool.QueryContext(ctx, query, args...)
+}
+
+func (p *protectedConnPool) protect(ctx context.Context, query string) (context.Context, error) {
+	if ctx == nil || query == "" {
+		return ctx, nil
+	}
+	if err := sqlsec.ProtectSQLOperation(ctx, query, p.system); err != nil {
+		return ctx, err
+	}
+	// Avoid checking the query again when it is run through contrib/database/sql
+	return sqlsec.WithProtectedQuery(ctx, query), nil
+}
+
+// dbSystem returns the database system identifier of the given dialector.
+func dbSystem(d gorm.Dialector) string {
+	if d == nil {
+		return ext.DBSystemOtherSQL
+	}
+	switch name := d.Name(); name {
+	case "postgres":
+		return ext.DBSystemPostgreSQL
+	case "sqlserver":
+		return ext.DBSystemMicrosoftSQLServer
+	default:
+		return name
+	}
+}
@@ -112,6 +112,10 @@ func withCallbacks(db *gorm.DB, opts ...Option) (*gorm.DB, error) {
 	if err != nil {
 		return db, err
 	}
+	err = registerAppSecCallbacks(db)
+	if err != nil {
+		return db, err
+	}
 	return db, nil
 }
 
@@ -0,0 +1,153 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package fasthttptrace
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/httpsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+
+	"github.com/valyala/fasthttp"
+)
+
+// UseAppSec monitors the request of fctx with AppSec and calls next with ctx
+// extended with the AppSec operation, unless the request is blocked
+// beforehand. The operation is also stored in fctx so that fctx can be used as
+// the context of the downstream calls, such as database queries, protected by
+// AppSec. When not nil, pathParams is called once next returns to monitor the
+// path parameters of the request, for the frameworks matching the route after
+// their middlewares. It returns true when the request was blocked, in which
+// case the response of fctx is the blocking response.
+func UseAppSec(ctx context.Context, fctx *fasthttp.RequestCtx, span tracer.Span, pathParams func() map[string]string, next func(ctx context.Context)) (blocked bool) {
+	r, err := newHTTPRequest(fctx)
+	if err != nil {
+		log.Debug("appsec: could not convert the fasthttp request: %v", err)
+		next(ctx)
+		return false
+	}
+	h := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		fctx.SetUserValue(listener.ContextKey{}, ctx.Value(listener.ContextKey{}))
+		next(ctx)
+	})
+	w := &responseWriter{fctx: fctx}
+	httpsec.WrapHandler(h, span, nil, &httpsec.Config{
+		OnBlock:      []func(){func() { blocked = true }},
+		ResponseBody: w,
+		PathParams:   pathParams,
+	}).ServeHTTP(w, r.WithContext(ctx))
+	return blocked
+}
+
+// newHTTPRequest returns the http.Request equivalent to the request of fctx.
+// Unlike fasthttpadaptor.ConvertRequest, the values are copied so that they
+// can outlive the request.
+func newHTTPRequest(fctx *fasthttp.RequestCtx) (*http.Request, error) {
+	uri := string(fctx.RequestURI())
+	u, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	h := make(http.Header)
+	fctx.Request.Header.VisitAll(func(k, v []byte) {
+		h.Add(string(k), string(v))
+	})
+	body := fctx.PostBody()
+	return &http.Request{
+		Method:        string(fctx.Method()),
+		URL:           u,
+		Proto:         string(fctx.Request.Header.Protocol()),
+		Header:        h,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Host:          string(fctx.Host()),
+		RemoteAddr:    fctx.RemoteAddr().String(),
+		RequestURI:    uri,
+		TLS:           fctx.TLSConnectionState(),
+	}, nil
+}
+
+// responseWriter is an http.ResponseWriter writing to the response of a
+// fasthttp request context. The response of a fasthttp request context is
+// fully buffered until the handler returns, which allows the response written
+// by the handler to be replaced by the blocking response.
+type responseWriter struct {
+	fctx        *fasthttp.RequestCtx
+	header      http.Header
+	wroteHeader bool
+}
+
+// Header returns the response headers, initialized out of the response of the
+// fasthttp request context the first time it is called.
+func (w *responseWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = make(http.Header)
+		w.fctx.Response.Header.VisitAll(func(k, v []byte) {
+			w.header.Add(string(k), string(v))
+		})
+	}
+	return w.header
+}
+
+// WriteHeader sets the response status code and headers of the fasthttp
+// request context.
+func (w *responseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	for k, values := range w.Header() {
+		w.fctx.Response.Header.Del(k)
+		for _, v := range values {
+			w.fctx.Response.Header.Add(k, v)
+		}
+	}
+	w.fctx.SetStatusCode(status)
+}
+
+// Write appends p to the response body of the fasthttp request context.
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.fctx.Response.AppendBody(p)
+	return len(p), nil
+}
+
+// Status returns the response status code of the fasthttp request context.
+func (w *responseWriter) Status() int {
+	return w.fctx.Response.StatusCode()
+}
+
+// Body is not supported: the response body is not inspected.
+func (*responseWriter) Body() ([]byte, string, bool) {
+	return nil, "", false
+}
+
+// SetBody replaces the response body of the fasthttp request context.
+func (w *responseWriter) SetBody(body []byte) {
+	w.fctx.Response.SetBody(body)
+}
+
+// Discard drops the response body written by the handler so that the blocking
+// response can be written instead.
+func (w *responseWriter) Discard() {
+	w.fctx.Response.ResetBody()
+	w.wroteHeader = false
+}
+
+// Commit has nothing to do since fasthttp writes the response once the
+// handler returns.
+func (*responseWriter) Commit() error {
+	return nil
+}
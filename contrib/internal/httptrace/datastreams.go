@@ -0,0 +1,43 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package httptrace
+
+import (
+	"context"
+	"net/http"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams"
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams/options"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// SetDataStreamsServerCheckpoint sets the Data Streams Monitoring inbound
+// checkpoint of the given server request out of the pathway propagated in its
+// headers, if any. It returns the context holding the resulting pathway, so
+// that the messages produced while handling the request continue it.
+func SetDataStreamsServerCheckpoint(ctx context.Context, r *http.Request) context.Context {
+	ctx = datastreams.ExtractFromBase64Carrier(ctx, tracer.HTTPHeadersCarrier(r.Header))
+	ctx, _ = tracer.SetDataStreamsCheckpointWithParams(ctx, options.CheckpointParams{PayloadSize: requestSize(r)}, "direction:in", "type:http")
+	return ctx
+}
+
+// SetDataStreamsClientCheckpoint sets the Data Streams Monitoring outbound
+// checkpoint of the given client request, continuing the pathway of the
+// request context if any, and injects the resulting pathway into its headers.
+func SetDataStreamsClientCheckpoint(r *http.Request) {
+	ctx, ok := tracer.SetDataStreamsCheckpointWithParams(r.Context(), options.CheckpointParams{PayloadSize: requestSize(r)}, "direction:out", "type:http")
+	if !ok {
+		return
+	}
+	datastreams.InjectToBase64Carrier(ctx, tracer.HTTPHeadersCarrier(r.Header))
+}
+
+func requestSize(r *http.Request) int64 {
+	if r.ContentLength < 0 {
+		return 0
+	}
+	return r.ContentLength
+}
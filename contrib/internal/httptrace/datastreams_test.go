@@ -0,0 +1,38 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package httptrace
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataStreamsCheckpoints(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	// A pathway started by a message consumed by the client
+	ctx, _ := tracer.SetDataStreamsCheckpoint(context.Background(), "direction:in", "topic:topic", "type:kafka")
+	r := httptest.NewRequest("POST", "/", nil).WithContext(ctx)
+	SetDataStreamsClientCheckpoint(r)
+	assert.NotEmpty(t, r.Header.Get("dd-pathway-ctx-base64"))
+
+	p, ok := datastreams.PathwayFromContext(SetDataStreamsServerCheckpoint(context.Background(), r))
+	require.True(t, ok)
+	expectedCtx, _ := tracer.SetDataStreamsCheckpoint(ctx, "direction:out", "type:http")
+	expectedCtx, _ = tracer.SetDataStreamsCheckpoint(expectedCtx, "direction:in", "type:http")
+	expected, _ := datastreams.PathwayFromContext(expectedCtx)
+	assert.NotEqual(t, uint64(0), expected.GetHash())
+	assert.Equal(t, expected.GetHash(), p.GetHash())
+}
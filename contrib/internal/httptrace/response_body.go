@@ -0,0 +1,199 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package httptrace
+
+import (
+	"bufio"
+	"bytes"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// responseBodyState is the state of a ResponseBodyBuffer.
+type responseBodyState int
+
+const (
+	// responseUndecided means the response status has not been written yet.
+	responseUndecided responseBodyState = iota
+	// responseBuffering means the response is being held back.
+	responseBuffering
+	// responsePassthrough means the response is directly written to the
+	// underlying response writer.
+	responsePassthrough
+)
+
+// ResponseBodyBuffer is an http.ResponseWriter holding back JSON and
+// URL-encoded form responses until they are committed, so that their body can
+// be inspected, replaced or discarded before anything is sent to the client.
+// Other responses, and responses whose body exceeds the size limit, are
+// written through to the underlying response writer.
+//
+// Flushing the response writer commits the response, which allows streaming
+// responses to work as expected at the cost of not being inspected.
+type ResponseBodyBuffer struct {
+	w      http.ResponseWriter
+	limit  int
+	state  responseBodyState
+	status int
+	buf    bytes.Buffer
+}
+
+// NewResponseBodyBuffer returns a ResponseBodyBuffer holding back response
+// bodies of up to limit bytes written to w.
+func NewResponseBodyBuffer(w http.ResponseWriter, limit int) *ResponseBodyBuffer {
+	return &ResponseBodyBuffer{w: w, limit: limit}
+}
+
+// Header returns the header map of the underlying response writer.
+func (b *ResponseBodyBuffer) Header() http.Header {
+	return b.w.Header()
+}
+
+// WriteHeader holds back the status code if the response is to be buffered,
+// or writes it to the underlying response writer otherwise.
+func (b *ResponseBodyBuffer) WriteHeader(status int) {
+	switch {
+	case b.state == responsePassthrough:
+		b.w.WriteHeader(status)
+		return
+	case b.state == responseBuffering:
+		// Superfluous call, ignored like net/http does.
+		return
+	case status >= 100 && status < 200:
+		// Informational responses are not final and are sent right away.
+		b.w.WriteHeader(status)
+		return
+	}
+	b.status = status
+	if b.limit > 0 && inspectableContentType(b.w.Header().Get("Content-Type")) {
+		b.state = responseBuffering
+		return
+	}
+	b.state = responsePassthrough
+	b.w.WriteHeader(status)
+}
+
+// Write appends p to the held back body, or writes it to the underlying
+// response writer if the response is not buffered. The response is committed
+// when the body would exceed the size limit.
+func (b *ResponseBodyBuffer) Write(p []byte) (int, error) {
+	if b.state == responseUndecided {
+		b.WriteHeader(http.StatusOK)
+	}
+	if b.state == responseBuffering && b.buf.Len()+len(p) > b.limit {
+		if err := b.Commit(); err != nil {
+			return 0, err
+		}
+	}
+	if b.state == responsePassthrough {
+		return b.w.Write(p)
+	}
+	return b.buf.Write(p)
+}
+
+// Status returns the status code written by the handler, whether it was held
+// back or not.
+func (b *ResponseBodyBuffer) Status() int {
+	return b.status
+}
+
+// Body returns the held back response body along with its content type. It
+// returns false if the response is not being held back.
+func (b *ResponseBodyBuffer) Body() (body []byte, contentType string, ok bool) {
+	if b.state != responseBuffering {
+		return nil, "", false
+	}
+	return b.buf.Bytes(), b.w.Header().Get("Content-Type"), true
+}
+
+// SetBody replaces the held back response body with body. It has no effect if
+// the response is not being held back.
+func (b *ResponseBodyBuffer) SetBody(body []byte) {
+	if b.state != responseBuffering {
+		return
+	}
+	b.buf.Reset()
+	b.buf.Write(body)
+	b.w.Header().Del("Content-Length")
+}
+
+// Discard drops the held back response so that a new one can be written, and
+// stops holding back the response.
+func (b *ResponseBodyBuffer) Discard() {
+	if b.state == responseBuffering {
+		b.w.Header().Del("Content-Length")
+	}
+	b.buf.Reset()
+	b.status = 0
+	b.state = responsePassthrough
+}
+
+// Commit writes the held back status code and body to the underlying response
+// writer, and stops holding back the response. It is safe to call Commit
+// several times.
+func (b *ResponseBodyBuffer) Commit() error {
+	if b.state != responseBuffering {
+		return nil
+	}
+	b.state = responsePassthrough
+	b.w.WriteHeader(b.status)
+	if b.buf.Len() == 0 {
+		return nil
+	}
+	_, err := b.w.Write(b.buf.Bytes())
+	b.buf.Reset()
+	return err
+}
+
+// Flush commits the response and flushes the underlying response writer, if it
+// implements http.Flusher.
+func (b *ResponseBodyBuffer) Flush() {
+	b.Commit()
+	if f, ok := b.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack commits the response and hijacks the connection of the underlying
+// response writer. It returns http.ErrNotSupported if the underlying response
+// writer does not implement http.Hijacker.
+func (b *ResponseBodyBuffer) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := b.w.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	b.Commit()
+	return h.Hijack()
+}
+
+// Push initiates an HTTP/2 server push on the underlying response writer. It
+// returns http.ErrNotSupported if the underlying response writer does not
+// implement http.Pusher.
+func (b *ResponseBodyBuffer) Push(target string, opts *http.PushOptions) error {
+	p, ok := b.w.(http.Pusher)
+	if !ok {
+		return http.ErrNotSupported
+	}
+	return p.Push(target, opts)
+}
+
+// Unwrap returns the underlying response writer, as expected by
+// http.ResponseController.
+func (b *ResponseBodyBuffer) Unwrap() http.ResponseWriter {
+	return b.w
+}
+
+// inspectableContentType returns true for the JSON and URL-encoded form
+// content types.
+func inspectableContentType(ct string) bool {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	return mt == "application/json" || strings.HasSuffix(mt, "+json") || mt == "application/x-www-form-urlencoded"
+}
@@ -0,0 +1,127 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package httptrace
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseBodyBuffer(t *testing.T) {
+	t.Run("buffered", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		b := NewResponseBodyBuffer(rec, 64)
+		b.Header().Set("Content-Type", "application/json; charset=utf-8")
+		b.WriteHeader(http.StatusCreated)
+		n, err := b.Write([]byte(`{"a":`))
+		require.NoError(t, err)
+		assert.Equal(t, 5, n)
+		b.Write([]byte(`1}`))
+		assert.False(t, rec.Flushed)
+		assert.Equal(t, 0, rec.Body.Len())
+		assert.Equal(t, http.StatusCreated, b.Status())
+
+		body, ct, ok := b.Body()
+		require.True(t, ok)
+		assert.Equal(t, `{"a":1}`, string(body))
+		assert.Equal(t, "application/json; charset=utf-8", ct)
+
+		require.NoError(t, b.Commit())
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, `{"a":1}`, rec.Body.String())
+		_, _, ok = b.Body()
+		assert.False(t, ok)
+		require.NoError(t, b.Commit())
+		assert.Equal(t, `{"a":1}`, rec.Body.String())
+	})
+
+	t.Run("form", func(t *testing.T) {
+		b := NewResponseBodyBuffer(httptest.NewRecorder(), 64)
+		b.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+		b.Write([]byte(`a=1`))
+		body, _, ok := b.Body()
+		require.True(t, ok)
+		assert.Equal(t, `a=1`, string(body))
+	})
+
+	t.Run("passthrough", func(t *testing.T) {
+		for name, ct := range map[string]string{
+			"html":    "text/html",
+			"missing": "",
+		} {
+			t.Run(name, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				b := NewResponseBodyBuffer(rec, 64)
+				b.Header().Set("Content-Type", ct)
+				b.Write([]byte(`hello`))
+				_, _, ok := b.Body()
+				assert.False(t, ok)
+				assert.Equal(t, `hello`, rec.Body.String())
+				assert.Equal(t, http.StatusOK, b.Status())
+			})
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		b := NewResponseBodyBuffer(rec, 4)
+		b.Header().Set("Content-Type", "application/json")
+		b.Write([]byte(`[1,`))
+		assert.Equal(t, 0, rec.Body.Len())
+		b.Write([]byte(`2]`))
+		_, _, ok := b.Body()
+		assert.False(t, ok)
+		assert.Equal(t, `[1,2]`, rec.Body.String())
+	})
+
+	t.Run("set-body", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		b := NewResponseBodyBuffer(rec, 64)
+		b.Header().Set("Content-Type", "application/json")
+		b.Header().Set("Content-Length", "7")
+		b.Write([]byte(`{"a":1}`))
+		b.SetBody([]byte(`{"a":"<Redacted>"}`))
+		require.NoError(t, b.Commit())
+		assert.Equal(t, `{"a":"<Redacted>"}`, rec.Body.String())
+		assert.Empty(t, rec.Header().Get("Content-Length"))
+	})
+
+	t.Run("discard", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		b := NewResponseBodyBuffer(rec, 64)
+		b.Header().Set("Content-Type", "application/json")
+		b.Write([]byte(`{"a":1}`))
+		b.Discard()
+		b.Header().Set("Content-Type", "text/plain")
+		b.WriteHeader(http.StatusForbidden)
+		b.Write([]byte(`blocked`))
+		require.NoError(t, b.Commit())
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Equal(t, `blocked`, rec.Body.String())
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		b := NewResponseBodyBuffer(rec, 64)
+		b.Header().Set("Content-Type", "application/json")
+		b.Write([]byte(`[1,`))
+		b.Flush()
+		assert.True(t, rec.Flushed)
+		b.Write([]byte(`2]`))
+		assert.Equal(t, `[1,2]`, rec.Body.String())
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		b := NewResponseBodyBuffer(httptest.NewRecorder(), 64)
+		_, _, err := b.Hijack()
+		assert.ErrorIs(t, err, http.ErrNotSupported)
+		assert.ErrorIs(t, b.Push("/", nil), http.ErrNotSupported)
+	})
+}
@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package logcorrelation holds the helpers shared by the log correlation
+// integrations, which attach trace and unified service tagging information to
+// log records.
+package logcorrelation
+
+import (
+	"encoding/binary"
+	"os"
+	"strconv"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/globalconfig"
+)
+
+// Keys of the fields added to log records.
+const (
+	// KeyTraceID is the key holding the trace ID.
+	KeyTraceID = "dd.trace_id"
+	// KeySpanID is the key holding the span ID.
+	KeySpanID = "dd.span_id"
+	// KeyService is the key holding the service name.
+	KeyService = "dd.service"
+	// KeyEnv is the key holding the environment.
+	KeyEnv = "dd.env"
+	// KeyVersion is the key holding the service version.
+	KeyVersion = "dd.version"
+)
+
+// TraceID128 returns the hex-encoded 128-bit trace ID of ctx. It returns false
+// if ctx does not carry a 128-bit trace ID, i.e. if its upper 64 bits are all
+// zero, in which case the 64-bit TraceID should be used instead.
+func TraceID128(ctx ddtrace.SpanContext) (string, bool) {
+	w3c, ok := ctx.(ddtrace.SpanContextW3C)
+	if !ok {
+		return "", false
+	}
+	id := w3c.TraceID128Bytes()
+	if binary.BigEndian.Uint64(id[:8]) == 0 {
+		return "", false
+	}
+	return w3c.TraceID128(), true
+}
+
+// TraceID returns the trace ID of ctx as a string. When use128 is true and ctx
+// carries a 128-bit trace ID, it is returned hex-encoded. Otherwise the lower
+// 64 bits are returned in decimal form, which is what the Datadog backend
+// expects for 64-bit trace IDs.
+func TraceID(ctx ddtrace.SpanContext, use128 bool) string {
+	if use128 {
+		if id, ok := TraceID128(ctx); ok {
+			return id
+		}
+	}
+	return strconv.FormatUint(ctx.TraceID(), 10)
+}
+
+// SpanID returns the span ID of ctx in decimal form.
+func SpanID(ctx ddtrace.SpanContext) string {
+	return strconv.FormatUint(ctx.SpanID(), 10)
+}
+
+// ServiceTags holds the unified service tagging values attached to log
+// records. Empty values are not attached.
+type ServiceTags struct {
+	// Service overrides the service name. If empty, the service name
+	// configured on the tracer is used, see ServiceName.
+	Service string
+	Env     string
+	Version string
+}
+
+// DefaultServiceTags returns the unified service tagging values configured
+// through the DD_ENV and DD_VERSION environment variables.
+func DefaultServiceTags() ServiceTags {
+	return ServiceTags{
+		Env:     os.Getenv("DD_ENV"),
+		Version: os.Getenv("DD_VERSION"),
+	}
+}
+
+// ServiceName returns the service name to attach to log records: t.Service if
+// set, otherwise the service name configured on the tracer, falling back to
+// the DD_SERVICE environment variable.
+func (t ServiceTags) ServiceName() string {
+	if t.Service != "" {
+		return t.Service
+	}
+	if svc := globalconfig.ServiceName(); svc != "" {
+		return svc
+	}
+	return os.Getenv("DD_SERVICE")
+}
+
+// Tag is a key/value pair attached to log records.
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// Tags returns the unified service tagging values to attach to log records,
+// keyed by KeyService, KeyEnv and KeyVersion. Empty values are omitted.
+func (t ServiceTags) Tags() []Tag {
+	tags := make([]Tag, 0, 3)
+	if svc := t.ServiceName(); svc != "" {
+		tags = append(tags, Tag{Key: KeyService, Value: svc})
+	}
+	if t.Env != "" {
+		tags = append(tags, Tag{Key: KeyEnv, Value: t.Env})
+	}
+	if t.Version != "" {
+		tags = append(tags, Tag{Key: KeyVersion, Value: t.Version})
+	}
+	return tags
+}
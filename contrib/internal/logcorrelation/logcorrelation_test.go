@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package logcorrelation
+
+import (
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/globalconfig"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type spanContext struct {
+	traceID [16]byte
+}
+
+func (c spanContext) SpanID() uint64                            { return 2 }
+func (c spanContext) TraceID() uint64                           { return 1 }
+func (c spanContext) ForeachBaggageItem(func(k, v string) bool) {}
+func (c spanContext) TraceID128() string                        { return "00000000000000030000000000000001" }
+func (c spanContext) TraceID128Bytes() [16]byte                 { return c.traceID }
+
+func TestTraceID(t *testing.T) {
+	ctx128 := spanContext{traceID: [16]byte{7: 3, 15: 1}}
+	ctx64 := spanContext{traceID: [16]byte{15: 1}}
+
+	assert.Equal(t, "00000000000000030000000000000001", TraceID(ctx128, true))
+	assert.Equal(t, "1", TraceID(ctx128, false))
+	assert.Equal(t, "1", TraceID(ctx64, true))
+	assert.Equal(t, "2", SpanID(ctx64))
+
+	_, ok := TraceID128(ctx64)
+	assert.False(t, ok)
+}
+
+func TestServiceTags(t *testing.T) {
+	t.Setenv("DD_SERVICE", "env-service")
+	t.Setenv("DD_ENV", "prod")
+	t.Setenv("DD_VERSION", "1.0")
+
+	tags := DefaultServiceTags()
+	assert.Equal(t, "prod", tags.Env)
+	assert.Equal(t, "1.0", tags.Version)
+	assert.Equal(t, "env-service", tags.ServiceName())
+
+	globalconfig.SetServiceName("tracer-service")
+	defer globalconfig.SetServiceName("")
+	assert.Equal(t, "tracer-service", tags.ServiceName())
+
+	tags.Service = "explicit"
+	assert.Equal(t, "explicit", tags.ServiceName())
+	assert.Equal(t, []Tag{{KeyService, "explicit"}, {KeyEnv, "prod"}, {KeyVersion, "1.0"}}, tags.Tags())
+
+	tags = ServiceTags{Service: "explicit"}
+	assert.Equal(t, []Tag{{KeyService, "explicit"}}, tags.Tags())
+}
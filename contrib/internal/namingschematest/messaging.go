@@ -36,3 +36,38 @@ func NewKafkaTest(genSpans GenSpansFn) func(t *testing.T) {
 		t.Run("SpanName", NewSpanNameTest(genSpans, assertOpV0, assertOpV1))
 	}
 }
+
+// NewRabbitMQTest creates a new test for RabbitMQ naming schema.
+func NewRabbitMQTest(genSpans GenSpansFn) func(t *testing.T) {
+	return newMessagingTest(genSpans, "rabbitmq", []string{"amqp.publish", "amqp.consume"}, []string{"rabbitmq.send", "rabbitmq.process"})
+}
+
+// NewNATSTest creates a new test for NATS naming schema.
+func NewNATSTest(genSpans GenSpansFn) func(t *testing.T) {
+	return newMessagingTest(genSpans, "nats", []string{"nats.publish", "nats.consume"}, []string{"nats.send", "nats.process"})
+}
+
+// newMessagingTest creates a new naming schema test for the integrations
+// generating a producer and a consumer span, in this order, and using the
+// given default service name.
+func newMessagingTest(genSpans GenSpansFn, defaultServiceName string, opV0, opV1 []string) func(t *testing.T) {
+	return func(t *testing.T) {
+		assertOpV0 := func(t *testing.T, spans []mocktracer.Span) {
+			require.Len(t, spans, 2)
+			assert.Equal(t, opV0[0], spans[0].OperationName())
+			assert.Equal(t, opV0[1], spans[1].OperationName())
+		}
+		assertOpV1 := func(t *testing.T, spans []mocktracer.Span) {
+			require.Len(t, spans, 2)
+			assert.Equal(t, opV1[0], spans[0].OperationName())
+			assert.Equal(t, opV1[1], spans[1].OperationName())
+		}
+		wantServiceNameV0 := ServiceNameAssertions{
+			WithDefaults:             []string{defaultServiceName, defaultServiceName},
+			WithDDService:            []string{defaultServiceName, TestDDService},
+			WithDDServiceAndOverride: []string{TestServiceOverride, TestServiceOverride},
+		}
+		t.Run("ServiceName", NewServiceNameTest(genSpans, wantServiceNameV0))
+		t.Run("SpanName", NewSpanNameTest(genSpans, assertOpV0, assertOpV1))
+	}
+}
@@ -0,0 +1,233 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package pgx
+
+import (
+	"context"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/sqlsec"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Conn wraps a *pgx.Conn so that, when AppSec RASP is enabled, the SQL of its
+// exec, query, batch and prepare operations, and of the ones of the
+// transactions it begins, is checked for SQL injections before being sent.
+// Unsafe operations are aborted with an *events.BlockingSecurityEvent error,
+// and the request is blocked by the AppSec middleware monitoring it. The
+// tracer of the connections returned by Connect and NewPool can't abort the
+// operations, so RASP protection requires using WrapConn or WrapPool.
+// CopyFrom operations are not checked.
+type Conn struct {
+	*pgx.Conn
+}
+
+// WrapConn returns conn protected by AppSec RASP.
+func WrapConn(conn *pgx.Conn) *Conn {
+	return &Conn{Conn: conn}
+}
+
+// Exec runs pgx.Conn.Exec once its SQL is checked.
+func (c *Conn) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if err := checkQuerySecurity(ctx, sql); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return c.Conn.Exec(ctx, sql, args...)
+}
+
+// Query runs pgx.Conn.Query once its SQL is checked.
+func (c *Conn) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if err := checkQuerySecurity(ctx, sql); err != nil {
+		return nil, err
+	}
+	return c.Conn.Query(ctx, sql, args...)
+}
+
+// QueryRow runs pgx.Conn.QueryRow once its SQL is checked.
+func (c *Conn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if err := checkQuerySecurity(ctx, sql); err != nil {
+		return errRow{err: err}
+	}
+	return c.Conn.QueryRow(ctx, sql, args...)
+}
+
+// SendBatch runs pgx.Conn.SendBatch once the SQL of all its queries is checked.
+func (c *Conn) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	if err := checkBatchSecurity(ctx, b); err != nil {
+		return errBatchResults{err: err}
+	}
+	return c.Conn.SendBatch(ctx, b)
+}
+
+// Prepare runs pgx.Conn.Prepare once its SQL is checked.
+func (c *Conn) Prepare(ctx context.Context, name, sql string) (*pgconn.StatementDescription, error) {
+	if err := checkQuerySecurity(ctx, sql); err != nil {
+		return nil, err
+	}
+	return c.Conn.Prepare(ctx, name, sql)
+}
+
+// Begin runs pgx.Conn.Begin and returns the protected transaction.
+func (c *Conn) Begin(ctx context.Context) (pgx.Tx, error) {
+	return wrapTx(c.Conn.Begin(ctx))
+}
+
+// BeginTx runs pgx.Conn.BeginTx and returns the protected transaction.
+func (c *Conn) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	return wrapTx(c.Conn.BeginTx(ctx, txOptions))
+}
+
+// Pool wraps a *pgxpool.Pool so that, when AppSec RASP is enabled, the SQL of
+// its exec, query and batch operations, and of the ones of the transactions
+// it begins, is checked for SQL injections before being sent. See Conn. The
+// connections returned by Acquire are not protected.
+type Pool struct {
+	*pgxpool.Pool
+}
+
+// WrapPool returns pool protected by AppSec RASP.
+func WrapPool(pool *pgxpool.Pool) *Pool {
+	return &Pool{Pool: pool}
+}
+
+// Exec runs pgxpool.Pool.Exec once its SQL is checked.
+func (p *Pool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if err := checkQuerySecurity(ctx, sql); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return p.Pool.Exec(ctx, sql, args...)
+}
+
+// Query runs pgxpool.Pool.Query once its SQL is checked.
+func (p *Pool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if err := checkQuerySecurity(ctx, sql); err != nil {
+		return nil, err
+	}
+	return p.Pool.Query(ctx, sql, args...)
+}
+
+// QueryRow runs pgxpool.Pool.QueryRow once its SQL is checked.
+func (p *Pool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if err := checkQuerySecurity(ctx, sql); err != nil {
+		return errRow{err: err}
+	}
+	return p.Pool.QueryRow(ctx, sql, args...)
+}
+
+// SendBatch runs pgxpool.Pool.SendBatch once the SQL of all its queries is checked.
+func (p *Pool) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	if err := checkBatchSecurity(ctx, b); err != nil {
+		return errBatchResults{err: err}
+	}
+	return p.Pool.SendBatch(ctx, b)
+}
+
+// Begin runs pgxpool.Pool.Begin and returns the protected transaction.
+func (p *Pool) Begin(ctx context.Context) (pgx.Tx, error) {
+	return wrapTx(p.Pool.Begin(ctx))
+}
+
+// BeginTx runs pgxpool.Pool.BeginTx and returns the protected transaction.
+func (p *Pool) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	return wrapTx(p.Pool.BeginTx(ctx, txOptions))
+}
+
+// tx is a pgx.Tx whose operations are protected by AppSec RASP.
+type tx struct {
+	pgx.Tx
+}
+
+func wrapTx(t pgx.Tx, err error) (pgx.Tx, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &tx{Tx: t}, nil
+}
+
+func (t *tx) Begin(ctx context.Context) (pgx.Tx, error) {
+	return wrapTx(t.Tx.Begin(ctx))
+}
+
+func (t *tx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if err := checkQuerySecurity(ctx, sql); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return t.Tx.Exec(ctx, sql, args...)
+}
+
+func (t *tx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if err := checkQuerySecurity(ctx, sql); err != nil {
+		return nil, err
+	}
+	return t.Tx.Query(ctx, sql, args...)
+}
+
+func (t *tx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if err := checkQuerySecurity(ctx, sql); err != nil {
+		return errRow{err: err}
+	}
+	return t.Tx.QueryRow(ctx, sql, args...)
+}
+
+func (t *tx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	if err := checkBatchSecurity(ctx, b); err != nil {
+		return errBatchResults{err: err}
+	}
+	return t.Tx.SendBatch(ctx, b)
+}
+
+func (t *tx) Prepare(ctx context.Context, name, sql string) (*pgconn.StatementDescription, error) {
+	if err := checkQuerySecurity(ctx, sql); err != nil {
+		return nil, err
+	}
+	return t.Tx.Prepare(ctx, name, sql)
+}
+
+// checkQuerySecurity runs ASM RASP SQLi checks on the query to verify if it can safely be run. If it's unsafe to
+// run, an *events.BlockingSecurityEvent is returned, which aborts the operation before pgx sends it.
+func checkQuerySecurity(ctx context.Context, query string) error {
+	if !appsec.RASPEnabled() {
+		return nil
+	}
+	return sqlsec.ProtectSQLOperation(ctx, query, ext.DBSystemPostgreSQL)
+}
+
+// checkBatchSecurity runs checkQuerySecurity on the queries queued in b.
+func checkBatchSecurity(ctx context.Context, b *pgx.Batch) error {
+	if !appsec.RASPEnabled() {
+		return nil
+	}
+	for _, q := range batchQueries(b) {
+		if err := sqlsec.ProtectSQLOperation(ctx, q, ext.DBSystemPostgreSQL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// errRow is the pgx.Row of a blocked QueryRow call.
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...any) error { return r.err }
+
+// errBatchResults is the pgx.BatchResults of a blocked SendBatch call.
+type errBatchResults struct {
+	err error
+}
+
+func (r errBatchResults) Exec() (pgconn.CommandTag, error) { return pgconn.CommandTag{}, r.err }
+
+func (r errBatchResults) Query() (pgx.Rows, error) { return nil, r.err }
+
+func (r errBatchResults) QueryRow() pgx.Row { return errRow{err: r.err} }
+
+func (r errBatchResults) Close() error { return r.err }
@@ -0,0 +1,302 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package pgx
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec/events"
+	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppSec(t *testing.T) {
+	skipIntegrationTest(t)
+	t.Setenv("DD_APPSEC_RULES", "../../../internal/appsec/testdata/rasp.json")
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.RASPEnabled() {
+		t.Skip("RASP needs to be enabled for this test")
+	}
+
+	c, err := Connect(context.Background(), postgresDSN)
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+	conn := WrapConn(c)
+
+	var queryErr error
+	mux := httptrace.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, queryErr = conn.Exec(r.Context(), r.URL.Query().Get("query"))
+		if events.IsSecurityError(queryErr) {
+			return
+		}
+		w.Write([]byte("Hello World!\n"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for name, tc := range map[string]struct {
+		query   string
+		blocked bool
+	}{
+		"no-error": {
+			query: "SELECT 1",
+		},
+		"injection": {
+			query:   "SELECT * FROM users WHERE user='' UNION ALL SELECT NULL;version()--",
+			blocked: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			res, err := srv.Client().Get(srv.URL + "/?query=" + url.QueryEscape(tc.query))
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			if tc.blocked {
+				require.Equal(t, 403, res.StatusCode)
+				require.True(t, events.IsSecurityError(queryErr))
+			} else {
+				require.Equal(t, 200, res.StatusCode)
+				require.NoError(t, queryErr)
+			}
+		})
+	}
+}
+
+// TestAppSecBlocking checks that the blocked operations are not sent to the server, and that the connection can
+// still be used afterwards, using a fake server.
+func TestAppSecBlocking(t *testing.T) {
+	t.Setenv("DD_APPSEC_RULES", "../../../internal/appsec/testdata/rasp.json")
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.RASPEnabled() {
+		t.Skip("RASP needs to be enabled for this test")
+	}
+
+	srv := &fakeServer{}
+	connConfig, err := pgx.ParseConfig("postgres://postgres@127.0.0.1:5432/postgres?sslmode=disable")
+	require.NoError(t, err)
+	connConfig.DialFunc = srv.dial
+	connConfig.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+	c, err := ConnectConfig(context.Background(), connConfig)
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+	conn := WrapConn(c)
+
+	poolConfig, err := pgxpool.ParseConfig("")
+	require.NoError(t, err)
+	poolConfig.ConnConfig = connConfig
+	p, err := NewPoolWithConfig(context.Background(), poolConfig)
+	require.NoError(t, err)
+	defer p.Close()
+	pool := WrapPool(p)
+
+	operations := map[string]func(ctx context.Context, query string) error{
+		"exec": func(ctx context.Context, query string) error {
+			_, err := conn.Exec(ctx, query)
+			return err
+		},
+		"query": func(ctx context.Context, query string) error {
+			rows, err := conn.Query(ctx, query)
+			if err != nil {
+				return err
+			}
+			rows.Close()
+			return rows.Err()
+		},
+		"query-row": func(ctx context.Context, query string) error {
+			if err := conn.QueryRow(ctx, query).Scan(); !errors.Is(err, pgx.ErrNoRows) {
+				return err
+			}
+			return nil
+		},
+		"batch": func(ctx context.Context, query string) error {
+			b := &pgx.Batch{}
+			b.Queue("SELECT 1")
+			b.Queue(query)
+			return conn.SendBatch(ctx, b).Close()
+		},
+		"tx": func(ctx context.Context, query string) error {
+			tx, err := conn.Begin(ctx)
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(ctx)
+			_, err = tx.Exec(ctx, query)
+			return err
+		},
+		"pool": func(ctx context.Context, query string) error {
+			_, err := pool.Exec(ctx, query)
+			return err
+		},
+		"pool/batch": func(ctx context.Context, query string) error {
+			b := &pgx.Batch{}
+			b.Queue(query)
+			return pool.SendBatch(ctx, b).Close()
+		},
+		"pool/tx": func(ctx context.Context, query string) error {
+			tx, err := pool.Begin(ctx)
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(ctx)
+			_, err = tx.Exec(ctx, query)
+			return err
+		},
+	}
+
+	var opErr error
+	var operation func(ctx context.Context, query string) error
+	mux := httptrace.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		opErr = operation(r.Context(), r.URL.Query().Get("query"))
+		if events.IsSecurityError(opErr) {
+			return
+		}
+		w.Write([]byte("Hello World!\n"))
+	})
+	httpSrv := httptest.NewServer(mux)
+	defer httpSrv.Close()
+
+	for name, op := range operations {
+		for _, tc := range []struct {
+			name    string
+			query   string
+			blocked bool
+		}{
+			{
+				name:    "injection",
+				query:   "SELECT * FROM users WHERE user='' UNION ALL SELECT NULL;version()--",
+				blocked: true,
+			},
+			{
+				name:  "no-error",
+				query: "SELECT 2",
+			},
+		} {
+			t.Run(name+"/"+tc.name, func(t *testing.T) {
+				operation = op
+				srv.reset()
+
+				res, err := httpSrv.Client().Get(httpSrv.URL + "/?query=" + url.QueryEscape(tc.query))
+				require.NoError(t, err)
+				defer res.Body.Close()
+
+				sent := strings.Join(srv.queries(), "\n")
+				if tc.blocked {
+					require.Equal(t, 403, res.StatusCode)
+					require.True(t, events.IsSecurityError(opErr))
+					require.NotContains(t, sent, tc.query)
+					return
+				}
+				require.Equal(t, 200, res.StatusCode)
+				require.NoError(t, opErr)
+				require.Contains(t, sent, tc.query)
+			})
+		}
+	}
+}
+
+// fakeServer is a PostgreSQL server accepting any connection and answering the queries with empty results,
+// using the simple or the extended query protocol. It records the SQL of the queries and statements it receives.
+type fakeServer struct {
+	mu   sync.Mutex
+	recv []string
+}
+
+func (s *fakeServer) dial(_ context.Context, _, _ string) (net.Conn, error) {
+	client, server := net.Pipe()
+	go s.serve(server)
+	return client, nil
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+	backend := pgproto3.NewBackend(conn, conn)
+	if _, err := backend.ReceiveStartupMessage(); err != nil {
+		return
+	}
+	backend.Send(&pgproto3.AuthenticationOk{})
+	// Required by pgx to run simple protocol queries with arguments
+	backend.Send(&pgproto3.ParameterStatus{Name: "standard_conforming_strings", Value: "on"})
+	backend.Send(&pgproto3.ParameterStatus{Name: "client_encoding", Value: "UTF8"})
+	backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+	if err := backend.Flush(); err != nil {
+		return
+	}
+	for {
+		msg, err := backend.Receive()
+		if err != nil {
+			return
+		}
+		switch msg := msg.(type) {
+		case *pgproto3.Query:
+			s.mu.Lock()
+			s.recv = append(s.recv, msg.String)
+			s.mu.Unlock()
+			for range strings.Split(msg.String, ";") {
+				backend.Send(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 0")})
+			}
+			backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+			if err := backend.Flush(); err != nil {
+				return
+			}
+		case *pgproto3.Parse:
+			s.mu.Lock()
+			s.recv = append(s.recv, msg.Query)
+			s.mu.Unlock()
+			backend.Send(&pgproto3.ParseComplete{})
+		case *pgproto3.Describe:
+			if msg.ObjectType == 'S' {
+				backend.Send(&pgproto3.ParameterDescription{})
+			}
+			backend.Send(&pgproto3.NoData{})
+		case *pgproto3.Bind:
+			backend.Send(&pgproto3.BindComplete{})
+		case *pgproto3.Execute:
+			backend.Send(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 0")})
+		case *pgproto3.Close:
+			backend.Send(&pgproto3.CloseComplete{})
+		case *pgproto3.Sync:
+			backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+			if err := backend.Flush(); err != nil {
+				return
+			}
+		case *pgproto3.Terminate:
+			return
+		}
+	}
+}
+
+func (s *fakeServer) queries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.recv
+}
+
+func (s *fakeServer) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recv = nil
+}
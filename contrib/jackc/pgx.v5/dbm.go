@@ -0,0 +1,168 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package pgx
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgproto3"
+)
+
+const keyDBMTraceInjected = "_dd.dbm_trace_injected"
+
+// commentWriter prepends the SQL comments of the operation in progress to the queries written by a
+// connection frontend. pgx doesn't allow its tracers to change the SQL of the traced operations, and
+// the connections returned by Connect and NewPool are pgx types whose methods can't be wrapped, so
+// the comments are added to the Query and Parse messages sent to the server instead, which are
+// decoded and encoded again with pgproto3. Doing so also keeps the statement and description caches
+// of pgx keyed by the original SQL, whereas rewriting the SQL of the queries would make every query
+// with a new trace context miss them.
+type commentWriter struct {
+	w io.Writer
+
+	mu sync.Mutex
+	// comment is prepended to the simple queries and the unnamed statements, which are only used
+	// by the operation in progress.
+	comment string
+	// serviceComment is prepended to the named statements, which outlive the operation in progress
+	// since pgx caches them, and can't hold its trace context.
+	serviceComment string
+	// full reports whether comment holds the trace context.
+	full bool
+	// injected reports whether the trace context was sent to the server.
+	injected bool
+	buf      []byte
+	segments []segment
+}
+
+// segment locates a message written to the commentWriter in the bytes sent in its place, where a
+// comment of extra bytes may be inserted at the offset at of the message.
+type segment struct {
+	in, out, size int
+	at, extra     int
+}
+
+// set makes the writer prepend the given comments to the queries until reset is called.
+func (cw *commentWriter) set(comment, serviceComment string, full bool) {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	cw.comment = comment
+	cw.serviceComment = serviceComment
+	cw.full = full
+	cw.injected = false
+}
+
+// reset stops prepending comments to the queries, and reports whether the trace context of the
+// finished operation was sent to the server.
+func (cw *commentWriter) reset() (injected bool) {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	injected = cw.injected
+	cw.comment = ""
+	cw.serviceComment = ""
+	cw.full = false
+	cw.injected = false
+	return injected
+}
+
+// Write implements io.Writer. The pgproto3 frontend writes whole messages at once. When the write
+// fails, the count of the bytes of p that were sent is returned, so that the frontend knows whether
+// the write can be retried.
+func (cw *commentWriter) Write(p []byte) (int, error) {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	if cw.comment == "" && cw.serviceComment == "" {
+		return cw.w.Write(p)
+	}
+	cw.buf, cw.segments = cw.appendMessages(cw.buf[:0], cw.segments[:0], p)
+	n, err := cw.w.Write(cw.buf)
+	if err != nil {
+		return cw.written(n), err
+	}
+	return len(p), nil
+}
+
+// written returns the count of the bytes of the messages last written that are in the first n
+// bytes sent in their place. The comments inserted in the messages don't count.
+func (cw *commentWriter) written(n int) int {
+	for _, s := range cw.segments {
+		k := n - s.out
+		switch {
+		case k >= s.size+s.extra:
+			continue
+		case k <= s.at:
+			return s.in + k
+		case k <= s.at+s.extra:
+			return s.in + s.at
+		default:
+			return s.in + k - s.extra
+		}
+	}
+	if len(cw.segments) == 0 {
+		return 0
+	}
+	last := cw.segments[len(cw.segments)-1]
+	return last.in + last.size
+}
+
+// appendMessages appends the frontend messages in p to dst, prepending the comments to the queries
+// of the Query and Parse messages, and appends their segments to segs. Anything that can't be
+// decoded is appended as is.
+func (cw *commentWriter) appendMessages(dst []byte, segs []segment, p []byte) ([]byte, []segment) {
+	in := 0
+	for len(p)-in >= 5 {
+		size := int(binary.BigEndian.Uint32(p[in+1:in+5])) + 1
+		if size < 5 || size > len(p)-in {
+			break
+		}
+		msg := p[in : in+size]
+		seg := segment{in: in, out: len(dst), size: size}
+		in += size
+		switch msg[0] {
+		case 'Q':
+			var q pgproto3.Query
+			if cw.comment == "" || q.Decode(msg[5:]) != nil {
+				break
+			}
+			q.String = cw.comment + " " + q.String
+			if enc, err := q.Encode(dst); err == nil {
+				dst = enc
+				seg.at, seg.extra = 5, len(cw.comment)+1
+				cw.injected = cw.injected || cw.full
+			}
+		case 'P':
+			var parse pgproto3.Parse
+			if parse.Decode(msg[5:]) != nil {
+				break
+			}
+			// Named statements are cached, and only get the service comment.
+			comment := cw.comment
+			if parse.Name != "" {
+				comment = cw.serviceComment
+			}
+			if comment == "" {
+				break
+			}
+			parse.Query = comment + " " + parse.Query
+			if enc, err := parse.Encode(dst); err == nil {
+				dst = enc
+				seg.at, seg.extra = 5+len(parse.Name)+1, len(comment)+1
+				cw.injected = cw.injected || (cw.full && parse.Name == "")
+			}
+		}
+		if seg.extra == 0 {
+			dst = append(dst, msg...)
+		}
+		segs = append(segs, seg)
+	}
+	if in < len(p) {
+		segs = append(segs, segment{in: in, out: len(dst), size: len(p) - in})
+		dst = append(dst, p[in:]...)
+	}
+	return dst, segs
+}
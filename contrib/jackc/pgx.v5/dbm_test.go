@@ -0,0 +1,166 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package pgx
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommentWriter(t *testing.T) {
+	const (
+		comment        = "/*dddbs='postgres.db',traceparent='00-0000000000000000000000000000002a-000000000000002a-00'*/"
+		serviceComment = "/*dddbs='postgres.db'*/"
+	)
+	encode := func(t *testing.T, msgs ...pgproto3.FrontendMessage) []byte {
+		t.Helper()
+		var p []byte
+		for _, msg := range msgs {
+			var err error
+			p, err = msg.Encode(p)
+			require.NoError(t, err)
+		}
+		return p
+	}
+	// queries returns the queries of the Query and Parse messages in p, and the types of all messages.
+	queries := func(t *testing.T, p []byte) (queries []string, types string) {
+		t.Helper()
+		backend := pgproto3.NewBackend(bytes.NewReader(p), nil)
+		for i := 0; i < 5; i++ {
+			msg, err := backend.Receive()
+			require.NoError(t, err)
+			switch msg := msg.(type) {
+			case *pgproto3.Query:
+				queries = append(queries, msg.String)
+				types += "Q"
+			case *pgproto3.Parse:
+				queries = append(queries, msg.Name+":"+msg.Query)
+				assert.Equal(t, []uint32{23}, msg.ParameterOIDs)
+				types += "P"
+			case *pgproto3.Bind:
+				assert.Equal(t, "stmt", msg.PreparedStatement)
+				types += "B"
+			case *pgproto3.Sync:
+				types += "S"
+			}
+		}
+		return queries, types
+	}
+	p := encode(t,
+		&pgproto3.Query{String: "SELECT 1"},
+		&pgproto3.Parse{Query: "SELECT $1::int", ParameterOIDs: []uint32{23}},
+		&pgproto3.Parse{Name: "stmt", Query: "SELECT $1::int", ParameterOIDs: []uint32{23}},
+		&pgproto3.Bind{PreparedStatement: "stmt"},
+		&pgproto3.Sync{},
+	)
+
+	t.Run("full", func(t *testing.T) {
+		var buf bytes.Buffer
+		cw := &commentWriter{w: &buf}
+		cw.set(comment, serviceComment, true)
+		n, err := cw.Write(p)
+		require.NoError(t, err)
+		assert.Equal(t, len(p), n)
+		assert.True(t, cw.reset())
+
+		qs, types := queries(t, buf.Bytes())
+		assert.Equal(t, "QPPBS", types)
+		assert.Equal(t, []string{
+			comment + " SELECT 1",
+			":" + comment + " SELECT $1::int",
+			"stmt:" + serviceComment + " SELECT $1::int",
+		}, qs)
+	})
+
+	t.Run("named-statement", func(t *testing.T) {
+		var buf bytes.Buffer
+		cw := &commentWriter{w: &buf}
+		cw.set(comment, serviceComment, true)
+		_, err := cw.Write(encode(t, &pgproto3.Parse{Name: "stmt", Query: "SELECT 1"}))
+		require.NoError(t, err)
+		assert.False(t, cw.reset())
+		assert.Contains(t, buf.String(), serviceComment+" SELECT 1")
+	})
+
+	t.Run("service", func(t *testing.T) {
+		var buf bytes.Buffer
+		cw := &commentWriter{w: &buf}
+		cw.set(serviceComment, serviceComment, false)
+		_, err := cw.Write(p)
+		require.NoError(t, err)
+		assert.False(t, cw.reset())
+
+		qs, _ := queries(t, buf.Bytes())
+		assert.Equal(t, []string{
+			serviceComment + " SELECT 1",
+			":" + serviceComment + " SELECT $1::int",
+			"stmt:" + serviceComment + " SELECT $1::int",
+		}, qs)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		var buf bytes.Buffer
+		cw := &commentWriter{w: &buf}
+		cw.set(comment, serviceComment, true)
+		cw.reset()
+		_, err := cw.Write(p)
+		require.NoError(t, err)
+		assert.Equal(t, p, buf.Bytes())
+	})
+
+	t.Run("partial-write", func(t *testing.T) {
+		query := encode(t, &pgproto3.Query{String: "SELECT 1"})
+		p := append(query, encode(t, &pgproto3.Sync{})...)
+		sent := len(query) + len(comment) + 1
+		for _, tc := range []struct {
+			name        string
+			sent, count int
+		}{
+			{name: "nothing", sent: 0, count: 0},
+			{name: "header", sent: 3, count: 3},
+			{name: "comment", sent: 5 + 10, count: 5},
+			{name: "query", sent: sent - 2, count: len(query) - 2},
+			{name: "message", sent: sent, count: len(query)},
+			{name: "next-message", sent: sent + 2, count: len(query) + 2},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				w := &failingWriter{n: tc.sent}
+				cw := &commentWriter{w: w}
+				cw.set(comment, serviceComment, true)
+				n, err := cw.Write(p)
+				require.Error(t, err)
+				assert.Equal(t, tc.count, n)
+				assert.Equal(t, tc.sent, w.buf.Len())
+			})
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		var buf bytes.Buffer
+		cw := &commentWriter{w: &buf}
+		cw.set(comment, serviceComment, true)
+		_, err := cw.Write(p[:7])
+		require.NoError(t, err)
+		assert.Equal(t, p[:7], buf.Bytes())
+	})
+}
+
+// failingWriter writes n bytes at most, and fails.
+type failingWriter struct {
+	n   int
+	buf bytes.Buffer
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	n := min(w.n, len(p))
+	w.buf.Write(p[:n])
+	return n, errors.New("broken pipe")
+}
@@ -5,28 +5,56 @@
 
 package pgx
 
-import "gopkg.in/DataDog/dd-trace-go.v1/internal/namingschema"
+import (
+	"os"
+	"sync"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/namingschema"
+)
 
 type config struct {
-	serviceName   string
-	traceQuery    bool
-	traceBatch    bool
-	traceCopyFrom bool
-	tracePrepare  bool
-	traceConnect  bool
+	serviceName        string
+	traceQuery         bool
+	traceBatch         bool
+	traceCopyFrom      bool
+	tracePrepare       bool
+	traceConnect       bool
+	dbmPropagationMode tracer.DBMPropagationMode
+	// cacheStatementWarning logs once that the full DBM propagation mode doesn't apply to
+	// the statements cached in the default pgx.QueryExecModeCacheStatement mode.
+	cacheStatementWarning sync.Once
 }
 
 func defaultConfig() *config {
+	mode := os.Getenv("DD_DBM_PROPAGATION_MODE")
+	if mode == "" {
+		mode = os.Getenv("DD_TRACE_SQL_COMMENT_INJECTION_MODE")
+	}
 	return &config{
-		serviceName:   namingschema.ServiceName(defaultServiceName),
-		traceQuery:    true,
-		traceBatch:    true,
-		traceCopyFrom: true,
-		tracePrepare:  true,
-		traceConnect:  true,
+		serviceName:        namingschema.ServiceName(defaultServiceName),
+		traceQuery:         true,
+		traceBatch:         true,
+		traceCopyFrom:      true,
+		tracePrepare:       true,
+		traceConnect:       true,
+		dbmPropagationMode: tracer.DBMPropagationMode(mode),
 	}
 }
 
+func newConfig(opts ...Option) *config {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// dbmPropagationEnabled reports whether SQL comments are injected in the traced queries.
+func (c *config) dbmPropagationEnabled() bool {
+	return c.dbmPropagationMode == tracer.DBMPropagationModeService || c.dbmPropagationMode == tracer.DBMPropagationModeFull
+}
+
 type Option func(*config)
 
 // WithServiceName sets the service name to use for all spans.
@@ -81,3 +109,24 @@ func WithTraceConnect(enabled bool) Option {
 		c.traceConnect = enabled
 	}
 }
+
+// WithDBMPropagation enables injection of tags as sql comments on traced queries.
+// This includes dynamic values like span id, trace id and the sampled flag which can make queries
+// unique for some cache implementations. Use DBMPropagationModeService if this is a concern.
+//
+// The full mode only applies to the statements parsed for a single operation: prepared statements,
+// including the ones cached by the default pgx.QueryExecModeCacheStatement mode, only get the service
+// tags. As a result, the queries and batches run in the default mode never carry the trace context
+// (traceparent), which requires an uncached mode such as pgx.QueryExecModeDescribeExec or
+// pgx.QueryExecModeSimpleProtocol, and a warning is logged when connecting in the default mode with
+// the full mode. Note that pgx always runs the Exec calls without arguments with the simple protocol.
+// CopyFrom operations and batches sent in pgx.QueryExecModeExec mode are sent without comments.
+//
+// Note that enabling sql comment propagation results in potentially confidential data (service names)
+// being stored in the databases which can then be accessed by other 3rd parties that have been granted
+// access to the database.
+func WithDBMPropagation(mode tracer.DBMPropagationMode) Option {
+	return func(c *config) {
+		c.dbmPropagationMode = mode
+	}
+}
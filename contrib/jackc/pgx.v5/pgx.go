@@ -3,14 +3,23 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2022 Datadog, Inc.
 
+// Package pgx provides functions to trace the jackc/pgx package (https://github.com/jackc/pgx).
+// With DBM propagation in full mode, the trace context is only sent along with the statements that are
+// not cached by pgx, so not in the default pgx.QueryExecModeCacheStatement mode (see WithDBMPropagation).
+// AppSec RASP protection against SQL injections requires wrapping the connections and pools with WrapConn
+// and WrapPool.
 package pgx
 
 import (
 	"context"
+	"io"
+
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgproto3"
 )
 
 const (
@@ -39,6 +48,34 @@ func ConnectConfig(ctx context.Context, connConfig *pgx.ConnConfig, opts ...Opti
 	// as pgx takes ownership of the config. QueryTracer traces
 	// may work, but none of the others will, as they're set in
 	// unexported fields in the config in the pgx.connect function.
-	connConfig.Tracer = newPgxTracer(opts...)
+	// The config is copied so that it can be reused without wrapping
+	// its frontend more than once.
+	connConfig = connConfig.Copy()
+	configureConn(connConfig, newConfig(opts...))
 	return pgx.ConnectConfig(ctx, connConfig)
 }
+
+// configureConn sets a new tracer in connConfig. When DBM propagation is enabled, the frontend of
+// the connection is wrapped to prepend the SQL comments to the queries it sends, so each connection
+// needs its own tracer.
+func configureConn(connConfig *pgx.ConnConfig, cfg *config) {
+	t := &pgxTracer{cfg: cfg}
+	connConfig.Tracer = t
+	if !cfg.dbmPropagationEnabled() {
+		return
+	}
+	if cfg.dbmPropagationMode == tracer.DBMPropagationModeFull && connConfig.DefaultQueryExecMode == pgx.QueryExecModeCacheStatement {
+		cfg.cacheStatementWarning.Do(func() {
+			log.Warn("contrib/jackc/pgx.v5: DBM propagation in full mode: the statements cached by pgx in the default " +
+				"pgx.QueryExecModeCacheStatement mode only get the service tags, so the trace context is only injected in " +
+				"the queries run in another mode: set the DefaultQueryExecMode of the connection config to an uncached " +
+				"mode such as pgx.QueryExecModeDescribeExec to inject it in all the queries")
+		})
+	}
+	buildFrontend := connConfig.BuildFrontend
+	connConfig.BuildFrontend = func(r io.Reader, w io.Writer) *pgproto3.Frontend {
+		// Connection attempts are sequential, the frontend built last is the one of the connection.
+		t.comments = &commentWriter{w: w}
+		return buildFrontend(r, t.comments)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
 
 	"github.com/jackc/pgx/v5"
 )
@@ -41,9 +42,14 @@ func (tb *tracedBatchQuery) finish() {
 	tb.span.Finish(tracer.WithError(tb.data.Err))
 }
 
+// commentsKey is the context key of the commentWriter injecting the comments of the operation in progress.
+type commentsKey struct{}
+
 type pgxTracer struct {
 	cfg            *config
 	prevBatchQuery *tracedBatchQuery
+	// comments is the writer of the connection frontend, set when DBM propagation is enabled.
+	comments *commentWriter
 }
 
 var (
@@ -54,21 +60,16 @@ var (
 	_ pgx.CopyFromTracer = (*pgxTracer)(nil)
 )
 
-func newPgxTracer(opts ...Option) *pgxTracer {
-	cfg := defaultConfig()
-	for _, opt := range opts {
-		opt(cfg)
-	}
-	return &pgxTracer{cfg: cfg}
-}
-
 func (t *pgxTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
 	if !t.cfg.traceQuery {
 		return ctx
 	}
-	opts := t.spanOptions(conn.Config(), operationTypeQuery, data.SQL)
+	connConfig := conn.Config()
+	opts := t.spanOptions(connConfig, operationTypeQuery, data.SQL,
+		t.injectComments(ctx, connConfig, t.cfg.dbmPropagationMode)...,
+	)
 	_, ctx = tracer.StartSpanFromContext(ctx, "pgx.query", opts...)
-	return ctx
+	return t.withComments(ctx)
 }
 
 func (t *pgxTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
@@ -79,6 +80,7 @@ func (t *pgxTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.Tra
 	if ok {
 		span.SetTag(tagRowsAffected, data.CommandTag.RowsAffected())
 	}
+	t.resetComments(ctx)
 	finishSpan(ctx, data.Err)
 }
 
@@ -86,11 +88,14 @@ func (t *pgxTracer) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pg
 	if !t.cfg.traceBatch {
 		return ctx
 	}
-	opts := t.spanOptions(conn.Config(), operationTypeBatch, "",
-		tracer.Tag(tagBatchNumQueries, data.Batch.Len()),
+	connConfig := conn.Config()
+	opts := t.spanOptions(connConfig, operationTypeBatch, "",
+		append(t.injectComments(ctx, connConfig, t.cfg.dbmPropagationMode),
+			tracer.Tag(tagBatchNumQueries, data.Batch.Len()),
+		)...,
 	)
 	_, ctx = tracer.StartSpanFromContext(ctx, "pgx.batch", opts...)
-	return ctx
+	return t.withComments(ctx)
 }
 
 func (t *pgxTracer) TraceBatchQuery(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
@@ -121,6 +126,7 @@ func (t *pgxTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.Tra
 		t.prevBatchQuery.finish()
 		t.prevBatchQuery = nil
 	}
+	t.resetComments(ctx)
 	finishSpan(ctx, data.Err)
 }
 
@@ -128,6 +134,8 @@ func (t *pgxTracer) TraceCopyFromStart(ctx context.Context, conn *pgx.Conn, data
 	if !t.cfg.traceCopyFrom {
 		return ctx
 	}
+	// No comments are injected: the COPY statement is followed by the copied data, which can't be
+	// linked to the query samples.
 	opts := t.spanOptions(conn.Config(), operationTypeCopyFrom, "",
 		tracer.Tag(tagCopyFromTables, data.TableName),
 		tracer.Tag(tagCopyFromColumns, data.ColumnNames),
@@ -147,7 +155,13 @@ func (t *pgxTracer) TracePrepareStart(ctx context.Context, conn *pgx.Conn, data
 	if !t.cfg.tracePrepare {
 		return ctx
 	}
-	opts := t.spanOptions(conn.Config(), operationTypePrepare, data.SQL)
+	connConfig := conn.Config()
+	opts := t.spanOptions(connConfig, operationTypePrepare, data.SQL)
+	// pgx prepares statements while running queries and batches, whose comments are kept. Otherwise,
+	// prepared statements outlive the span, so only the service tags are injected, as in contrib/database/sql.
+	if !t.hasComments(ctx) {
+		opts = append(opts, t.injectComments(ctx, connConfig, tracer.DBMPropagationModeService)...)
+	}
 	_, ctx = tracer.StartSpanFromContext(ctx, "pgx.prepare", opts...)
 	return ctx
 }
@@ -156,6 +170,9 @@ func (t *pgxTracer) TracePrepareEnd(ctx context.Context, _ *pgx.Conn, data pgx.T
 	if !t.cfg.tracePrepare {
 		return
 	}
+	if !t.hasComments(ctx) {
+		t.resetComments(ctx)
+	}
 	finishSpan(ctx, data.Err)
 }
 
@@ -206,6 +223,76 @@ func (t *pgxTracer) spanOptions(connConfig *pgx.ConnConfig, op operationType, sq
 	return opts
 }
 
+// injectComments makes the connection prepend the SQL comments of the operation about to start to
+// the queries it sends, and returns the options of its span. As with contrib/database/sql, the span
+// ID is generated when the comments are built.
+func (t *pgxTracer) injectComments(ctx context.Context, connConfig *pgx.ConnConfig, mode tracer.DBMPropagationMode) []ddtrace.StartSpanOption {
+	if t.comments == nil {
+		return nil
+	}
+	var spanCtx ddtrace.SpanContext
+	if span, ok := tracer.SpanFromContext(ctx); ok {
+		spanCtx = span.Context()
+	}
+	carrier := tracer.SQLCommentCarrier{Mode: mode, DBServiceName: t.cfg.serviceName, PeerDBHostname: connConfig.Host, PeerDBName: connConfig.Database}
+	if err := carrier.Inject(spanCtx); err != nil {
+		// this should never happen
+		log.Warn("contrib/jackc/pgx.v5: failed to inject query comments: %v", err)
+		return nil
+	}
+	serviceComment := carrier.Query
+	if mode == tracer.DBMPropagationModeFull {
+		service := carrier
+		service.Mode = tracer.DBMPropagationModeService
+		service.Query = ""
+		if err := service.Inject(spanCtx); err != nil {
+			log.Warn("contrib/jackc/pgx.v5: failed to inject query comments: %v", err)
+			return nil
+		}
+		serviceComment = service.Query
+	}
+	t.comments.set(carrier.Query, serviceComment, mode == tracer.DBMPropagationModeFull)
+	return []ddtrace.StartSpanOption{tracer.WithSpanID(carrier.SpanID)}
+}
+
+// withComments returns a copy of ctx marking that the comments of the operation in progress are
+// injected, so that the operations pgx runs as part of it keep them.
+func (t *pgxTracer) withComments(ctx context.Context) context.Context {
+	if t.comments == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, commentsKey{}, t.comments)
+}
+
+// hasComments reports whether ctx belongs to an operation whose comments are injected.
+func (t *pgxTracer) hasComments(ctx context.Context) bool {
+	cw, _ := ctx.Value(commentsKey{}).(*commentWriter)
+	return cw != nil && cw == t.comments
+}
+
+// resetComments stops prepending the SQL comments of the finished operation to the queries, and tags
+// its span if its trace context was sent to the database.
+func (t *pgxTracer) resetComments(ctx context.Context) {
+	if t.comments == nil || !t.comments.reset() {
+		return
+	}
+	if span, ok := tracer.SpanFromContext(ctx); ok {
+		span.SetTag(keyDBMTraceInjected, true)
+	}
+}
+
+// batchQueries returns the SQL statements of the queries queued in b.
+func batchQueries(b *pgx.Batch) []string {
+	if b == nil {
+		return nil
+	}
+	queries := make([]string, len(b.QueuedQueries))
+	for i, q := range b.QueuedQueries {
+		queries[i] = q.SQL
+	}
+	return queries
+}
+
 func finishSpan(ctx context.Context, err error) {
 	span, ok := tracer.SpanFromContext(ctx)
 	if !ok {
@@ -6,17 +6,22 @@
 package pgx
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 
 	"log"
+	"net"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	logger "gopkg.in/DataDog/dd-trace-go.v1/internal/log"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
@@ -54,21 +59,35 @@ func prepareDB() (func(), error) {
 	}, nil
 }
 
-func TestMain(m *testing.M) {
-	_, ok := os.LookupEnv("INTEGRATION")
-	if !ok {
-		fmt.Println("--- SKIP: to enable integration test, set the INTEGRATION environment variable")
-		os.Exit(0)
+var (
+	prepareDBOnce sync.Once
+	prepareDBErr  error
+	cleanupDB     func()
+)
+
+// skipIntegrationTest skips the tests requiring the database unless the INTEGRATION environment variable is set,
+// in which case the database is prepared the first time it is called.
+func skipIntegrationTest(t *testing.T) {
+	t.Helper()
+	if _, ok := os.LookupEnv("INTEGRATION"); !ok {
+		t.Skip("🚧 Skipping integration test (INTEGRATION environment variable is not set)")
 	}
-	cleanup, err := prepareDB()
-	if err != nil {
-		log.Fatal(err)
+	prepareDBOnce.Do(func() {
+		cleanupDB, prepareDBErr = prepareDB()
+	})
+	require.NoError(t, prepareDBErr)
+}
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if cleanupDB != nil {
+		cleanupDB()
 	}
-	defer cleanup()
-	os.Exit(m.Run())
+	os.Exit(code)
 }
 
 func TestConnect(t *testing.T) {
+	skipIntegrationTest(t)
 	mt := mocktracer.Start()
 	defer mt.Stop()
 
@@ -92,6 +111,7 @@ func TestConnect(t *testing.T) {
 }
 
 func TestQuery(t *testing.T) {
+	skipIntegrationTest(t)
 	mt := mocktracer.Start()
 	defer mt.Stop()
 
@@ -125,6 +145,7 @@ func TestQuery(t *testing.T) {
 }
 
 func TestPrepare(t *testing.T) {
+	skipIntegrationTest(t)
 	mt := mocktracer.Start()
 	defer mt.Stop()
 
@@ -158,6 +179,7 @@ func TestPrepare(t *testing.T) {
 }
 
 func TestBatch(t *testing.T) {
+	skipIntegrationTest(t)
 	mt := mocktracer.Start()
 	defer mt.Stop()
 
@@ -207,6 +229,7 @@ func TestBatch(t *testing.T) {
 }
 
 func TestCopyFrom(t *testing.T) {
+	skipIntegrationTest(t)
 	mt := mocktracer.Start()
 	defer mt.Stop()
 
@@ -231,6 +254,217 @@ func TestCopyFrom(t *testing.T) {
 	assert.Equal(t, ps.SpanID(), s.ParentID())
 }
 
+func TestDBMPropagation(t *testing.T) {
+	skipIntegrationTest(t)
+	// connect returns a connection to the database along with the buffer recording what it writes.
+	connect := func(t *testing.T, ctx context.Context, opts ...Option) (*pgx.Conn, *lockedBuffer) {
+		t.Helper()
+		connConfig, err := pgx.ParseConfig(postgresDSN)
+		require.NoError(t, err)
+		var buf lockedBuffer
+		connConfig.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+			return &recordingConn{Conn: conn, buf: &buf}, err
+		}
+		conn, err := ConnectConfig(ctx, connConfig, opts...)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close(context.Background()) })
+		return conn, &buf
+	}
+
+	for _, tt := range []struct {
+		name     string
+		mode     tracer.DBMPropagationMode
+		args     []any
+		injected bool
+	}{
+		{name: "full", mode: tracer.DBMPropagationModeFull, args: []any{pgx.QueryExecModeExec}, injected: true},
+		{name: "full-describe-exec", mode: tracer.DBMPropagationModeFull, args: []any{pgx.QueryExecModeDescribeExec}, injected: true},
+		{name: "full-simple-protocol", mode: tracer.DBMPropagationModeFull, args: []any{pgx.QueryExecModeSimpleProtocol}, injected: true},
+		// the statements cached by pgx only get the service tags.
+		{name: "full-cache-statement", mode: tracer.DBMPropagationModeFull, args: []any{pgx.QueryExecModeCacheStatement}},
+		{name: "service", mode: tracer.DBMPropagationModeService, args: []any{pgx.QueryExecModeExec}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			ctx := context.Background()
+			conn, buf := connect(t, ctx, WithDBMPropagation(tt.mode))
+			var x int
+			err := conn.QueryRow(ctx, `SELECT 1`, tt.args...).Scan(&x)
+			require.NoError(t, err)
+
+			spans := mt.FinishedSpans()
+			require.NotEmpty(t, spans)
+			s := spans[len(spans)-1]
+			assert.Equal(t, "pgx.query", s.OperationName())
+			assert.Contains(t, buf.String(), "/*dddbs='postgres.db'")
+			assert.Contains(t, buf.String(), "*/ SELECT 1")
+			traceparent := fmt.Sprintf("traceparent='00-%032x-%016x-00'", s.SpanID(), s.SpanID())
+			if tt.injected {
+				assert.Equal(t, true, s.Tag(keyDBMTraceInjected))
+				assert.Contains(t, buf.String(), traceparent)
+			} else {
+				assert.Nil(t, s.Tag(keyDBMTraceInjected))
+				assert.NotContains(t, buf.String(), "traceparent")
+			}
+		})
+	}
+
+	t.Run("prepare", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		ctx := context.Background()
+		conn, buf := connect(t, ctx, WithDBMPropagation(tracer.DBMPropagationModeFull))
+		_, err := conn.Prepare(ctx, "stmt", `SELECT $1::integer`)
+		require.NoError(t, err)
+		var x int
+		err = conn.QueryRow(ctx, "stmt", 1).Scan(&x)
+		require.NoError(t, err)
+
+		assert.Contains(t, buf.String(), "*/ SELECT $1::integer")
+		assert.NotContains(t, buf.String(), "traceparent")
+		for _, s := range mt.FinishedSpans() {
+			assert.Nil(t, s.Tag(keyDBMTraceInjected))
+		}
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		ctx := context.Background()
+		conn, buf := connect(t, ctx, WithDBMPropagation(tracer.DBMPropagationModeFull))
+		batch := &pgx.Batch{}
+		batch.Queue(`SELECT 1`)
+		batch.Queue(`SELECT 2`)
+		err := conn.SendBatch(ctx, batch).Close()
+		require.NoError(t, err)
+
+		spans := mt.FinishedSpans()
+		require.NotEmpty(t, spans)
+		s := spans[len(spans)-1]
+		assert.Equal(t, "pgx.batch", s.OperationName())
+		// the batch is sent in the default mode, caching its statements.
+		assert.Contains(t, buf.String(), "*/ SELECT 1")
+		assert.Contains(t, buf.String(), "*/ SELECT 2")
+		assert.Nil(t, s.Tag(keyDBMTraceInjected))
+	})
+
+	t.Run("copy-from", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		ctx := context.Background()
+		conn, buf := connect(t, ctx, WithDBMPropagation(tracer.DBMPropagationModeFull))
+		_, err := conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS numbers (number INT NOT NULL)`, pgx.QueryExecModeExec)
+		require.NoError(t, err)
+		buf.Reset()
+		_, err = conn.CopyFrom(ctx, []string{"numbers"}, []string{"number"}, pgx.CopyFromRows([][]any{{1}}))
+		require.NoError(t, err)
+
+		// only the statement describing the table is commented, as a prepared statement.
+		assert.Contains(t, buf.String(), "copy ")
+		assert.NotContains(t, buf.String(), "*/ copy")
+		spans := mt.FinishedSpans()
+		s := spans[len(spans)-1]
+		assert.Equal(t, "pgx.copy_from", s.OperationName())
+		assert.Nil(t, s.Tag(keyDBMTraceInjected))
+	})
+}
+
+// TestDBMPropagationDefaultMode checks the full mode with the default pgx.QueryExecModeCacheStatement mode, using a
+// fake server: the cached statements only get the service tags, and a warning is logged.
+func TestDBMPropagationDefaultMode(t *testing.T) {
+	tl := new(logger.RecordLogger)
+	defer logger.UseLogger(tl)()
+	srv := &fakeServer{}
+	connConfig, err := pgx.ParseConfig("postgres://postgres@127.0.0.1:5432/postgres?sslmode=disable")
+	require.NoError(t, err)
+	require.Equal(t, pgx.QueryExecModeCacheStatement, connConfig.DefaultQueryExecMode)
+	connConfig.DialFunc = srv.dial
+	ctx := context.Background()
+	conn, err := ConnectConfig(ctx, connConfig, WithDBMPropagation(tracer.DBMPropagationModeFull))
+	require.NoError(t, err)
+	defer conn.Close(ctx)
+	assert.Contains(t, strings.Join(tl.Logs(), "\n"), "pgx.QueryExecModeCacheStatement mode only get the service tags")
+
+	for _, tt := range []struct {
+		name     string
+		query    string
+		args     []any
+		injected bool
+	}{
+		{name: "default", query: "SELECT 1"},
+		{name: "describe-exec", query: "SELECT 2", args: []any{pgx.QueryExecModeDescribeExec}, injected: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+			srv.reset()
+
+			// unlike Exec, Query doesn't use the simple protocol for the queries without arguments.
+			rows, err := conn.Query(ctx, tt.query, tt.args...)
+			require.NoError(t, err)
+			rows.Close()
+			require.NoError(t, rows.Err())
+
+			spans := mt.FinishedSpans()
+			require.NotEmpty(t, spans)
+			s := spans[len(spans)-1]
+			assert.Equal(t, "pgx.query", s.OperationName())
+			queries := srv.queries()
+			require.Len(t, queries, 1)
+			assert.True(t, strings.HasPrefix(queries[0], "/*dddbs='postgres.db'"))
+			assert.True(t, strings.HasSuffix(queries[0], "*/ "+tt.query))
+			if tt.injected {
+				assert.Equal(t, true, s.Tag(keyDBMTraceInjected))
+				assert.Contains(t, queries[0], "traceparent=")
+			} else {
+				assert.Nil(t, s.Tag(keyDBMTraceInjected))
+				assert.NotContains(t, queries[0], "traceparent")
+			}
+		})
+	}
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent use.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (b *lockedBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Reset()
+}
+
+// recordingConn is a net.Conn recording what is written to it.
+type recordingConn struct {
+	net.Conn
+	buf *lockedBuffer
+}
+
+func (c *recordingConn) Write(p []byte) (int, error) {
+	c.buf.Write(p)
+	return c.Conn.Write(p)
+}
+
 func tracingAllDisabled() []Option {
 	return []Option{
 		WithTraceConnect(false),
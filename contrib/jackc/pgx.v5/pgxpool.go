@@ -8,6 +8,7 @@ package pgx
 import (
 	"context"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -20,6 +21,20 @@ func NewPool(ctx context.Context, connString string, opts ...Option) (*pgxpool.P
 }
 
 func NewPoolWithConfig(ctx context.Context, config *pgxpool.Config, opts ...Option) (*pgxpool.Pool, error) {
-	config.ConnConfig.Tracer = newPgxTracer(opts...)
+	cfg := newConfig(opts...)
+	// The config is copied so that it can be reused without wrapping
+	// its BeforeConnect hook more than once.
+	config = config.Copy()
+	beforeConnect := config.BeforeConnect
+	config.BeforeConnect = func(ctx context.Context, connConfig *pgx.ConnConfig) error {
+		if beforeConnect != nil {
+			if err := beforeConnect(ctx, connConfig); err != nil {
+				return err
+			}
+		}
+		// connConfig is a copy of config.ConnConfig made for each new connection.
+		configureConn(connConfig, cfg)
+		return nil
+	}
 	return pgxpool.NewWithConfig(ctx, config)
 }
@@ -10,12 +10,16 @@ import (
 	"testing"
 
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestPool(t *testing.T) {
+	skipIntegrationTest(t)
 	mt := mocktracer.Start()
 	defer mt.Stop()
 
@@ -38,3 +42,34 @@ func TestPool(t *testing.T) {
 	assert.Len(t, mt.OpenSpans(), 0)
 	assert.Len(t, mt.FinishedSpans(), 5)
 }
+
+func TestPoolDBMPropagation(t *testing.T) {
+	skipIntegrationTest(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	ctx := context.Background()
+
+	config, err := pgxpool.ParseConfig(postgresDSN)
+	require.NoError(t, err)
+	var beforeConnect int
+	config.BeforeConnect = func(context.Context, *pgx.ConnConfig) error {
+		beforeConnect++
+		return nil
+	}
+	conn, err := NewPoolWithConfig(ctx, config, WithDBMPropagation(tracer.DBMPropagationModeFull))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var x int
+	err = conn.QueryRow(ctx, `select 1`, pgx.QueryExecModeExec).Scan(&x)
+	require.NoError(t, err)
+	assert.Equal(t, 1, x)
+	assert.Equal(t, 1, beforeConnect)
+
+	spans := mt.FinishedSpans()
+	require.NotEmpty(t, spans)
+	s := spans[len(spans)-1]
+	assert.Equal(t, "pgx.query", s.OperationName())
+	assert.Equal(t, true, s.Tag(keyDBMTraceInjected))
+}
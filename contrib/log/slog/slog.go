@@ -0,0 +1,208 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package slog provides a log/span correlation handler for the log/slog package (https://pkg.go.dev/log/slog).
+package slog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/contrib/internal/logcorrelation"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
+)
+
+const componentName = "log/slog"
+
+func init() {
+	telemetry.LoadIntegration(componentName)
+	tracer.MarkIntegrationImported(componentName)
+}
+
+// NewJSONHandler is a convenience function that returns a *slog.JSONHandler logger enhanced with
+// tracing information.
+func NewJSONHandler(w io.Writer, opts *slog.HandlerOptions, traceOpts ...Option) slog.Handler {
+	return WrapHandler(slog.NewJSONHandler(w, opts), traceOpts...)
+}
+
+// WrapHandler enhances the given logger handler attaching tracing information to logs. The span
+// is looked up in the context passed to the logger, e.g. with slog.InfoContext.
+func WrapHandler(h slog.Handler, opts ...Option) slog.Handler {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/log/slog: Configuring Handler: %#v", cfg)
+	return &handler{base: h, wrapped: h, cfg: cfg}
+}
+
+// groupOrAttrs holds either a group name or a list of attributes added to the
+// handler through WithGroup or WithAttrs.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// handler is a slog.Handler that adds tracing information to the records it
+// handles before passing them to the wrapped handler.
+type handler struct {
+	// base is the handler given to WrapHandler.
+	base slog.Handler
+	// wrapped is base with goas applied to it.
+	wrapped slog.Handler
+	// goas holds the groups and attributes added to the handler, in order,
+	// with consecutive attributes merged. They are needed to add the tracing
+	// attributes at the root level of the record rather than in the last
+	// opened group.
+	goas []groupOrAttrs
+	// grouped reports whether a group was opened on the handler.
+	grouped bool
+	// last holds the tracing attributes of the last span seen by Handle,
+	// along with the handler chain replayed on top of them, so that they
+	// are only built once for the records logged within the same span.
+	last atomic.Pointer[spanHandler]
+	cfg  *config
+}
+
+// spanHandler holds the tracing attributes of a span and, for grouped
+// handlers, the handler chain adding them at the root level.
+type spanHandler struct {
+	traceID, spanID uint64
+	attrs           []slog.Attr
+	wrapped         slog.Handler
+}
+
+// Enabled implements slog.Handler.
+func (h *handler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	return h.wrapped.Enabled(ctx, lvl)
+}
+
+// Handle implements slog.Handler.
+func (h *handler) Handle(ctx context.Context, rec slog.Record) error {
+	span, ok := tracer.SpanFromContext(ctx)
+	if !ok {
+		return h.wrapped.Handle(ctx, rec)
+	}
+	if h.cfg.errorLevel != nil && rec.Level >= h.cfg.errorLevel.Level() {
+		span.SetTag(ext.Error, recordError(rec))
+	}
+	sh := h.spanHandler(span.Context())
+	if sh.wrapped != nil {
+		return sh.wrapped.Handle(ctx, rec)
+	}
+	// The attributes of the record are at the root level.
+	rec = rec.Clone()
+	rec.AddAttrs(sh.attrs...)
+	return h.wrapped.Handle(ctx, rec)
+}
+
+// spanHandler returns the spanHandler of the given span context, reusing the
+// last one if it belongs to the same span.
+func (h *handler) spanHandler(ctx ddtrace.SpanContext) *spanHandler {
+	if sh := h.last.Load(); sh != nil && sh.spanID == ctx.SpanID() && sh.traceID == ctx.TraceID() {
+		return sh
+	}
+	sh := &spanHandler{
+		traceID: ctx.TraceID(),
+		spanID:  ctx.SpanID(),
+		attrs:   h.attrs(ctx),
+	}
+	if h.grouped {
+		// Only the groups and the merged attributes between them are
+		// replayed on top of the tracing attributes.
+		attrs, goas := sh.attrs, h.goas
+		if goas[0].group == "" {
+			attrs = append(attrs[:len(attrs):len(attrs)], goas[0].attrs...)
+			goas = goas[1:]
+		}
+		wrapped := h.base.WithAttrs(attrs)
+		for _, goa := range goas {
+			if goa.group != "" {
+				wrapped = wrapped.WithGroup(goa.group)
+			} else {
+				wrapped = wrapped.WithAttrs(goa.attrs)
+			}
+		}
+		sh.wrapped = wrapped
+	}
+	h.last.Store(sh)
+	return sh
+}
+
+// attrs returns the tracing attributes for the given span context.
+func (h *handler) attrs(ctx ddtrace.SpanContext) []slog.Attr {
+	tags := h.cfg.serviceTags.Tags()
+	attrs := make([]slog.Attr, 0, 2+len(tags))
+	attrs = append(attrs,
+		slog.String(logcorrelation.KeyTraceID, logcorrelation.TraceID(ctx, h.cfg.use128BitTraceID)),
+		slog.String(logcorrelation.KeySpanID, logcorrelation.SpanID(ctx)),
+	)
+	for _, tag := range tags {
+		attrs = append(attrs, slog.String(tag.Key, tag.Value))
+	}
+	return attrs
+}
+
+// recordError returns the first error found in the attributes of rec, or an
+// error holding the message of rec if there is none.
+func recordError(rec slog.Record) error {
+	var err error
+	rec.Attrs(func(a slog.Attr) bool {
+		if e, ok := a.Value.Resolve().Any().(error); ok {
+			err = e
+			return false
+		}
+		return true
+	})
+	if err == nil {
+		err = errors.New(rec.Message)
+	}
+	return err
+}
+
+// WithAttrs implements slog.Handler.
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(groupOrAttrs{attrs: attrs}, h.wrapped.WithAttrs(attrs))
+}
+
+// WithGroup implements slog.Handler.
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name}, h.wrapped.WithGroup(name))
+}
+
+func (h *handler) with(goa groupOrAttrs, wrapped slog.Handler) *handler {
+	goas := make([]groupOrAttrs, len(h.goas), len(h.goas)+1)
+	copy(goas, h.goas)
+	if n := len(goas); goa.group == "" && n > 0 && goas[n-1].group == "" {
+		// Merge the attributes with the previous ones, without modifying
+		// the slice shared with h.
+		merged := make([]slog.Attr, 0, len(goas[n-1].attrs)+len(goa.attrs))
+		merged = append(merged, goas[n-1].attrs...)
+		goas[n-1].attrs = append(merged, goa.attrs...)
+	} else {
+		goas = append(goas, goa)
+	}
+	return &handler{
+		base:    h.base,
+		wrapped: wrapped,
+		goas:    goas,
+		grouped: h.grouped || goa.group != "",
+		cfg:     h.cfg,
+	}
+}
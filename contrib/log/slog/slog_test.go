@@ -0,0 +1,176 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package slog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var m map[string]any
+		require.NoError(t, dec.Decode(&m))
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func TestHandler(t *testing.T) {
+	tracer.Start(tracer.WithLogger(testLogger{}))
+	defer tracer.Stop()
+
+	t.Run("128-bit", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(NewJSONHandler(&buf, nil, WithService("svc"), WithEnv("prod"), WithVersion("1.2.3")))
+		span, ctx := tracer.StartSpanFromContext(context.Background(), "test")
+		defer span.Finish()
+
+		logger.InfoContext(ctx, "with span")
+		logger.Info("without span")
+
+		lines := decodeLines(t, &buf)
+		require.Len(t, lines, 2)
+		w3c := span.Context().(ddtrace.SpanContextW3C)
+		assert.Equal(t, w3c.TraceID128(), lines[0]["dd.trace_id"])
+		assert.Equal(t, strconv.FormatUint(span.Context().SpanID(), 10), lines[0]["dd.span_id"])
+		assert.Equal(t, "svc", lines[0]["dd.service"])
+		assert.Equal(t, "prod", lines[0]["dd.env"])
+		assert.Equal(t, "1.2.3", lines[0]["dd.version"])
+		assert.NotContains(t, lines[1], "dd.trace_id")
+		assert.NotContains(t, lines[1], "dd.span_id")
+	})
+
+	t.Run("64-bit", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(NewJSONHandler(&buf, nil, With128BitTraceID(false)))
+		span, ctx := tracer.StartSpanFromContext(context.Background(), "test")
+		defer span.Finish()
+
+		logger.InfoContext(ctx, "with span")
+
+		lines := decodeLines(t, &buf)
+		require.Len(t, lines, 1)
+		assert.Equal(t, strconv.FormatUint(span.Context().TraceID(), 10), lines[0]["dd.trace_id"])
+		assert.NotContains(t, lines[0], "dd.env")
+	})
+
+	t.Run("groups", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(NewJSONHandler(&buf, nil)).
+			With("a", 1).
+			WithGroup("g").
+			With("b", 2)
+		span, ctx := tracer.StartSpanFromContext(context.Background(), "test")
+		defer span.Finish()
+
+		logger.InfoContext(ctx, "grouped", "c", 3)
+
+		lines := decodeLines(t, &buf)
+		require.Len(t, lines, 1)
+		assert.Contains(t, lines[0], "dd.trace_id")
+		assert.Contains(t, lines[0], "dd.span_id")
+		assert.Equal(t, float64(1), lines[0]["a"])
+		assert.Equal(t, map[string]any{"b": float64(2), "c": float64(3)}, lines[0]["g"])
+	})
+
+	t.Run("attrs", func(t *testing.T) {
+		var buf bytes.Buffer
+		h := NewJSONHandler(&buf, nil).
+			WithAttrs([]slog.Attr{slog.Int("a", 1)}).
+			WithAttrs([]slog.Attr{slog.Int("b", 2)}).
+			WithGroup("g").
+			WithAttrs([]slog.Attr{slog.Int("c", 3)}).
+			WithAttrs([]slog.Attr{slog.Int("d", 4)})
+		// consecutive attributes are merged, so that only groups are replayed.
+		assert.Len(t, h.(*handler).goas, 3)
+		span, ctx := tracer.StartSpanFromContext(context.Background(), "test")
+		defer span.Finish()
+
+		logger := slog.New(h)
+		logger.InfoContext(ctx, "grouped")
+		slog.New(NewJSONHandler(&buf, nil)).With("a", 1).With("b", 2).InfoContext(ctx, "ungrouped", "c", 3)
+
+		lines := decodeLines(t, &buf)
+		require.Len(t, lines, 2)
+		assert.Equal(t, strconv.FormatUint(span.Context().SpanID(), 10), lines[0]["dd.span_id"])
+		assert.Equal(t, float64(1), lines[0]["a"])
+		assert.Equal(t, float64(2), lines[0]["b"])
+		assert.Equal(t, map[string]any{"c": float64(3), "d": float64(4)}, lines[0]["g"])
+		assert.Equal(t, strconv.FormatUint(span.Context().SpanID(), 10), lines[1]["dd.span_id"])
+		assert.Equal(t, float64(1), lines[1]["a"])
+		assert.Equal(t, float64(2), lines[1]["b"])
+		assert.Equal(t, float64(3), lines[1]["c"])
+	})
+
+	t.Run("same-span", func(t *testing.T) {
+		var buf bytes.Buffer
+		h := NewJSONHandler(&buf, nil).WithGroup("g")
+		logger := slog.New(h)
+		span, ctx := tracer.StartSpanFromContext(context.Background(), "test")
+		defer span.Finish()
+		child, childCtx := tracer.StartSpanFromContext(ctx, "child")
+		defer child.Finish()
+
+		logger.InfoContext(ctx, "first")
+		last := h.(*handler).last.Load()
+		logger.InfoContext(ctx, "second")
+		// the handler chain is only built once per span.
+		assert.Same(t, last, h.(*handler).last.Load())
+		logger.InfoContext(childCtx, "child")
+
+		lines := decodeLines(t, &buf)
+		require.Len(t, lines, 3)
+		assert.Equal(t, strconv.FormatUint(span.Context().SpanID(), 10), lines[0]["dd.span_id"])
+		assert.Equal(t, strconv.FormatUint(span.Context().SpanID(), 10), lines[1]["dd.span_id"])
+		assert.Equal(t, strconv.FormatUint(child.Context().SpanID(), 10), lines[2]["dd.span_id"])
+	})
+}
+
+func TestErrorLevel(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	var buf bytes.Buffer
+	logger := slog.New(NewJSONHandler(&buf, nil, WithErrorLevel(slog.LevelError)))
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "warn")
+	logger.WarnContext(ctx, "not an error")
+	span.Finish()
+
+	span, ctx = tracer.StartSpanFromContext(context.Background(), "message")
+	logger.ErrorContext(ctx, "something failed")
+	span.Finish()
+
+	span, ctx = tracer.StartSpanFromContext(context.Background(), "attr")
+	logger.ErrorContext(ctx, "something failed", "err", errors.New("boom"))
+	span.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 3)
+	assert.Nil(t, spans[0].Tag("error"))
+	assert.EqualError(t, spans[1].Tag("error").(error), "something failed")
+	assert.EqualError(t, spans[2].Tag("error").(error), "boom")
+}
+
+type testLogger struct{}
+
+func (testLogger) Log(string) {}
@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package nats_test
+
+import (
+	"log"
+
+	natstrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/nats-io/nats.go"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+
+	"github.com/nats-io/nats.go"
+)
+
+func Example() {
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	c := natstrace.WrapConn(nc, natstrace.WithServiceName("orders"))
+	defer c.Close()
+
+	// the messages delivered to the handler are traced
+	_, err = c.Subscribe("orders.created", func(m *nats.Msg) {
+		// the span context of the consume span is injected in the message headers
+		spanctx, _ := natstrace.ExtractSpanContext(m)
+		span := tracer.StartSpan("process.order", tracer.ChildOf(spanctx))
+		defer span.Finish()
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := c.Publish("orders.created", []byte("order")); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ExampleConn_JetStream() {
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	c := natstrace.WrapConn(nc, natstrace.WithDataStreams())
+	defer c.Close()
+
+	js, err := c.JetStream()
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = js.Subscribe("orders.created", func(m *nats.Msg) {
+		m.Ack()
+	}, nats.Durable("processor"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := js.Publish("orders.created", []byte("order")); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ExampleJetStreamContext_PullSubscribe() {
+	nc, err := nats.Connect(nats.DefaultURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	c := natstrace.WrapConn(nc, natstrace.WithDataStreams())
+	defer c.Close()
+
+	js, err := c.JetStream()
+	if err != nil {
+		log.Fatal(err)
+	}
+	sub, err := js.PullSubscribe("orders.created", "processor")
+	if err != nil {
+		log.Fatal(err)
+	}
+	// the fetched messages are traced
+	msgs, err := sub.Fetch(10)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, m := range msgs {
+		m.Ack()
+	}
+}
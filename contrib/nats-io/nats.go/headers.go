@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package nats
+
+import (
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+
+	"github.com/nats-io/nats.go"
+)
+
+// A messageCarrier implements TextMapReader/TextMapWriter for extracting/injecting traces on a nats.Msg
+type messageCarrier struct {
+	msg *nats.Msg
+}
+
+var _ interface {
+	tracer.TextMapReader
+	tracer.TextMapWriter
+} = (*messageCarrier)(nil)
+
+// ForeachKey conforms to the TextMapReader interface.
+func (c messageCarrier) ForeachKey(handler func(key, val string) error) error {
+	for k, vals := range c.msg.Header {
+		for _, v := range vals {
+			if err := handler(k, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Set implements TextMapWriter
+func (c messageCarrier) Set(key, val string) {
+	if c.msg.Header == nil {
+		c.msg.Header = nats.Header{}
+	}
+	c.msg.Header.Set(key, val)
+}
+
+// ExtractSpanContext retrieves the SpanContext from a nats.Msg
+func ExtractSpanContext(msg *nats.Msg) (ddtrace.SpanContext, error) {
+	return tracer.Extract(messageCarrier{msg})
+}
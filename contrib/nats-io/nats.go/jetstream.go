@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package nats
+
+import (
+	"context"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+
+	"github.com/nats-io/nats.go"
+)
+
+// A JetStreamContext wraps a nats.JetStreamContext so that the published
+// messages, and the messages delivered to the push subscriptions or fetched by
+// the pull subscriptions, are traced.
+type JetStreamContext struct {
+	nats.JetStreamContext
+	cfg *config
+}
+
+// JetStream calls nats.Conn.JetStream and wraps the resulting
+// nats.JetStreamContext with the options of the Conn.
+func (c *Conn) JetStream(opts ...nats.JSOpt) (*JetStreamContext, error) {
+	js, err := c.Conn.JetStream(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &JetStreamContext{
+		JetStreamContext: js,
+		cfg:              c.cfg,
+	}, nil
+}
+
+// Publish calls nats.JetStreamContext.PublishMsg with a message holding the
+// given data and traces the request.
+func (js *JetStreamContext) Publish(subj string, data []byte, opts ...nats.PubOpt) (*nats.PubAck, error) {
+	return js.PublishMsgWithContext(context.Background(), &nats.Msg{Subject: subj, Data: data}, opts...)
+}
+
+// PublishMsg calls nats.JetStreamContext.PublishMsg and traces the request.
+func (js *JetStreamContext) PublishMsg(m *nats.Msg, opts ...nats.PubOpt) (*nats.PubAck, error) {
+	return js.PublishMsgWithContext(context.Background(), m, opts...)
+}
+
+// PublishMsgWithContext calls nats.JetStreamContext.PublishMsg and traces the
+// request as a child of the span found in ctx, if any.
+func (js *JetStreamContext) PublishMsgWithContext(ctx context.Context, m *nats.Msg, opts ...nats.PubOpt) (*nats.PubAck, error) {
+	span := startProducerSpan(ctx, js.cfg, m)
+	ack, err := js.JetStreamContext.PublishMsg(m, opts...)
+	if ack != nil {
+		span.SetTag(ext.NATSStream, ack.Stream)
+	}
+	span.Finish(tracer.WithError(err))
+	return ack, err
+}
+
+// Subscribe calls nats.JetStreamContext.Subscribe, tracing the messages
+// delivered to cb.
+func (js *JetStreamContext) Subscribe(subj string, cb nats.MsgHandler, opts ...nats.SubOpt) (*nats.Subscription, error) {
+	return js.JetStreamContext.Subscribe(subj, wrapMsgHandler(js.cfg, "", cb), opts...)
+}
+
+// QueueSubscribe calls nats.JetStreamContext.QueueSubscribe, tracing the
+// messages delivered to cb.
+func (js *JetStreamContext) QueueSubscribe(subj, queue string, cb nats.MsgHandler, opts ...nats.SubOpt) (*nats.Subscription, error) {
+	return js.JetStreamContext.QueueSubscribe(subj, queue, wrapMsgHandler(js.cfg, queue, cb), opts...)
+}
+
+// PublishAsync calls nats.JetStreamContext.PublishMsgAsync with a message
+// holding the given data and traces the request.
+func (js *JetStreamContext) PublishAsync(subj string, data []byte, opts ...nats.PubOpt) (nats.PubAckFuture, error) {
+	return js.PublishMsgAsyncWithContext(context.Background(), &nats.Msg{Subject: subj, Data: data}, opts...)
+}
+
+// PublishMsgAsync calls nats.JetStreamContext.PublishMsgAsync and traces the
+// request.
+func (js *JetStreamContext) PublishMsgAsync(m *nats.Msg, opts ...nats.PubOpt) (nats.PubAckFuture, error) {
+	return js.PublishMsgAsyncWithContext(context.Background(), m, opts...)
+}
+
+// PublishMsgAsyncWithContext calls nats.JetStreamContext.PublishMsgAsync and
+// traces the request as a child of the span found in ctx, if any. The span is
+// finished once the message is sent, without waiting for its acknowledgement.
+func (js *JetStreamContext) PublishMsgAsyncWithContext(ctx context.Context, m *nats.Msg, opts ...nats.PubOpt) (nats.PubAckFuture, error) {
+	span := startProducerSpan(ctx, js.cfg, m)
+	future, err := js.JetStreamContext.PublishMsgAsync(m, opts...)
+	span.Finish(tracer.WithError(err))
+	return future, err
+}
+
+// SubscribeSync calls nats.JetStreamContext.SubscribeSync and wraps the
+// subscription so that the messages it returns are traced.
+func (js *JetStreamContext) SubscribeSync(subj string, opts ...nats.SubOpt) (*Subscription, error) {
+	sub, err := js.JetStreamContext.SubscribeSync(subj, opts...)
+	return wrapSubscription(js.cfg, "", sub, err)
+}
+
+// QueueSubscribeSync calls nats.JetStreamContext.QueueSubscribeSync and wraps
+// the subscription so that the messages it returns are traced.
+func (js *JetStreamContext) QueueSubscribeSync(subj, queue string, opts ...nats.SubOpt) (*Subscription, error) {
+	sub, err := js.JetStreamContext.QueueSubscribeSync(subj, queue, opts...)
+	return wrapSubscription(js.cfg, queue, sub, err)
+}
+
+// PullSubscribe calls nats.JetStreamContext.PullSubscribe and wraps the
+// subscription so that the messages returned by Subscription.Fetch are
+// traced.
+func (js *JetStreamContext) PullSubscribe(subj, durable string, opts ...nats.SubOpt) (*Subscription, error) {
+	sub, err := js.JetStreamContext.PullSubscribe(subj, durable, opts...)
+	return wrapSubscription(js.cfg, "", sub, err)
+}
@@ -0,0 +1,304 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package nats provides functions to trace the nats-io/nats.go package (https://github.com/nats-io/nats.go).
+//
+// The messages published, requested or received through a Conn, a
+// JetStreamContext or a Subscription are traced. The messages delivered to a
+// channel, with ChanSubscribe, ChanQueueSubscribe, QueueSubscribeSyncWithChan
+// or nats.Subscription.FetchBatch, are not traced: use the subscriptions
+// taking a handler, or the synchronous ones, instead.
+package nats // import "gopkg.in/DataDog/dd-trace-go.v1/contrib/nats-io/nats.go"
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams"
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams/options"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
+
+	"github.com/nats-io/nats.go"
+)
+
+const componentName = "nats-io/nats.go"
+
+func init() {
+	telemetry.LoadIntegration(componentName)
+	tracer.MarkIntegrationImported("github.com/nats-io/nats.go")
+}
+
+// A Conn wraps a nats.Conn so that the published messages and the messages
+// delivered to the subscriptions are traced.
+type Conn struct {
+	*nats.Conn
+	cfg *config
+}
+
+// WrapConn wraps a nats.Conn so that the published messages and the messages
+// delivered to the subscriptions are traced.
+func WrapConn(nc *nats.Conn, opts ...Option) *Conn {
+	c := &Conn{
+		Conn: nc,
+		cfg:  newConfig(opts...),
+	}
+	log.Debug("contrib/nats-io/nats.go: Wrapping Conn: %#v", c.cfg)
+	return c
+}
+
+// Publish calls nats.Conn.PublishMsg with a message holding the given data and
+// traces the request.
+func (c *Conn) Publish(subj string, data []byte) error {
+	return c.PublishMsgWithContext(context.Background(), &nats.Msg{Subject: subj, Data: data})
+}
+
+// PublishMsg calls nats.Conn.PublishMsg and traces the request.
+func (c *Conn) PublishMsg(m *nats.Msg) error {
+	return c.PublishMsgWithContext(context.Background(), m)
+}
+
+// PublishMsgWithContext calls nats.Conn.PublishMsg and traces the request as
+// a child of the span found in ctx, if any.
+func (c *Conn) PublishMsgWithContext(ctx context.Context, m *nats.Msg) error {
+	span := startProducerSpan(ctx, c.cfg, m)
+	err := c.Conn.PublishMsg(m)
+	span.Finish(tracer.WithError(err))
+	return err
+}
+
+// Subscribe calls nats.Conn.Subscribe, tracing the messages delivered to cb.
+func (c *Conn) Subscribe(subj string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	return c.Conn.Subscribe(subj, wrapMsgHandler(c.cfg, "", cb))
+}
+
+// QueueSubscribe calls nats.Conn.QueueSubscribe, tracing the messages
+// delivered to cb.
+func (c *Conn) QueueSubscribe(subj, queue string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	return c.Conn.QueueSubscribe(subj, queue, wrapMsgHandler(c.cfg, queue, cb))
+}
+
+// Request calls nats.Conn.RequestMsg with a message holding the given data and
+// traces the request.
+func (c *Conn) Request(subj string, data []byte, timeout time.Duration) (*nats.Msg, error) {
+	return c.RequestMsg(&nats.Msg{Subject: subj, Data: data}, timeout)
+}
+
+// RequestMsg calls nats.Conn.RequestMsg and traces the request. The span is
+// finished once the response is received.
+func (c *Conn) RequestMsg(m *nats.Msg, timeout time.Duration) (*nats.Msg, error) {
+	span := startProducerSpan(context.Background(), c.cfg, m)
+	resp, err := c.Conn.RequestMsg(m, timeout)
+	span.Finish(tracer.WithError(err))
+	return resp, err
+}
+
+// RequestWithContext calls nats.Conn.RequestMsgWithContext with a message
+// holding the given data and traces the request as a child of the span found
+// in ctx, if any.
+func (c *Conn) RequestWithContext(ctx context.Context, subj string, data []byte) (*nats.Msg, error) {
+	return c.RequestMsgWithContext(ctx, &nats.Msg{Subject: subj, Data: data})
+}
+
+// RequestMsgWithContext calls nats.Conn.RequestMsgWithContext and traces the
+// request as a child of the span found in ctx, if any. The span is finished
+// once the response is received.
+func (c *Conn) RequestMsgWithContext(ctx context.Context, m *nats.Msg) (*nats.Msg, error) {
+	span := startProducerSpan(ctx, c.cfg, m)
+	resp, err := c.Conn.RequestMsgWithContext(ctx, m)
+	span.Finish(tracer.WithError(err))
+	return resp, err
+}
+
+// SubscribeSync calls nats.Conn.SubscribeSync and wraps the subscription so
+// that the messages it returns are traced.
+func (c *Conn) SubscribeSync(subj string) (*Subscription, error) {
+	sub, err := c.Conn.SubscribeSync(subj)
+	return wrapSubscription(c.cfg, "", sub, err)
+}
+
+// QueueSubscribeSync calls nats.Conn.QueueSubscribeSync and wraps the
+// subscription so that the messages it returns are traced.
+func (c *Conn) QueueSubscribeSync(subj, queue string) (*Subscription, error) {
+	sub, err := c.Conn.QueueSubscribeSync(subj, queue)
+	return wrapSubscription(c.cfg, queue, sub, err)
+}
+
+// A Subscription wraps a synchronous nats.Subscription so that the messages
+// it returns are traced. As the handling of a message can't be followed, its
+// span is finished as soon as it is returned.
+type Subscription struct {
+	*nats.Subscription
+	cfg   *config
+	queue string
+}
+
+func wrapSubscription(cfg *config, queue string, sub *nats.Subscription, err error) (*Subscription, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &Subscription{
+		Subscription: sub,
+		cfg:          cfg,
+		queue:        queue,
+	}, nil
+}
+
+// NextMsg calls nats.Subscription.NextMsg and traces the message, if any.
+func (s *Subscription) NextMsg(timeout time.Duration) (*nats.Msg, error) {
+	m, err := s.Subscription.NextMsg(timeout)
+	if err != nil {
+		return m, err
+	}
+	s.traceMsg(m)
+	return m, nil
+}
+
+// NextMsgWithContext calls nats.Subscription.NextMsgWithContext and traces
+// the message, if any.
+func (s *Subscription) NextMsgWithContext(ctx context.Context) (*nats.Msg, error) {
+	m, err := s.Subscription.NextMsgWithContext(ctx)
+	if err != nil {
+		return m, err
+	}
+	s.traceMsg(m)
+	return m, nil
+}
+
+// Fetch calls nats.Subscription.Fetch and traces the fetched messages. It is
+// meant for the pull subscriptions of JetStreamContext.PullSubscribe.
+func (s *Subscription) Fetch(batch int, opts ...nats.PullOpt) ([]*nats.Msg, error) {
+	msgs, err := s.Subscription.Fetch(batch, opts...)
+	for _, m := range msgs {
+		s.traceMsg(m)
+	}
+	return msgs, err
+}
+
+func (s *Subscription) traceMsg(m *nats.Msg) {
+	span := startConsumerSpan(s.cfg, m)
+	setConsumeCheckpoint(s.cfg.dataStreamsEnabled, s.queue, m)
+	span.Finish()
+}
+
+// WrapMsgHandler wraps a nats.MsgHandler so that the messages it handles are
+// traced, for the subscriptions which aren't made through a Conn or a
+// JetStreamContext. The span context is reinjected into the message headers,
+// so that the handler can start child spans from ExtractSpanContext.
+func WrapMsgHandler(cb nats.MsgHandler, opts ...Option) nats.MsgHandler {
+	cfg := newConfig(opts...)
+	log.Debug("contrib/nats-io/nats.go: Wrapping MsgHandler: %#v", cfg)
+	return wrapMsgHandler(cfg, "", cb)
+}
+
+func wrapMsgHandler(cfg *config, queue string, cb nats.MsgHandler) nats.MsgHandler {
+	return func(m *nats.Msg) {
+		span := startConsumerSpan(cfg, m)
+		setConsumeCheckpoint(cfg.dataStreamsEnabled, queue, m)
+		defer span.Finish()
+		cb(m)
+	}
+}
+
+func startProducerSpan(ctx context.Context, cfg *config, m *nats.Msg) ddtrace.Span {
+	opts := []tracer.StartSpanOption{
+		tracer.ServiceName(cfg.producerServiceName),
+		tracer.ResourceName("Publish " + m.Subject),
+		tracer.SpanType(ext.SpanTypeMessageProducer),
+		tracer.Tag(ext.Component, componentName),
+		tracer.Tag(ext.SpanKind, ext.SpanKindProducer),
+		tracer.Tag(ext.MessagingSystem, ext.MessagingSystemNATS),
+		tracer.Tag(ext.NATSSubject, m.Subject),
+	}
+	if !math.IsNaN(cfg.analyticsRate) {
+		opts = append(opts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
+	}
+	span, _ := tracer.StartSpanFromContext(ctx, cfg.producerSpanName, opts...)
+	carrier := messageCarrier{m}
+	if err := tracer.Inject(span.Context(), carrier); err != nil {
+		log.Debug("contrib/nats-io/nats.go: Failed to inject span context into carrier, %v", err)
+	}
+	setProduceCheckpoint(ctx, cfg.dataStreamsEnabled, m)
+	return span
+}
+
+func startConsumerSpan(cfg *config, m *nats.Msg) ddtrace.Span {
+	opts := []tracer.StartSpanOption{
+		tracer.ServiceName(cfg.consumerServiceName),
+		tracer.ResourceName("Consume " + m.Subject),
+		tracer.SpanType(ext.SpanTypeMessageConsumer),
+		tracer.Tag(ext.Component, componentName),
+		tracer.Tag(ext.SpanKind, ext.SpanKindConsumer),
+		tracer.Tag(ext.MessagingSystem, ext.MessagingSystemNATS),
+		tracer.Tag(ext.NATSSubject, m.Subject),
+		tracer.Measured(),
+	}
+	if md, err := m.Metadata(); err == nil {
+		// the message was delivered by a JetStream consumer
+		opts = append(opts, tracer.Tag(ext.NATSStream, md.Stream))
+	}
+	if !math.IsNaN(cfg.analyticsRate) {
+		opts = append(opts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
+	}
+	carrier := messageCarrier{m}
+	if spanctx, err := tracer.Extract(carrier); err == nil {
+		opts = append(opts, tracer.ChildOf(spanctx))
+	}
+	span := tracer.StartSpan(cfg.consumerSpanName, opts...)
+	// reinject the span context so handlers can pick it up
+	if err := tracer.Inject(span.Context(), carrier); err != nil {
+		log.Debug("contrib/nats-io/nats.go: Failed to inject span context into carrier, %v", err)
+	}
+	return span
+}
+
+func setProduceCheckpoint(ctx context.Context, enabled bool, m *nats.Msg) {
+	if !enabled {
+		return
+	}
+	carrier := messageCarrier{m}
+	ctx, ok := tracer.SetDataStreamsCheckpointWithParams(
+		datastreams.ExtractFromBase64Carrier(ctx, carrier),
+		options.CheckpointParams{PayloadSize: getMsgSize(m)},
+		"direction:out", "topic:"+m.Subject, "type:nats",
+	)
+	if !ok {
+		return
+	}
+	datastreams.InjectToBase64Carrier(ctx, carrier)
+}
+
+func setConsumeCheckpoint(enabled bool, queue string, m *nats.Msg) {
+	if !enabled {
+		return
+	}
+	edges := []string{"direction:in", "topic:" + m.Subject, "type:nats"}
+	if queue != "" {
+		edges = append(edges, "group:"+queue)
+	}
+	carrier := messageCarrier{m}
+	ctx, ok := tracer.SetDataStreamsCheckpointWithParams(
+		datastreams.ExtractFromBase64Carrier(context.Background(), carrier),
+		options.CheckpointParams{PayloadSize: getMsgSize(m)},
+		edges...,
+	)
+	if !ok {
+		return
+	}
+	datastreams.InjectToBase64Carrier(ctx, carrier)
+}
+
+func getMsgSize(m *nats.Msg) (size int64) {
+	for k, vals := range m.Header {
+		for _, v := range vals {
+			size += int64(len(k) + len(v))
+		}
+	}
+	return size + int64(len(m.Data))
+}
@@ -0,0 +1,276 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package nats
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/contrib/internal/namingschematest"
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSubject  = "orders.created"
+	testStream   = "ORDERS"
+	testConsumer = "processor"
+)
+
+func newTestConn(t *testing.T, opts ...Option) *Conn {
+	srv := newTestServer(t, testStream, testSubject, testConsumer)
+	nc, err := nats.Connect("", nats.InProcessServer(srv))
+	require.NoError(t, err)
+	t.Cleanup(nc.Close)
+	return WrapConn(nc, opts...)
+}
+
+// waitSpans waits for n spans to be finished and returns them with the
+// producer spans first.
+func waitSpans(t *testing.T, mt mocktracer.Tracer, n int) []mocktracer.Span {
+	t.Helper()
+	require.Eventually(t, func() bool { return len(mt.FinishedSpans()) >= n }, 5*time.Second, 10*time.Millisecond)
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, n)
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].Tag(ext.SpanKind) == ext.SpanKindProducer && spans[j].Tag(ext.SpanKind) != ext.SpanKindProducer
+	})
+	return spans
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	c := newTestConn(t, WithAnalytics(true))
+	msgs := make(chan *nats.Msg, 1)
+	_, err := c.Subscribe(testSubject, func(m *nats.Msg) { msgs <- m })
+	require.NoError(t, err)
+
+	parent, ctx := tracer.StartSpanFromContext(context.Background(), "parent")
+	require.NoError(t, c.PublishMsgWithContext(ctx, &nats.Msg{Subject: testSubject, Data: []byte("order")}))
+	parent.Finish()
+
+	msg := <-msgs
+	assert.Equal(t, []byte("order"), msg.Data)
+	spans := waitSpans(t, mt, 3)
+	produce, consume := spans[0], spans[2]
+	assert.Equal(t, "parent", spans[1].OperationName())
+
+	assert.Equal(t, "nats.publish", produce.OperationName())
+	assert.Equal(t, parent.Context().SpanID(), produce.ParentID())
+	assert.Equal(t, "nats", produce.Tag(ext.ServiceName))
+	assert.Equal(t, "Publish "+testSubject, produce.Tag(ext.ResourceName))
+	assert.Equal(t, ext.SpanTypeMessageProducer, produce.Tag(ext.SpanType))
+	assert.Equal(t, ext.SpanKindProducer, produce.Tag(ext.SpanKind))
+	assert.Equal(t, ext.MessagingSystemNATS, produce.Tag(ext.MessagingSystem))
+	assert.Equal(t, componentName, produce.Tag(ext.Component))
+	assert.Equal(t, testSubject, produce.Tag(ext.NATSSubject))
+	assert.Equal(t, 1.0, produce.Tag(ext.EventSampleRate))
+
+	assert.Equal(t, "nats.consume", consume.OperationName())
+	assert.Equal(t, produce.SpanID(), consume.ParentID())
+	assert.Equal(t, produce.TraceID(), consume.TraceID())
+	assert.Equal(t, "Consume "+testSubject, consume.Tag(ext.ResourceName))
+	assert.Equal(t, ext.SpanTypeMessageConsumer, consume.Tag(ext.SpanType))
+	assert.Equal(t, ext.SpanKindConsumer, consume.Tag(ext.SpanKind))
+	assert.Equal(t, ext.MessagingSystemNATS, consume.Tag(ext.MessagingSystem))
+	assert.Equal(t, componentName, consume.Tag(ext.Component))
+	assert.Equal(t, testSubject, consume.Tag(ext.NATSSubject))
+
+	// the consume span context is reinjected for the handler
+	spanctx, err := ExtractSpanContext(msg)
+	require.NoError(t, err)
+	assert.Equal(t, consume.SpanID(), spanctx.SpanID())
+}
+
+func TestWrapMsgHandler(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	c := newTestConn(t)
+	msgs := make(chan *nats.Msg, 1)
+	_, err := c.Conn.Subscribe(testSubject, WrapMsgHandler(func(m *nats.Msg) { msgs <- m }, WithServiceName("consumer")))
+	require.NoError(t, err)
+	require.NoError(t, c.Publish(testSubject, []byte("order")))
+	<-msgs
+
+	spans := waitSpans(t, mt, 2)
+	assert.Equal(t, "nats", spans[0].Tag(ext.ServiceName))
+	assert.Equal(t, "consumer", spans[1].Tag(ext.ServiceName))
+	assert.Equal(t, spans[0].SpanID(), spans[1].ParentID())
+}
+
+func TestJetStreamPublish(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	js, err := newTestConn(t).JetStream()
+	require.NoError(t, err)
+	ack, err := js.Publish(testSubject, []byte("order"))
+	require.NoError(t, err)
+	assert.Equal(t, testStream, ack.Stream)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "nats.publish", spans[0].OperationName())
+	assert.Equal(t, testStream, spans[0].Tag(ext.NATSStream))
+	assert.Nil(t, spans[0].Tag(ext.Error))
+}
+
+func TestRequest(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	c := newTestConn(t)
+	// not a stream subject, so that the responder is the only one to reply
+	const subject = "orders.get"
+	_, err := c.Subscribe(subject, func(m *nats.Msg) { m.Respond([]byte("order")) })
+	require.NoError(t, err)
+
+	parent, ctx := tracer.StartSpanFromContext(context.Background(), "parent")
+	resp, err := c.RequestWithContext(ctx, subject, []byte("id"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("order"), resp.Data)
+	parent.Finish()
+	resp, err = c.Request(subject, []byte("id"), time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("order"), resp.Data)
+
+	spans := waitSpans(t, mt, 5)
+	assert.Equal(t, "nats.publish", spans[0].OperationName())
+	assert.Equal(t, parent.Context().SpanID(), spans[0].ParentID())
+	assert.Equal(t, "nats.publish", spans[1].OperationName())
+	assert.Equal(t, subject, spans[1].Tag(ext.NATSSubject))
+	assert.Nil(t, spans[1].Tag(ext.Error))
+}
+
+func TestSubscribeSync(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	c := newTestConn(t)
+	sub, err := c.QueueSubscribeSync(testSubject, "workers")
+	require.NoError(t, err)
+	require.NoError(t, c.Publish(testSubject, []byte("order")))
+	msg, err := sub.NextMsg(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("order"), msg.Data)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	produce, consume := spans[0], spans[1]
+	assert.Equal(t, "nats.consume", consume.OperationName())
+	assert.Equal(t, produce.SpanID(), consume.ParentID())
+	spanctx, err := ExtractSpanContext(msg)
+	require.NoError(t, err)
+	assert.Equal(t, consume.SpanID(), spanctx.SpanID())
+
+	_, err = sub.NextMsg(time.Millisecond)
+	assert.Equal(t, nats.ErrTimeout, err)
+	assert.Len(t, mt.FinishedSpans(), 2)
+}
+
+func TestJetStreamPublishAsync(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	js, err := newTestConn(t).JetStream()
+	require.NoError(t, err)
+	future, err := js.PublishAsync(testSubject, []byte("order"))
+	require.NoError(t, err)
+	select {
+	case ack := <-future.Ok():
+		assert.Equal(t, testStream, ack.Stream)
+	case err := <-future.Err():
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the message was not acknowledged")
+	}
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "nats.publish", spans[0].OperationName())
+	assert.Equal(t, "Publish "+testSubject, spans[0].Tag(ext.ResourceName))
+}
+
+func TestJetStreamPullSubscribe(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	js, err := newTestConn(t, WithDataStreams()).JetStream()
+	require.NoError(t, err)
+	sub, err := js.PullSubscribe(testSubject, testConsumer, nats.Bind(testStream, testConsumer), nats.SkipConsumerLookup())
+	require.NoError(t, err)
+	_, err = js.Publish(testSubject, []byte("order"))
+	require.NoError(t, err)
+	msgs, err := sub.Fetch(1, nats.MaxWait(time.Second))
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, []byte("order"), msgs[0].Data)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	produce, consume := spans[0], spans[1]
+	assert.Equal(t, "nats.consume", consume.OperationName())
+	assert.Equal(t, produce.SpanID(), consume.ParentID())
+	assert.Equal(t, testStream, consume.Tag(ext.NATSStream))
+
+	p, ok := datastreams.PathwayFromContext(datastreams.ExtractFromBase64Carrier(context.Background(), messageCarrier{msgs[0]}))
+	require.True(t, ok)
+	expectedCtx, _ := tracer.SetDataStreamsCheckpoint(context.Background(), "direction:out", "topic:"+testSubject, "type:nats")
+	expectedCtx, _ = tracer.SetDataStreamsCheckpoint(expectedCtx, "direction:in", "topic:"+testSubject, "type:nats")
+	expected, _ := datastreams.PathwayFromContext(expectedCtx)
+	assert.Equal(t, expected.GetHash(), p.GetHash())
+}
+
+func TestDataStreams(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	c := newTestConn(t, WithDataStreams())
+	msgs := make(chan *nats.Msg, 1)
+	_, err := c.QueueSubscribe(testSubject, "workers", func(m *nats.Msg) { msgs <- m })
+	require.NoError(t, err)
+	require.NoError(t, c.Publish(testSubject, []byte("order")))
+	msg := <-msgs
+
+	p, ok := datastreams.PathwayFromContext(datastreams.ExtractFromBase64Carrier(context.Background(), messageCarrier{msg}))
+	require.True(t, ok)
+	expectedCtx, _ := tracer.SetDataStreamsCheckpoint(context.Background(), "direction:out", "topic:"+testSubject, "type:nats")
+	expectedCtx, _ = tracer.SetDataStreamsCheckpoint(expectedCtx, "direction:in", "topic:"+testSubject, "type:nats", "group:workers")
+	expected, _ := datastreams.PathwayFromContext(expectedCtx)
+	assert.NotEqual(t, uint64(0), expected.GetHash())
+	assert.Equal(t, expected.GetHash(), p.GetHash())
+}
+
+func TestNamingSchema(t *testing.T) {
+	genSpans := func(t *testing.T, serviceOverride string) []mocktracer.Span {
+		var opts []Option
+		if serviceOverride != "" {
+			opts = append(opts, WithServiceName(serviceOverride))
+		}
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		c := newTestConn(t, opts...)
+		done := make(chan struct{})
+		_, err := c.Subscribe(testSubject, func(*nats.Msg) { close(done) })
+		require.NoError(t, err)
+		require.NoError(t, c.Publish(testSubject, []byte("order")))
+		<-done
+		return waitSpans(t, mt, 2)
+	}
+	namingschematest.NewNATSTest(genSpans)(t)
+}
@@ -28,6 +28,10 @@ type config struct {
 	ignoreRequest func(*http.Request) bool
 	resourceNamer func(*http.Request) string
 	headerTags    *internal.LockMap
+	// responseBodyLimit is the maximum size of the response bodies inspected by AppSec.
+	responseBodyLimit int
+	// dataStreams enables the Data Streams Monitoring checkpoints of the requests.
+	dataStreams bool
 }
 
 // MuxOption has been deprecated in favor of Option.
@@ -127,6 +131,28 @@ func NoDebugStack() Option {
 	}
 }
 
+// WithResponseBodyInspection enables the inspection of the response bodies by
+// AppSec, when it is enabled. JSON and URL-encoded form responses whose body
+// is at most limit bytes long are held back until the handler returns, so that
+// they can be blocked or redacted before being sent to the client. Other
+// responses are sent as usual, and so are responses flushed by the handler.
+// Note that holding back responses adds up to limit bytes of memory per
+// request. It is disabled by default.
+func WithResponseBodyInspection(limit int) Option {
+	return func(cfg *config) {
+		cfg.responseBodyLimit = limit
+	}
+}
+
+// WithDataStreams enables the Data Streams Monitoring inbound checkpoints of
+// the requests, so that the pathways propagated by the clients flow through
+// the handler.
+func WithDataStreams() Option {
+	return func(cfg *config) {
+		cfg.dataStreams = true
+	}
+}
+
 // A RoundTripperBeforeFunc can be used to modify a span before an http
 // RoundTrip is made.
 type RoundTripperBeforeFunc func(*http.Request, ddtrace.Span)
@@ -146,6 +172,7 @@ type roundTripperConfig struct {
 	spanOpts      []ddtrace.StartSpanOption
 	propagation   bool
 	errCheck      func(err error) bool
+	dataStreams   bool
 }
 
 func newRoundTripperConfig() *roundTripperConfig {
@@ -248,6 +275,15 @@ func RTWithPropagation(propagation bool) RoundTripperOption {
 	}
 }
 
+// RTWithDataStreams enables the Data Streams Monitoring outbound checkpoints
+// of the requests, and the propagation of the resulting pathways in their
+// headers.
+func RTWithDataStreams() RoundTripperOption {
+	return func(cfg *roundTripperConfig) {
+		cfg.dataStreams = true
+	}
+}
+
 // RTWithIgnoreRequest holds the function to use for determining if the
 // outgoing HTTP request should not be traced.
 func RTWithIgnoreRequest(f func(*http.Request) bool) RoundTripperOption {
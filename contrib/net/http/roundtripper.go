@@ -13,6 +13,7 @@ import (
 	"os"
 	"strconv"
 
+	"gopkg.in/DataDog/dd-trace-go.v1/contrib/internal/httptrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
@@ -78,6 +79,9 @@ func (rt *roundTripper) RoundTrip(req *http.Request) (res *http.Response, err er
 			fmt.Fprintf(os.Stderr, "contrib/net/http.Roundtrip: failed to inject http headers: %v\n", err)
 		}
 	}
+	if rt.cfg.dataStreams {
+		httptrace.SetDataStreamsClientCheckpoint(r2)
+	}
 
 	if appsec.RASPEnabled() {
 		if err := httpsec.ProtectRoundTrip(ctx, r2.URL.String()); err != nil {
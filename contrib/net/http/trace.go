@@ -46,6 +46,13 @@ type ServeConfig struct {
 	FinishOpts []ddtrace.FinishOption
 	// SpanOpts specifies any options to be applied to the request starting span.
 	SpanOpts []ddtrace.StartSpanOption
+	// ResponseBodyLimit enables the inspection of the response bodies by AppSec when greater than zero. JSON and
+	// URL-encoded form responses whose body is at most ResponseBodyLimit bytes long are then held back until the
+	// handler returns, so that they can be blocked or redacted. It is only taken into account when AppSec is enabled.
+	ResponseBodyLimit int
+	// DataStreams enables the Data Streams Monitoring inbound checkpoint of the request, continuing the pathway
+	// propagated in its headers, if any.
+	DataStreams bool
 }
 
 // TraceAndServe serves the handler h using the given ResponseWriter and Request, applying tracing
@@ -65,13 +72,22 @@ func TraceAndServe(h http.Handler, w http.ResponseWriter, r *http.Request, cfg *
 		opts = append(opts, tracer.Tag(ext.HTTPRoute, cfg.Route))
 	}
 	span, ctx := httptrace.StartRequestSpan(r, opts...)
+	if cfg.DataStreams {
+		ctx = httptrace.SetDataStreamsServerCheckpoint(ctx, r)
+	}
 	rw, ddrw := wrapResponseWriter(w)
 	defer func() {
 		httptrace.FinishRequestSpan(span, ddrw.status, cfg.FinishOpts...)
 	}()
 
 	if appsec.Enabled() {
-		h = httpsec.WrapHandler(h, span, cfg.RouteParams, nil)
+		secCfg := &httpsec.Config{Route: cfg.Route}
+		if cfg.ResponseBodyLimit > 0 {
+			buf := httptrace.NewResponseBodyBuffer(rw, cfg.ResponseBodyLimit)
+			rw = buf
+			secCfg.ResponseBody = buf
+		}
+		h = httpsec.WrapHandler(h, span, cfg.RouteParams, secCfg)
 	}
 	h.ServeHTTP(rw, r.WithContext(ctx))
 }
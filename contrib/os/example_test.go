@@ -0,0 +1,33 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package os_test
+
+import (
+	"net/http"
+	"path/filepath"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec/events"
+	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
+	ostrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/os"
+)
+
+func ExampleReadFile() {
+	mux := httptrace.NewServeMux()
+	mux.HandleFunc("/file", func(w http.ResponseWriter, r *http.Request) {
+		name := filepath.Join("/var/www", r.URL.Query().Get("name"))
+		data, err := ostrace.ReadFile(r.Context(), name)
+		if events.IsSecurityError(err) {
+			// The request was blocked by appsec, which already responded to it.
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	})
+	http.ListenAndServe(":8080", mux)
+}
@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package exec provides a drop-in replacement of exec.CommandContext
+// protecting the started processes against command and shell injection
+// attacks when Application Security Management and its RASP feature are
+// enabled.
+//
+// Commands are checked right before the process is started, so changes made to
+// the command's Args field after its creation are taken into account. When the
+// command is blocked, the process is not started and the returned error is an
+// *events.BlockingSecurityEvent, which can be checked with
+// events.IsSecurityError. The request handler must then be aborted.
+//
+// The context passed to CommandContext must be the context of the monitored
+// request (or derived from it), otherwise the command is not monitored.
+package exec // import "gopkg.in/DataDog/dd-trace-go.v1/contrib/os/exec"
+
+import (
+	"context"
+	"os/exec"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/ossec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
+)
+
+const componentName = "os/exec"
+
+func init() {
+	telemetry.LoadIntegration(componentName)
+	tracer.MarkIntegrationImported(componentName)
+}
+
+// Cmd is an exec.Cmd whose process is checked against command and shell
+// injections before being started.
+type Cmd struct {
+	*exec.Cmd
+	ctx context.Context
+}
+
+// CommandContext is a drop-in replacement of exec.CommandContext returning a
+// Cmd protected by the RASP command and shell injection checks.
+func CommandContext(ctx context.Context, name string, arg ...string) *Cmd {
+	return &Cmd{
+		Cmd: exec.CommandContext(ctx, name, arg...),
+		ctx: ctx,
+	}
+}
+
+// Start starts the command as exec.Cmd.Start does, unless the command is
+// blocked.
+func (c *Cmd) Start() error {
+	if err := c.checkSecurity(); err != nil {
+		return err
+	}
+	return c.Cmd.Start()
+}
+
+// Run starts the command and waits for it to complete as exec.Cmd.Run does,
+// unless the command is blocked.
+func (c *Cmd) Run() error {
+	if err := c.Start(); err != nil {
+		return err
+	}
+	return c.Wait()
+}
+
+// Output runs the command and returns its standard output as exec.Cmd.Output
+// does, unless the command is blocked.
+func (c *Cmd) Output() ([]byte, error) {
+	if err := c.checkSecurity(); err != nil {
+		return nil, err
+	}
+	return c.Cmd.Output()
+}
+
+// CombinedOutput runs the command and returns its combined standard output
+// and standard error as exec.Cmd.CombinedOutput does, unless the command is
+// blocked.
+func (c *Cmd) CombinedOutput() ([]byte, error) {
+	if err := c.checkSecurity(); err != nil {
+		return nil, err
+	}
+	return c.Cmd.CombinedOutput()
+}
+
+// checkSecurity runs the ASM RASP command and shell injection checks on the
+// command. If it's unsafe to run, an *events.BlockingSecurityEvent is returned.
+func (c *Cmd) checkSecurity() error {
+	if !appsec.RASPEnabled() {
+		return nil
+	}
+	return ossec.ProtectExec(c.ctx, c.Args)
+}
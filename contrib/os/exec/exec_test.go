@@ -0,0 +1,44 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package exec
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandContext(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Run", func(t *testing.T) {
+		var out bytes.Buffer
+		cmd := CommandContext(ctx, "sh", "-c", "echo hello")
+		cmd.Stdout = &out
+		require.NoError(t, cmd.Run())
+		require.Equal(t, "hello\n", out.String())
+	})
+
+	t.Run("Output", func(t *testing.T) {
+		out, err := CommandContext(ctx, "echo", "hello").Output()
+		require.NoError(t, err)
+		require.Equal(t, "hello\n", string(out))
+	})
+
+	t.Run("CombinedOutput", func(t *testing.T) {
+		out, err := CommandContext(ctx, "sh", "-c", "echo hello >&2").CombinedOutput()
+		require.NoError(t, err)
+		require.Equal(t, "hello\n", string(out))
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		require.Error(t, CommandContext(ctx, "sleep", "1").Run())
+	})
+}
@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package os provides drop-in replacements of the os package functions opening
+// files, protecting them against Local File Inclusion (LFI) attacks when
+// Application Security Management and its RASP feature are enabled.
+//
+// When the file opening is blocked, the returned error is an
+// *events.BlockingSecurityEvent and no file is opened. Blocking an attack
+// interrupts the file opening only: the request handler must still be aborted,
+// which can be checked with events.IsSecurityError.
+//
+// The context passed to these functions must be the context of the monitored
+// request (or derived from it), otherwise the file opening is not monitored.
+package os // import "gopkg.in/DataDog/dd-trace-go.v1/contrib/os"
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/ossec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
+)
+
+const componentName = "os"
+
+func init() {
+	telemetry.LoadIntegration(componentName)
+	tracer.MarkIntegrationImported(componentName)
+}
+
+// Open is a drop-in replacement of os.Open protected by the RASP LFI checks.
+func Open(ctx context.Context, name string) (*os.File, error) {
+	return OpenFile(ctx, name, os.O_RDONLY, 0)
+}
+
+// OpenFile is a drop-in replacement of os.OpenFile protected by the RASP LFI
+// checks.
+func OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (*os.File, error) {
+	if err := checkOpenSecurity(ctx, name, flag, perm); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(name, flag, perm)
+}
+
+// ReadFile is a drop-in replacement of os.ReadFile protected by the RASP LFI
+// checks.
+func ReadFile(ctx context.Context, name string) ([]byte, error) {
+	f, err := Open(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// checkOpenSecurity runs the ASM RASP LFI checks on the file about to be
+// opened. If it's unsafe to open it, an *events.BlockingSecurityEvent is returned.
+func checkOpenSecurity(ctx context.Context, name string, flag int, perm os.FileMode) error {
+	if !appsec.RASPEnabled() {
+		return nil
+	}
+	return ossec.ProtectOpen(ctx, name, flag, perm)
+}
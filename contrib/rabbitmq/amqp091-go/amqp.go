@@ -0,0 +1,258 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package amqp provides functions to trace the rabbitmq/amqp091-go package (https://github.com/rabbitmq/amqp091-go).
+package amqp // import "gopkg.in/DataDog/dd-trace-go.v1/contrib/rabbitmq/amqp091-go"
+
+import (
+	"context"
+	"math"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams"
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams/options"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const componentName = "rabbitmq/amqp091-go"
+
+func init() {
+	telemetry.LoadIntegration(componentName)
+	tracer.MarkIntegrationImported("github.com/rabbitmq/amqp091-go")
+}
+
+// A Channel wraps an amqp.Channel so that the published and consumed messages
+// are traced.
+type Channel struct {
+	*amqp.Channel
+	cfg *config
+}
+
+// WrapChannel wraps an amqp.Channel so that the published and consumed
+// messages are traced.
+func WrapChannel(ch *amqp.Channel, opts ...Option) *Channel {
+	c := &Channel{
+		Channel: ch,
+		cfg:     newConfig(opts...),
+	}
+	log.Debug("contrib/rabbitmq/amqp091-go: Wrapping Channel: %#v", c.cfg)
+	return c
+}
+
+// Publish calls amqp.Channel.PublishWithContext and traces the request.
+func (c *Channel) Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	return c.PublishWithContext(context.Background(), exchange, key, mandatory, immediate, msg)
+}
+
+// PublishWithContext calls amqp.Channel.PublishWithContext and traces the
+// request as a child of the span found in ctx, if any.
+func (c *Channel) PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	span := startProducerSpan(ctx, c.cfg, exchange, key, &msg)
+	err := c.Channel.PublishWithContext(ctx, exchange, key, mandatory, immediate, msg)
+	span.Finish(tracer.WithError(err))
+	return err
+}
+
+// PublishWithDeferredConfirmWithContext calls
+// amqp.Channel.PublishWithDeferredConfirmWithContext and traces the request as
+// a child of the span found in ctx, if any.
+func (c *Channel) PublishWithDeferredConfirmWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) (*amqp.DeferredConfirmation, error) {
+	span := startProducerSpan(ctx, c.cfg, exchange, key, &msg)
+	confirm, err := c.Channel.PublishWithDeferredConfirmWithContext(ctx, exchange, key, mandatory, immediate, msg)
+	span.Finish(tracer.WithError(err))
+	return confirm, err
+}
+
+// Consume calls amqp.Channel.Consume and traces the deliveries. The span of a
+// delivery is finished when the next delivery is received or when the
+// deliveries channel is closed.
+func (c *Channel) Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error) {
+	return c.ConsumeWithContext(context.Background(), queue, consumer, autoAck, exclusive, noLocal, noWait, args)
+}
+
+// ConsumeWithContext calls amqp.Channel.ConsumeWithContext and traces the
+// deliveries. The span of a delivery is finished when the next delivery is
+// received or when the deliveries channel is closed. The deliveries channel is
+// also closed once ctx is done.
+func (c *Channel) ConsumeWithContext(ctx context.Context, queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error) {
+	deliveries, err := c.Channel.ConsumeWithContext(ctx, queue, consumer, autoAck, exclusive, noLocal, noWait, args)
+	if err != nil {
+		return deliveries, err
+	}
+	return wrapDeliveries(ctx, c.cfg, queue, deliveries), nil
+}
+
+// Get calls amqp.Channel.Get and traces the delivery, if any.
+func (c *Channel) Get(queue string, autoAck bool) (amqp.Delivery, bool, error) {
+	d, ok, err := c.Channel.Get(queue, autoAck)
+	if err != nil || !ok {
+		return d, ok, err
+	}
+	span := startConsumerSpan(c.cfg, "basic.get", queue, &d)
+	setConsumeCheckpoint(c.cfg.dataStreamsEnabled, queue, &d)
+	span.Finish()
+	return d, ok, err
+}
+
+// wrapDeliveries traces the deliveries read from in until it is closed or ctx
+// is done, in which case the current delivery is dropped since nobody reads
+// the returned channel anymore.
+func wrapDeliveries(ctx context.Context, cfg *config, queue string, in <-chan amqp.Delivery) <-chan amqp.Delivery {
+	out := make(chan amqp.Delivery)
+	go func() {
+		var prev ddtrace.Span
+		defer func() {
+			// finish any remaining span
+			if prev != nil {
+				prev.Finish()
+			}
+			close(out)
+		}()
+		for d := range in {
+			next := startConsumerSpan(cfg, "basic.deliver", queue, &d)
+			setConsumeCheckpoint(cfg.dataStreamsEnabled, queue, &d)
+
+			select {
+			case out <- d:
+			case <-ctx.Done():
+				next.Finish()
+				return
+			}
+
+			// if the next delivery was received, finish the previous span
+			if prev != nil {
+				prev.Finish()
+			}
+			prev = next
+		}
+	}()
+	return out
+}
+
+func startProducerSpan(ctx context.Context, cfg *config, exchange, key string, msg *amqp.Publishing) ddtrace.Span {
+	opts := []tracer.StartSpanOption{
+		tracer.ServiceName(cfg.producerServiceName),
+		tracer.ResourceName("basic.publish " + exchangeName(exchange) + " -> " + key),
+		tracer.SpanType(ext.SpanTypeMessageProducer),
+		tracer.Tag(ext.Component, componentName),
+		tracer.Tag(ext.SpanKind, ext.SpanKindProducer),
+		tracer.Tag(ext.MessagingSystem, ext.MessagingSystemRabbitMQ),
+		tracer.Tag(ext.RabbitMQExchange, exchange),
+		tracer.Tag(ext.RabbitMQRoutingKey, key),
+	}
+	if !math.IsNaN(cfg.analyticsRate) {
+		opts = append(opts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
+	}
+	span, _ := tracer.StartSpanFromContext(ctx, cfg.producerSpanName, opts...)
+	// copy the headers so that the injected ones aren't shared between the
+	// messages published with the same table
+	headers := make(amqp.Table, len(msg.Headers)+1)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	msg.Headers = headers
+	carrier := tableCarrier(msg.Headers)
+	if err := tracer.Inject(span.Context(), carrier); err != nil {
+		log.Debug("contrib/rabbitmq/amqp091-go: Failed to inject span context into carrier, %v", err)
+	}
+	setProduceCheckpoint(ctx, cfg.dataStreamsEnabled, exchange, key, msg)
+	return span
+}
+
+// startConsumerSpan starts the span of a delivery received through the given
+// AMQP method, basic.deliver or basic.get.
+func startConsumerSpan(cfg *config, method, queue string, d *amqp.Delivery) ddtrace.Span {
+	opts := []tracer.StartSpanOption{
+		tracer.ServiceName(cfg.consumerServiceName),
+		tracer.ResourceName(method + " " + queue),
+		tracer.SpanType(ext.SpanTypeMessageConsumer),
+		tracer.Tag(ext.Component, componentName),
+		tracer.Tag(ext.SpanKind, ext.SpanKindConsumer),
+		tracer.Tag(ext.MessagingSystem, ext.MessagingSystemRabbitMQ),
+		tracer.Tag(ext.RabbitMQExchange, d.Exchange),
+		tracer.Tag(ext.RabbitMQRoutingKey, d.RoutingKey),
+		tracer.Tag(ext.RabbitMQQueue, queue),
+		tracer.Measured(),
+	}
+	if !math.IsNaN(cfg.analyticsRate) {
+		opts = append(opts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
+	}
+	if d.Headers == nil {
+		d.Headers = amqp.Table{}
+	}
+	carrier := tableCarrier(d.Headers)
+	if spanctx, err := tracer.Extract(carrier); err == nil {
+		opts = append(opts, tracer.ChildOf(spanctx))
+	}
+	span := tracer.StartSpan(cfg.consumerSpanName, opts...)
+	// reinject the span context so consumers can pick it up
+	if err := tracer.Inject(span.Context(), carrier); err != nil {
+		log.Debug("contrib/rabbitmq/amqp091-go: Failed to inject span context into carrier, %v", err)
+	}
+	return span
+}
+
+func setProduceCheckpoint(ctx context.Context, enabled bool, exchange, key string, msg *amqp.Publishing) {
+	if !enabled {
+		return
+	}
+	hasRoutingKey := "false"
+	if key != "" {
+		hasRoutingKey = "true"
+	}
+	carrier := tableCarrier(msg.Headers)
+	ctx, ok := tracer.SetDataStreamsCheckpointWithParams(
+		datastreams.ExtractFromBase64Carrier(ctx, carrier),
+		options.CheckpointParams{PayloadSize: getMsgSize(msg.Headers, msg.Body)},
+		"direction:out", "exchange:"+exchange, "has_routing_key:"+hasRoutingKey, "type:rabbitmq",
+	)
+	if !ok {
+		return
+	}
+	datastreams.InjectToBase64Carrier(ctx, carrier)
+}
+
+func setConsumeCheckpoint(enabled bool, queue string, d *amqp.Delivery) {
+	if !enabled {
+		return
+	}
+	carrier := tableCarrier(d.Headers)
+	ctx, ok := tracer.SetDataStreamsCheckpointWithParams(
+		datastreams.ExtractFromBase64Carrier(context.Background(), carrier),
+		options.CheckpointParams{PayloadSize: getMsgSize(d.Headers, d.Body)},
+		"direction:in", "topic:"+queue, "type:rabbitmq",
+	)
+	if !ok {
+		return
+	}
+	datastreams.InjectToBase64Carrier(ctx, carrier)
+}
+
+// exchangeName returns the name of the given exchange, as displayed in the
+// resource names.
+func exchangeName(exchange string) string {
+	if exchange == "" {
+		return "<default>"
+	}
+	return exchange
+}
+
+func getMsgSize(headers amqp.Table, body []byte) (size int64) {
+	for k, v := range headers {
+		size += int64(len(k))
+		switch v := v.(type) {
+		case string:
+			size += int64(len(v))
+		case []byte:
+			size += int64(len(v))
+		}
+	}
+	return size + int64(len(body))
+}
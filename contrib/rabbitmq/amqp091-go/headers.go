@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package amqp
+
+import (
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// A tableCarrier implements TextMapReader/TextMapWriter for extracting/injecting traces on the headers of an AMQP message
+type tableCarrier amqp.Table
+
+var _ interface {
+	tracer.TextMapReader
+	tracer.TextMapWriter
+} = (*tableCarrier)(nil)
+
+// ForeachKey conforms to the TextMapReader interface.
+func (c tableCarrier) ForeachKey(handler func(key, val string) error) error {
+	for k, v := range c {
+		var val string
+		switch v := v.(type) {
+		case string:
+			val = v
+		case []byte:
+			val = string(v)
+		default:
+			continue
+		}
+		if err := handler(k, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Set implements TextMapWriter
+func (c tableCarrier) Set(key, val string) {
+	c[key] = val
+}
+
+// ExtractSpanContext retrieves the SpanContext from the headers of an amqp.Delivery
+func ExtractSpanContext(d amqp.Delivery) (ddtrace.SpanContext, error) {
+	return tracer.Extract(tableCarrier(d.Headers))
+}
@@ -0,0 +1,30 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package zerolog_test
+
+import (
+	"context"
+	"os"
+
+	zerologtrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/rs/zerolog"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+
+	"github.com/rs/zerolog"
+)
+
+func ExampleNewHook() {
+	tracer.Start()
+	defer tracer.Stop()
+
+	// Setup zerolog, do this once at the beginning of your program
+	logger := zerolog.New(os.Stdout).Hook(zerologtrace.NewHook())
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "mySpan")
+	defer span.Finish()
+
+	// Pass the current span context to the logger
+	logger.Info().Ctx(ctx).Msg("Completed some work!")
+}
@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package zerolog
+
+import (
+	"github.com/rs/zerolog"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/contrib/internal/logcorrelation"
+)
+
+type config struct {
+	use128BitTraceID bool
+	serviceTags      logcorrelation.ServiceTags
+	errorLevel       zerolog.Level
+}
+
+// Option represents an option that can be passed to NewHook.
+type Option func(*config)
+
+func defaults(cfg *config) {
+	cfg.use128BitTraceID = true
+	cfg.serviceTags = logcorrelation.DefaultServiceTags()
+	cfg.errorLevel = zerolog.Disabled
+}
+
+// With128BitTraceID specifies whether the trace ID of spans carrying a
+// 128-bit trace ID should be logged in its 128-bit hex-encoded form. If
+// disabled, or if the trace ID only has 64 bits, the lower 64 bits are
+// logged in decimal form. It is enabled by default.
+func With128BitTraceID(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.use128BitTraceID = enabled
+	}
+}
+
+// WithService sets the service name added to log events. By default the
+// service name configured on the tracer is used, or DD_SERVICE if not set.
+func WithService(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceTags.Service = name
+	}
+}
+
+// WithEnv sets the environment added to log events. It defaults to the value
+// of the DD_ENV environment variable.
+func WithEnv(env string) Option {
+	return func(cfg *config) {
+		cfg.serviceTags.Env = env
+	}
+}
+
+// WithVersion sets the service version added to log events. It defaults to
+// the value of the DD_VERSION environment variable.
+func WithVersion(version string) Option {
+	return func(cfg *config) {
+		cfg.serviceTags.Version = version
+	}
+}
+
+// WithErrorLevel marks the span of the event's context as errored whenever an
+// event at or above the given level is logged. The error attached to the span
+// holds the event's message. By default spans are never marked as errored.
+func WithErrorLevel(level zerolog.Level) Option {
+	return func(cfg *config) {
+		cfg.errorLevel = level
+	}
+}
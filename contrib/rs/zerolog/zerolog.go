@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package zerolog provides a log/span correlation hook for the rs/zerolog package (https://github.com/rs/zerolog).
+package zerolog
+
+import (
+	"errors"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/contrib/internal/logcorrelation"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
+
+	"github.com/rs/zerolog"
+)
+
+const componentName = "rs/zerolog"
+
+func init() {
+	telemetry.LoadIntegration(componentName)
+	tracer.MarkIntegrationImported("github.com/rs/zerolog")
+}
+
+// NewHook returns a zerolog.Hook which adds the trace and unified service
+// tagging information of the span found in the event's context, as set with
+// zerolog.Event.Ctx or zerolog.Context.Ctx, to the logged events.
+func NewHook(opts ...Option) zerolog.Hook {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/rs/zerolog: Configuring Hook: %#v", cfg)
+	return &hook{cfg: cfg}
+}
+
+type hook struct {
+	cfg *config
+}
+
+// Run implements zerolog.Hook.
+func (h *hook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	span, ok := tracer.SpanFromContext(e.GetCtx())
+	if !ok {
+		return
+	}
+	ctx := span.Context()
+	e.Str(logcorrelation.KeyTraceID, logcorrelation.TraceID(ctx, h.cfg.use128BitTraceID))
+	e.Str(logcorrelation.KeySpanID, logcorrelation.SpanID(ctx))
+	for _, tag := range h.cfg.serviceTags.Tags() {
+		e.Str(tag.Key, tag.Value)
+	}
+	if h.cfg.errorLevel != zerolog.Disabled && level >= h.cfg.errorLevel && level != zerolog.NoLevel {
+		span.SetTag(ext.Error, errors.New(msg))
+	}
+}
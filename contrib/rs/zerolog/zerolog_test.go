@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package zerolog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHook(t *testing.T) {
+	tracer.Start(tracer.WithLogger(testLogger{}))
+	defer tracer.Stop()
+
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf).Hook(NewHook(WithService("svc"), WithEnv("prod"), WithVersion("1.2.3")))
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "test")
+	defer span.Finish()
+
+	logger.Info().Ctx(ctx).Msg("with span")
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	assert.Equal(t, span.Context().(ddtrace.SpanContextW3C).TraceID128(), m["dd.trace_id"])
+	assert.Equal(t, strconv.FormatUint(span.Context().SpanID(), 10), m["dd.span_id"])
+	assert.Equal(t, "svc", m["dd.service"])
+	assert.Equal(t, "prod", m["dd.env"])
+	assert.Equal(t, "1.2.3", m["dd.version"])
+
+	buf.Reset()
+	logger.Info().Msg("without span")
+	assert.NotContains(t, buf.String(), "dd.trace_id")
+
+	buf.Reset()
+	logger = zerolog.New(&buf).Hook(NewHook(With128BitTraceID(false)))
+	logger.Info().Ctx(ctx).Msg("64-bit")
+	m = nil
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+	assert.Equal(t, strconv.FormatUint(span.Context().TraceID(), 10), m["dd.trace_id"])
+}
+
+func TestErrorLevel(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf).Hook(NewHook(WithErrorLevel(zerolog.ErrorLevel)))
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "warn")
+	logger.Warn().Ctx(ctx).Msg("not an error")
+	span.Finish()
+
+	span, ctx = tracer.StartSpanFromContext(context.Background(), "error")
+	logger.Error().Ctx(ctx).Msg("something failed")
+	span.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	assert.Nil(t, spans[0].Tag("error"))
+	assert.EqualError(t, spans[1].Tag("error").(error), "something failed")
+}
+
+type testLogger struct{}
+
+func (testLogger) Log(string) {}
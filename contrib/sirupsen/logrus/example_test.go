@@ -32,3 +32,21 @@ func ExampleHook() {
 	// Output:
 	// {"dd.span_id":0,"dd.trace_id":0,"level":"info","msg":"Completed some work!","time":"2000-01-01T01:01:01Z"}
 }
+
+func ExampleNewHook() {
+	// Ensure your tracer is started and stopped
+	// Setup logrus, do this once at the beginning of your program
+	logrus.SetFormatter(&logrus.JSONFormatter{})
+	logrus.AddHook(NewHook(
+		// Add the service name, instead of the one configured on the tracer
+		WithService("my-service"),
+		// Mark spans as errored when an error entry is logged
+		WithErrorLevel(logrus.ErrorLevel),
+	))
+	logrus.SetOutput(os.Stdout)
+
+	span, sctx := tracer.StartSpanFromContext(context.Background(), "mySpan")
+	defer span.Finish()
+
+	logrus.WithContext(sctx).Info("Completed some work!")
+}
@@ -7,7 +7,12 @@
 package logrus
 
 import (
+	"errors"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/contrib/internal/logcorrelation"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/telemetry"
 
 	"github.com/sirupsen/logrus"
@@ -21,11 +26,25 @@ func init() {
 }
 
 // DDContextLogHook ensures that any span in the log context is correlated to log output.
-type DDContextLogHook struct{}
+// The zero value only adds the 64-bit trace ID and the span ID to the entries of all levels,
+// use NewHook to add the unified service tags, 128-bit trace IDs and the other options.
+type DDContextLogHook struct {
+	cfg *config
+}
 
-// Levels implements logrus.Hook interface, this hook applies to all defined levels
+// NewHook returns a DDContextLogHook configured with the given options.
+func NewHook(opts ...Option) *DDContextLogHook {
+	cfg := newConfig(opts...)
+	log.Debug("contrib/sirupsen/logrus: Configuring Hook: %#v", cfg)
+	return &DDContextLogHook{cfg: cfg}
+}
+
+// Levels implements logrus.Hook interface, by default this hook applies to all defined levels
 func (d *DDContextLogHook) Levels() []logrus.Level {
-	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel, logrus.InfoLevel, logrus.DebugLevel, logrus.TraceLevel}
+	if d.cfg == nil {
+		return logrus.AllLevels
+	}
+	return d.cfg.levels
 }
 
 // Fire implements logrus.Hook interface, attaches trace and span details found in entry context
@@ -34,7 +53,28 @@ func (d *DDContextLogHook) Fire(e *logrus.Entry) error {
 	if !found {
 		return nil
 	}
-	e.Data["dd.trace_id"] = span.Context().TraceID()
-	e.Data["dd.span_id"] = span.Context().SpanID()
+	ctx := span.Context()
+	e.Data[logcorrelation.KeyTraceID] = ctx.TraceID()
+	e.Data[logcorrelation.KeySpanID] = ctx.SpanID()
+	cfg := d.cfg
+	if cfg == nil {
+		return nil
+	}
+	if cfg.use128BitTraceID {
+		if id, ok := logcorrelation.TraceID128(ctx); ok {
+			e.Data[logcorrelation.KeyTraceID] = id
+		}
+	}
+	for _, tag := range cfg.serviceTags.Tags() {
+		e.Data[tag.Key] = tag.Value
+	}
+	// logrus levels are ordered from the most to the least severe.
+	if cfg.markErrors && e.Level <= cfg.errorLevel {
+		err, ok := e.Data[logrus.ErrorKey].(error)
+		if !ok {
+			err = errors.New(e.Message)
+		}
+		span.SetTag(ext.Error, err)
+	}
 	return nil
 }
@@ -7,8 +7,12 @@ package logrus
 
 import (
 	"context"
+	"errors"
+	"io"
 	"testing"
 
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 
 	"github.com/sirupsen/logrus"
@@ -29,3 +33,88 @@ func TestFire(t *testing.T) {
 	assert.Equal(t, uint64(1234), e.Data["dd.trace_id"])
 	assert.Equal(t, uint64(1234), e.Data["dd.span_id"])
 }
+
+func TestFireOptions(t *testing.T) {
+	tracer.Start(tracer.WithLogger(testLogger{}))
+	defer tracer.Stop()
+	span, sctx := tracer.StartSpanFromContext(context.Background(), "testSpan")
+	defer span.Finish()
+
+	t.Run("128-bit", func(t *testing.T) {
+		hook := NewHook()
+		e := logrus.NewEntry(logrus.New())
+		e.Context = sctx
+		assert.NoError(t, hook.Fire(e))
+		assert.Equal(t, span.Context().(ddtrace.SpanContextW3C).TraceID128(), e.Data["dd.trace_id"])
+		assert.Equal(t, span.Context().SpanID(), e.Data["dd.span_id"])
+
+		hook = NewHook(With128BitTraceID(false))
+		e = logrus.NewEntry(logrus.New())
+		e.Context = sctx
+		assert.NoError(t, hook.Fire(e))
+		assert.Equal(t, span.Context().TraceID(), e.Data["dd.trace_id"])
+	})
+
+	t.Run("service-tags", func(t *testing.T) {
+		t.Setenv("DD_ENV", "prod")
+		t.Setenv("DD_VERSION", "1.2.3")
+		hook := NewHook(WithService("svc"))
+		e := logrus.NewEntry(logrus.New())
+		e.Context = sctx
+		assert.NoError(t, hook.Fire(e))
+		assert.Equal(t, "svc", e.Data["dd.service"])
+		assert.Equal(t, "prod", e.Data["dd.env"])
+		assert.Equal(t, "1.2.3", e.Data["dd.version"])
+
+		// the zero value keeps adding the trace and span IDs only.
+		e = logrus.NewEntry(logrus.New())
+		e.Context = sctx
+		assert.NoError(t, (&DDContextLogHook{}).Fire(e))
+		assert.Equal(t, logrus.Fields{"dd.trace_id": span.Context().TraceID(), "dd.span_id": span.Context().SpanID()}, e.Data)
+
+		hook = NewHook(WithService("svc"), WithEnv("staging"), WithVersion("2.0"))
+		e = logrus.NewEntry(logrus.New())
+		e.Context = sctx
+		assert.NoError(t, hook.Fire(e))
+		assert.Equal(t, "svc", e.Data["dd.service"])
+		assert.Equal(t, "staging", e.Data["dd.env"])
+		assert.Equal(t, "2.0", e.Data["dd.version"])
+	})
+
+	t.Run("levels", func(t *testing.T) {
+		assert.Equal(t, logrus.AllLevels, (&DDContextLogHook{}).Levels())
+		hook := NewHook(WithLevels(logrus.ErrorLevel, logrus.WarnLevel))
+		assert.Equal(t, []logrus.Level{logrus.ErrorLevel, logrus.WarnLevel}, hook.Levels())
+	})
+}
+
+func TestFireErrorLevel(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.AddHook(NewHook(WithErrorLevel(logrus.ErrorLevel)))
+
+	span, sctx := tracer.StartSpanFromContext(context.Background(), "warn")
+	logger.WithContext(sctx).Warn("not an error")
+	span.Finish()
+
+	span, sctx = tracer.StartSpanFromContext(context.Background(), "message")
+	logger.WithContext(sctx).Error("something failed")
+	span.Finish()
+
+	span, sctx = tracer.StartSpanFromContext(context.Background(), "error")
+	logger.WithContext(sctx).WithError(errors.New("boom")).Error("something failed")
+	span.Finish()
+
+	spans := mt.FinishedSpans()
+	assert.Len(t, spans, 3)
+	assert.Nil(t, spans[0].Tag("error"))
+	assert.EqualError(t, spans[1].Tag("error").(error), "something failed")
+	assert.EqualError(t, spans[2].Tag("error").(error), "boom")
+}
+
+type testLogger struct{}
+
+func (testLogger) Log(string) {}
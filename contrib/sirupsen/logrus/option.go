@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package logrus
+
+import (
+	"gopkg.in/DataDog/dd-trace-go.v1/contrib/internal/logcorrelation"
+
+	"github.com/sirupsen/logrus"
+)
+
+type config struct {
+	use128BitTraceID bool
+	serviceTags      logcorrelation.ServiceTags
+	levels           []logrus.Level
+	errorLevel       logrus.Level
+	markErrors       bool
+}
+
+// Option represents an option that can be passed to NewHook.
+type Option func(*config)
+
+func defaults(cfg *config) {
+	cfg.use128BitTraceID = true
+	cfg.serviceTags = logcorrelation.DefaultServiceTags()
+	cfg.levels = logrus.AllLevels
+}
+
+func newConfig(opts ...Option) *config {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	return cfg
+}
+
+// With128BitTraceID specifies whether the trace ID of spans carrying a
+// 128-bit trace ID should be logged as a hex-encoded string. If disabled, or
+// if the trace ID only has 64 bits, the lower 64 bits are logged as a decimal
+// number. It is enabled by default.
+func With128BitTraceID(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.use128BitTraceID = enabled
+	}
+}
+
+// WithService sets the service name added to log entries. By default the
+// service name configured on the tracer is used, or DD_SERVICE if not set.
+func WithService(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceTags.Service = name
+	}
+}
+
+// WithEnv sets the environment added to log entries. It defaults to the value
+// of the DD_ENV environment variable.
+func WithEnv(env string) Option {
+	return func(cfg *config) {
+		cfg.serviceTags.Env = env
+	}
+}
+
+// WithVersion sets the service version added to log entries. It defaults to
+// the value of the DD_VERSION environment variable.
+func WithVersion(version string) Option {
+	return func(cfg *config) {
+		cfg.serviceTags.Version = version
+	}
+}
+
+// WithLevels sets the levels of the entries the hook applies to. By default
+// it applies to all levels.
+func WithLevels(levels ...logrus.Level) Option {
+	return func(cfg *config) {
+		cfg.levels = levels
+	}
+}
+
+// WithErrorLevel marks the span of the entry's context as errored whenever an
+// entry at the given level or a more severe one is logged, e.g.
+// WithErrorLevel(logrus.ErrorLevel). The error attached to the span is the one
+// set with logrus.WithError, or the entry's message otherwise. By default
+// spans are never marked as errored.
+func WithErrorLevel(level logrus.Level) Option {
+	return func(cfg *config) {
+		cfg.errorLevel = level
+		cfg.markErrors = true
+	}
+}
@@ -0,0 +1,138 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package fasthttp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	pappsec "gopkg.in/DataDog/dd-trace-go.v1/appsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestAppSec(t *testing.T) {
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.Enabled() {
+		t.Skip("appsec disabled")
+	}
+
+	handler := WrapHandler(func(fctx *fasthttp.RequestCtx) {
+		fctx.SetStatusCode(200)
+		fctx.WriteString("Hello World!\n")
+	})
+
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	var fctx fasthttp.RequestCtx
+	fctx.Request.Header.SetMethod("POST")
+	fctx.Request.SetRequestURI("/lfi/../../../secret.txt")
+	handler(&fctx)
+	require.Equal(t, 200, fctx.Response.StatusCode())
+	require.Equal(t, "Hello World!\n", string(fctx.Response.Body()))
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	event, _ := spans[0].Tag("_dd.appsec.json").(string)
+	require.Contains(t, event, "crs-930-110")
+	require.Contains(t, event, "server.request.uri.raw")
+}
+
+// Test that IP, user and body blocking works by using custom rules/rules data
+func TestBlocking(t *testing.T) {
+	t.Setenv("DD_APPSEC_RULES", "../../../internal/appsec/testdata/blocking.json")
+	// The request body is automatically parsed and monitored
+	t.Setenv("DD_APPSEC_BODY_PARSING_SIZE_LIMIT", "1048576")
+	appsec.Start()
+	defer appsec.Stop()
+	if !appsec.Enabled() {
+		t.Skip("AppSec needs to be enabled for this test")
+	}
+
+	handler := WrapHandler(func(fctx *fasthttp.RequestCtx) {
+		if usr := fctx.Request.Header.Peek("test-usr"); len(usr) > 0 {
+			// The request context is used as the Go context of the request
+			if err := pappsec.SetUser(fctx, string(usr)); err != nil {
+				return
+			}
+		}
+		fctx.Response.Header.Set("Content-Type", "text/plain")
+		fctx.WriteString("Hello World!\n")
+	})
+
+	for _, tc := range []struct {
+		name      string
+		headers   map[string]string
+		body      string
+		status    int
+		ruleMatch string
+	}{
+		{
+			name:   "no-block",
+			status: 200,
+		},
+		{
+			name:      "ip/block",
+			headers:   map[string]string{"x-forwarded-for": "1.2.3.4"},
+			status:    403,
+			ruleMatch: "blk-001-001",
+		},
+		{
+			name:    "user/no-block",
+			headers: map[string]string{"test-usr": "legit-user"},
+			status:  200,
+		},
+		{
+			name:      "user/block",
+			headers:   map[string]string{"test-usr": "blocked-user-1"},
+			status:    403,
+			ruleMatch: "blk-001-002",
+		},
+		{
+			name:      "body/block",
+			headers:   map[string]string{"Content-Type": "application/json"},
+			body:      `{"msg":"$globals"}`,
+			status:    403,
+			ruleMatch: "crs-933-130-block",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			var fctx fasthttp.RequestCtx
+			fctx.Request.Header.SetMethod("POST")
+			fctx.Request.SetRequestURI("/")
+			for k, v := range tc.headers {
+				fctx.Request.Header.Set(k, v)
+			}
+			fctx.Request.SetBodyString(tc.body)
+			handler(&fctx)
+			require.Equal(t, tc.status, fctx.Response.StatusCode())
+
+			spans := mt.FinishedSpans()
+			require.Len(t, spans, 1)
+			require.Equal(t, spans[0].Tag(ext.HTTPCode), fmt.Sprint(tc.status))
+			body := string(fctx.Response.Body())
+			if tc.ruleMatch == "" {
+				require.Equal(t, "Hello World!\n", body)
+				require.Nil(t, spans[0].Tag("_dd.appsec.json"))
+				return
+			}
+			require.True(t, strings.Contains(body, "Security provided by Datadog"))
+			require.Equal(t, "application/json", string(fctx.Response.Header.ContentType()))
+			require.Equal(t, true, spans[0].Tag("appsec.blocked"))
+			require.Contains(t, spans[0].Tag("_dd.appsec.json"), tc.ruleMatch)
+		})
+	}
+}
@@ -7,4 +7,33 @@ package options
 
 type CheckpointParams struct {
 	PayloadSize int64
+	// Schema is the schema of the message going through the checkpoint. It
+	// is sampled at a bounded rate per topic and operation, and reported
+	// along with the pathway stats so that schema changes can be correlated
+	// with the behavior of the pipeline.
+	Schema *Schema
+}
+
+// SchemaType is the type of a message schema.
+type SchemaType string
+
+const (
+	SchemaTypeAvro       SchemaType = "avro"
+	SchemaTypeProtobuf   SchemaType = "protobuf"
+	SchemaTypeJSONSchema SchemaType = "json"
+)
+
+// Schema describes the schema of a message.
+type Schema struct {
+	// Type is the type of the schema.
+	Type SchemaType
+	// ID identifies the schema, such as the ID of the schema in a schema
+	// registry or a hash of its definition.
+	ID string
+	// Name is the fully-qualified name of the schema, such as the name of the
+	// Avro record or of the Protobuf message.
+	Name string
+	// Definition is the definition of the schema fields, such as the Avro
+	// schema, the Protobuf descriptor or the JSON schema.
+	Definition string
 }
@@ -7,6 +7,7 @@ package datastreams
 
 import (
 	"context"
+	"strings"
 
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/datastreams"
 )
@@ -53,7 +54,9 @@ type TextMapReader interface {
 func ExtractFromBase64Carrier(ctx context.Context, carrier TextMapReader) (outCtx context.Context) {
 	outCtx = ctx
 	carrier.ForeachKey(func(key, val string) error {
-		if key == datastreams.PropagationKeyBase64 {
+		// keys are compared case-insensitively as carriers such as HTTP
+		// headers canonicalize them
+		if strings.EqualFold(key, datastreams.PropagationKeyBase64) {
 			_, outCtx, _ = datastreams.DecodeBase64(ctx, val)
 		}
 		return nil
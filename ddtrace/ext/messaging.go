@@ -14,6 +14,8 @@ const (
 const (
 	MessagingSystemGCPPubsub = "googlepubsub"
 	MessagingSystemKafka     = "kafka"
+	MessagingSystemNATS      = "nats"
+	MessagingSystemRabbitMQ  = "rabbitmq"
 )
 
 // Kafka tags.
@@ -23,3 +25,21 @@ const (
 	// KafkaBootstrapServers holds a comma separated list of bootstrap servers as defined in producer or consumer config.
 	KafkaBootstrapServers = "messaging.kafka.bootstrap.servers"
 )
+
+// RabbitMQ tags.
+const (
+	// RabbitMQExchange holds the name of the exchange the message is published to.
+	RabbitMQExchange = "messaging.rabbitmq.exchange"
+	// RabbitMQRoutingKey holds the routing key the message is published with.
+	RabbitMQRoutingKey = "messaging.rabbitmq.routing_key"
+	// RabbitMQQueue holds the name of the queue the message is consumed from.
+	RabbitMQQueue = "messaging.rabbitmq.queue"
+)
+
+// NATS tags.
+const (
+	// NATSSubject holds the subject the message is published to.
+	NATSSubject = "messaging.nats.subject"
+	// NATSStream holds the name of the JetStream stream which stored the message.
+	NATSStream = "messaging.nats.stream"
+)
@@ -8,14 +8,43 @@ package mocktracer
 import (
 	"compress/gzip"
 	"net/http"
+	"sync"
 
+	"github.com/DataDog/sketches-go/ddsketch"
+	"github.com/DataDog/sketches-go/ddsketch/pb/sketchpb"
 	"github.com/tinylib/msgp/msgp"
+	"google.golang.org/protobuf/proto"
 
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/datastreams"
 )
 
+// DSMStatsPoint holds the Data Streams Monitoring stats aggregated for a
+// pathway node during a bucket, as sent to the agent.
+type DSMStatsPoint struct {
+	// BucketStart is the beginning of the bucket in unix nanoseconds.
+	BucketStart uint64
+	// EdgeTags are the tags of the edge leading to the node, such as
+	// "direction:out", "topic:topic1" and "type:kafka".
+	EdgeTags []string
+	// Hash is the hash of the node, and ParentHash the hash of its parent, which
+	// is 0 for the root of the pathway.
+	Hash       uint64
+	ParentHash uint64
+	// TimestampType is "current" or "origin", the stats points being reported
+	// both from the time of the checkpoints and from the start of the pathways.
+	TimestampType string
+	// PathwayLatency and EdgeLatency are the distributions of the latencies in
+	// seconds since the start of the pathway and of the edge.
+	PathwayLatency *ddsketch.DDSketch
+	EdgeLatency    *ddsketch.DDSketch
+	// PayloadSize is the distribution of the payload sizes in bytes.
+	PayloadSize *ddsketch.DDSketch
+}
+
 type mockDSMTransport struct {
+	mu       sync.Mutex // guards below fields
 	backlogs []datastreams.Backlog
+	points   []DSMStatsPoint
 }
 
 // RoundTrip does nothing and returns a dummy response.
@@ -30,9 +59,22 @@ func (t *mockDSMTransport) RoundTrip(req *http.Request) (*http.Response, error)
 	if err != nil {
 		return nil, err
 	}
+	var points []DSMStatsPoint
+	for _, bucket := range p.Stats {
+		for _, s := range bucket.Stats {
+			point, err := decodeStatsPoint(bucket.Start, s)
+			if err != nil {
+				return nil, err
+			}
+			points = append(points, point)
+		}
+	}
+	t.mu.Lock()
 	for _, bucket := range p.Stats {
 		t.backlogs = append(t.backlogs, bucket.Backlogs...)
 	}
+	t.points = append(t.points, points...)
+	t.mu.Unlock()
 	return &http.Response{
 		StatusCode:    200,
 		Proto:         "HTTP/1.1",
@@ -43,3 +85,49 @@ func (t *mockDSMTransport) RoundTrip(req *http.Request) (*http.Response, error)
 		Body:          http.NoBody,
 	}, nil
 }
+
+func (t *mockDSMTransport) sentBacklogs() []datastreams.Backlog {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.backlogs
+}
+
+func (t *mockDSMTransport) sentStatsPoints() []DSMStatsPoint {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.points
+}
+
+func (t *mockDSMTransport) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.backlogs = nil
+	t.points = nil
+}
+
+func decodeStatsPoint(bucketStart uint64, s datastreams.StatsPoint) (DSMStatsPoint, error) {
+	point := DSMStatsPoint{
+		BucketStart:   bucketStart,
+		EdgeTags:      s.EdgeTags,
+		Hash:          s.Hash,
+		ParentHash:    s.ParentHash,
+		TimestampType: string(s.TimestampType),
+	}
+	var err error
+	if point.PathwayLatency, err = decodeSketch(s.PathwayLatency); err != nil {
+		return point, err
+	}
+	if point.EdgeLatency, err = decodeSketch(s.EdgeLatency); err != nil {
+		return point, err
+	}
+	point.PayloadSize, err = decodeSketch(s.PayloadSize)
+	return point, err
+}
+
+func decodeSketch(b []byte) (*ddsketch.DDSketch, error) {
+	var pb sketchpb.DDSketch
+	if err := proto.Unmarshal(b, &pb); err != nil {
+		return nil, err
+	}
+	return ddsketch.FromProto(&pb)
+}
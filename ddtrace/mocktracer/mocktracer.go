@@ -37,11 +37,20 @@ type Tracer interface {
 
 	// FinishedSpans returns the set of finished spans.
 	FinishedSpans() []Span
+
+	// SentDSMBacklogs flushes the Data Streams Monitoring stats and returns the
+	// Kafka backlogs sent so far.
 	SentDSMBacklogs() []datastreams.Backlog
 
-	// Reset resets the spans and services recorded in the tracer. This is
-	// especially useful when running tests in a loop, where a clean start
-	// is desired for FinishedSpans calls.
+	// SentDSMStatsPoints flushes the Data Streams Monitoring stats and returns
+	// the stats points sent so far, with their latency and payload size
+	// distributions decoded. The pathways can be rebuilt by matching the
+	// parent hashes of the points with the hashes of their parents.
+	SentDSMStatsPoints() []DSMStatsPoint
+
+	// Reset resets the spans, services and Data Streams Monitoring stats
+	// recorded in the tracer. This is especially useful when running tests
+	// in a loop, where a clean start is desired for FinishedSpans calls.
 	Reset()
 
 	// Stop deactivates the mock tracer and allows a normal tracer to take over.
@@ -70,7 +79,12 @@ type mocktracer struct {
 
 func (t *mocktracer) SentDSMBacklogs() []datastreams.Backlog {
 	t.dsmProcessor.Flush()
-	return t.dsmTransport.backlogs
+	return t.dsmTransport.sentBacklogs()
+}
+
+func (t *mocktracer) SentDSMStatsPoints() []DSMStatsPoint {
+	t.dsmProcessor.Flush()
+	return t.dsmTransport.sentStatsPoints()
 }
 
 func newMockTracer() *mocktracer {
@@ -133,6 +147,8 @@ func (t *mocktracer) Reset() {
 		delete(t.openSpans, k)
 	}
 	t.finishedSpans = nil
+	t.dsmProcessor.Flush()
+	t.dsmTransport.reset()
 }
 
 func (t *mocktracer) addFinishedSpan(s Span) {
@@ -6,15 +6,20 @@
 package mocktracer
 
 import (
+	"context"
+	"sort"
 	"testing"
 	"time"
 
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams"
+	"gopkg.in/DataDog/dd-trace-go.v1/datastreams/options"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/internal"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStart(t *testing.T) {
@@ -267,3 +272,39 @@ func TestTracerExtract(t *testing.T) {
 		assert.Equal("B", got.baggageItem("a"))
 	})
 }
+
+func TestTracerSentDSMStatsPoints(t *testing.T) {
+	mt := Start()
+	defer mt.Stop()
+
+	ctx, ok := tracer.SetDataStreamsCheckpointWithParams(context.Background(), options.CheckpointParams{PayloadSize: 100}, "direction:in", "topic:topicA", "type:kafka")
+	require.True(t, ok)
+	ctx, ok = tracer.SetDataStreamsCheckpointWithParams(ctx, options.CheckpointParams{PayloadSize: 200}, "direction:out", "topic:topicC", "type:kafka")
+	require.True(t, ok)
+	pathway, _ := datastreams.PathwayFromContext(ctx)
+
+	var points []DSMStatsPoint
+	for _, p := range mt.SentDSMStatsPoints() {
+		if p.TimestampType == "current" {
+			points = append(points, p)
+		}
+	}
+	require.Len(t, points, 2)
+	sort.Slice(points, func(i, j int) bool { return points[i].ParentHash < points[j].ParentHash })
+	in, out := points[0], points[1]
+	assert.Equal(t, uint64(0), in.ParentHash)
+	assert.Equal(t, []string{"direction:in", "topic:topicA", "type:kafka"}, in.EdgeTags)
+	assert.Equal(t, in.Hash, out.ParentHash)
+	assert.Equal(t, []string{"direction:out", "topic:topicC", "type:kafka"}, out.EdgeTags)
+	assert.Equal(t, pathway.GetHash(), out.Hash)
+	assert.Equal(t, float64(1), out.PayloadSize.GetCount())
+	size, err := out.PayloadSize.GetValueAtQuantile(0.5)
+	require.NoError(t, err)
+	assert.InEpsilon(t, 200, size, 0.01)
+	assert.Equal(t, float64(1), out.PathwayLatency.GetCount())
+	assert.Equal(t, float64(1), out.EdgeLatency.GetCount())
+
+	mt.Reset()
+	assert.Empty(t, mt.SentDSMStatsPoints())
+	assert.Empty(t, mt.SentDSMBacklogs())
+}
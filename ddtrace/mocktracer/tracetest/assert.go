@@ -0,0 +1,152 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package tracetest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+)
+
+// Node describes the expected shape of a trace tree. Operation must match the
+// operation name of the span. Tags, if set, must all be present on the span
+// with equal values; other tags of the span are ignored. Children, if set,
+// must match the children of the span in order of start time.
+type Node struct {
+	Operation string
+	Tags      map[string]interface{}
+	Children  []Node
+}
+
+// AssertTree asserts that spans form exactly one trace tree matching want.
+// It reports all mismatches found and returns whether the assertion passed.
+func AssertTree(t testing.TB, spans []mocktracer.Span, want Node) bool {
+	t.Helper()
+	roots := Build(spans)
+	if len(roots) != 1 {
+		t.Errorf("tracetest: expected a single trace tree, got %d:\n%s", len(roots), treesString(roots))
+		return false
+	}
+	var errs []string
+	matchNode(roots[0], want, want.Operation, &errs)
+	if len(errs) > 0 {
+		t.Errorf("tracetest: trace tree does not match:\n\t%s\ngot:\n%s", strings.Join(errs, "\n\t"), roots[0])
+		return false
+	}
+	return true
+}
+
+func matchNode(got *Tree, want Node, path string, errs *[]string) {
+	if op := got.Span.OperationName(); op != want.Operation {
+		*errs = append(*errs, fmt.Sprintf("%s: expected operation %q, got %q", path, want.Operation, op))
+		return
+	}
+	tags := got.Span.Tags()
+	keys := make([]string, 0, len(want.Tags))
+	for k := range want.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v, ok := tags[k]
+		if !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: missing tag %q", path, k))
+		} else if !tagEqual(v, want.Tags[k]) {
+			*errs = append(*errs, fmt.Sprintf("%s: expected tag %q to be %v, got %v", path, k, want.Tags[k], v))
+		}
+	}
+	if want.Children == nil {
+		return
+	}
+	if len(got.Children) != len(want.Children) {
+		*errs = append(*errs, fmt.Sprintf("%s: expected %d children, got %d", path, len(want.Children), len(got.Children)))
+		return
+	}
+	for i, c := range want.Children {
+		matchNode(got.Children[i], c, path+" > "+c.Operation, errs)
+	}
+}
+
+// tagEqual reports whether a tag value equals the expected one. Values are
+// compared by their string representation when their types differ, so that
+// e.g. an int expectation matches an int64 or float64 tag.
+func tagEqual(got, want interface{}) bool {
+	if reflect.DeepEqual(got, want) {
+		return true
+	}
+	return fmt.Sprint(got) == fmt.Sprint(want)
+}
+
+// AssertParentChild asserts that child is a direct child of parent within the
+// same trace.
+func AssertParentChild(t testing.TB, parent, child mocktracer.Span) bool {
+	t.Helper()
+	if child.ParentID() != parent.SpanID() || child.TraceID() != parent.TraceID() {
+		t.Errorf("tracetest: expected %q (span %d) to be a child of %q (span %d, trace %d), got parent %d in trace %d",
+			child.OperationName(), child.SpanID(), parent.OperationName(), parent.SpanID(), parent.TraceID(), child.ParentID(), child.TraceID())
+		return false
+	}
+	return true
+}
+
+// AssertOrder asserts that the given spans started in the given order.
+func AssertOrder(t testing.TB, spans ...mocktracer.Span) bool {
+	t.Helper()
+	for i := 1; i < len(spans); i++ {
+		if spans[i].StartTime().Before(spans[i-1].StartTime()) {
+			t.Errorf("tracetest: expected %q to start before %q", spans[i-1].OperationName(), spans[i].OperationName())
+			return false
+		}
+	}
+	return true
+}
+
+// DefaultRequiredTags lists the tags which every integration span of a given
+// span kind is expected to carry, see contrib/README.md.
+var DefaultRequiredTags = map[string][]string{
+	ext.SpanKindServer:   {ext.Component, ext.SpanKind},
+	ext.SpanKindClient:   {ext.Component, ext.SpanKind},
+	ext.SpanKindProducer: {ext.Component, ext.SpanKind},
+	ext.SpanKindConsumer: {ext.Component, ext.SpanKind},
+}
+
+// AssertRequiredTags asserts that every span carries the tags listed for its
+// span kind in required. Spans without a span kind are checked against the
+// tags listed for ext.SpanKindInternal, if any. DefaultRequiredTags is used
+// if required is nil.
+func AssertRequiredTags(t testing.TB, spans []mocktracer.Span, required map[string][]string) bool {
+	t.Helper()
+	if required == nil {
+		required = DefaultRequiredTags
+	}
+	ok := true
+	for _, s := range spans {
+		kind, _ := s.Tag(ext.SpanKind).(string)
+		if kind == "" {
+			kind = ext.SpanKindInternal
+		}
+		for _, k := range required[kind] {
+			if s.Tag(k) == nil {
+				t.Errorf("tracetest: %s span %q is missing required tag %q", kind, s.OperationName(), k)
+				ok = false
+			}
+		}
+	}
+	return ok
+}
+
+func treesString(trees []*Tree) string {
+	var sb strings.Builder
+	for _, t := range trees {
+		sb.WriteString(t.String())
+	}
+	return sb.String()
+}
@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package tracetest_test
+
+import (
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer/tracetest"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+func Example() {
+	// Within a test function, e.g. func TestHandler(t *testing.T)
+	var t testing.TB
+
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	root := tracer.StartSpan("http.request")
+	child := tracer.StartSpan("sql.query", tracer.ChildOf(root.Context()))
+	child.Finish()
+	root.Finish()
+
+	spans := mt.FinishedSpans()
+	// Assert on the shape of the trace.
+	tracetest.AssertTree(t, spans, tracetest.Node{
+		Operation: "http.request",
+		Children: []tracetest.Node{
+			{Operation: "sql.query"},
+		},
+	})
+	// Assert that integration spans carry the tags required for their kind.
+	tracetest.AssertRequiredTags(t, spans, nil)
+	// Or compare the whole trace against a golden file, which is written
+	// when running the tests with DD_TRACETEST_UPDATE_GOLDEN=true.
+	tracetest.AssertGolden(t, "testdata/handler.json", spans, tracetest.IgnoreTags(ext.SamplingPriority))
+}
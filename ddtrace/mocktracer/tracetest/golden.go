@@ -0,0 +1,183 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package tracetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal"
+)
+
+// UpdateGoldenEnvVar is the environment variable which, when set to true,
+// makes AssertGolden write the golden files instead of comparing against them.
+const UpdateGoldenEnvVar = "DD_TRACETEST_UPDATE_GOLDEN"
+
+// goldenSpan is the JSON representation of a span in a golden file. Span and
+// trace IDs, as well as start times and durations, are never part of it: the
+// tree structure replaces the former, and the latter vary between runs.
+type goldenSpan struct {
+	Name     string                 `json:"name"`
+	Tags     map[string]interface{} `json:"tags,omitempty"`
+	Children []goldenSpan           `json:"children,omitempty"`
+}
+
+type goldenConfig struct {
+	ignoreTags  []string
+	ignoreOrder bool
+}
+
+// GoldenOption configures AssertGolden.
+type GoldenOption func(*goldenConfig)
+
+// IgnoreTags excludes the given tags from golden files and comparisons. A key
+// ending with "*" excludes all tags starting with the preceding prefix, e.g.
+// "_dd.*". Use it for tags holding IDs, timings, ports or other values which
+// vary between runs.
+func IgnoreTags(keys ...string) GoldenOption {
+	return func(cfg *goldenConfig) {
+		cfg.ignoreTags = append(cfg.ignoreTags, keys...)
+	}
+}
+
+// IgnoreOrder orders sibling spans by operation name and resource instead of
+// start time. Use it when sibling spans are started concurrently.
+func IgnoreOrder() GoldenOption {
+	return func(cfg *goldenConfig) {
+		cfg.ignoreOrder = true
+	}
+}
+
+func (cfg *goldenConfig) ignored(key string) bool {
+	for _, k := range cfg.ignoreTags {
+		if prefix, ok := strings.CutSuffix(k, "*"); ok && strings.HasPrefix(key, prefix) {
+			return true
+		}
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// AssertGolden asserts that the trace trees formed by spans match the golden
+// JSON file at path. If the UpdateGoldenEnvVar environment variable is true,
+// the golden file is written instead.
+func AssertGolden(t testing.TB, path string, spans []mocktracer.Span, opts ...GoldenOption) bool {
+	t.Helper()
+	var cfg goldenConfig
+	for _, fn := range opts {
+		fn(&cfg)
+	}
+	got, err := marshalGolden(Build(spans), &cfg)
+	if err != nil {
+		t.Errorf("tracetest: marshaling spans: %v", err)
+		return false
+	}
+	if internal.BoolEnv(UpdateGoldenEnvVar, false) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Errorf("tracetest: %v", err)
+			return false
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Errorf("tracetest: writing golden file: %v", err)
+			return false
+		}
+		return true
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("tracetest: reading golden file (set %s=true to create it): %v", UpdateGoldenEnvVar, err)
+		return false
+	}
+	// Round-trip the golden file to compare both sides in the same format.
+	var wantSpans []goldenSpan
+	if err := json.Unmarshal(want, &wantSpans); err != nil {
+		t.Errorf("tracetest: decoding golden file %s: %v", path, err)
+		return false
+	}
+	want, err = json.MarshalIndent(wantSpans, "", "  ")
+	if err != nil {
+		t.Errorf("tracetest: %v", err)
+		return false
+	}
+	if !bytes.Equal(bytes.TrimSpace(got), bytes.TrimSpace(want)) {
+		t.Errorf("tracetest: spans do not match golden file %s (set %s=true to update it)\nexpected:\n%s\ngot:\n%s",
+			path, UpdateGoldenEnvVar, want, got)
+		return false
+	}
+	return true
+}
+
+// marshalGolden returns the golden file representation of trees.
+func marshalGolden(trees []*Tree, cfg *goldenConfig) ([]byte, error) {
+	spans := toGolden(trees, cfg)
+	// Round-trip through JSON so that tag values are normalized the same
+	// way as the ones decoded from a golden file, e.g. ints as float64.
+	b, err := json.Marshal(spans)
+	if err != nil {
+		return nil, err
+	}
+	spans = nil
+	if err := json.Unmarshal(b, &spans); err != nil {
+		return nil, err
+	}
+	b, err = json.MarshalIndent(spans, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+func toGolden(trees []*Tree, cfg *goldenConfig) []goldenSpan {
+	spans := make([]goldenSpan, 0, len(trees))
+	for _, t := range trees {
+		gs := goldenSpan{Name: t.Span.OperationName()}
+		for k, v := range t.Span.Tags() {
+			if v == nil || cfg.ignored(k) {
+				continue
+			}
+			if gs.Tags == nil {
+				gs.Tags = make(map[string]interface{})
+			}
+			gs.Tags[k] = goldenValue(v)
+		}
+		gs.Children = toGolden(t.Children, cfg)
+		spans = append(spans, gs)
+	}
+	if cfg.ignoreOrder {
+		sort.SliceStable(spans, func(i, j int) bool {
+			if spans[i].Name != spans[j].Name {
+				return spans[i].Name < spans[j].Name
+			}
+			return fmt.Sprint(spans[i].Tags["resource.name"]) < fmt.Sprint(spans[j].Tags["resource.name"])
+		})
+	}
+	return spans
+}
+
+// goldenValue converts a tag value to a value which can be stored in a golden
+// file.
+func goldenValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string, bool, nil,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return v
+	case error:
+		return v.Error()
+	default:
+		return fmt.Sprint(v)
+	}
+}
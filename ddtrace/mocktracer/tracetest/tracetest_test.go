@@ -0,0 +1,148 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package tracetest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordTrace records a small HTTP server trace calling a database twice.
+func recordTrace(t *testing.T) []mocktracer.Span {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	start := time.Now()
+	root := tracer.StartSpan("http.request",
+		tracer.StartTime(start),
+		tracer.ResourceName("GET /users"),
+		tracer.Tag(ext.SpanKind, ext.SpanKindServer),
+		tracer.Tag(ext.Component, "net/http"),
+		tracer.Tag(ext.HTTPCode, 200),
+	)
+	for i := 0; i < 2; i++ {
+		child := tracer.StartSpan("postgres.query",
+			tracer.ChildOf(root.Context()),
+			tracer.StartTime(start.Add(time.Duration(i+1)*time.Millisecond)),
+			tracer.ResourceName(fmt.Sprintf("SELECT %d", i)),
+			tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+			tracer.Tag(ext.Component, "database/sql"),
+			tracer.Tag("db.row_count", i),
+		)
+		child.Finish()
+	}
+	root.Finish()
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 3)
+	return spans
+}
+
+func TestBuild(t *testing.T) {
+	spans := recordTrace(t)
+	roots := Build(spans)
+	require.Len(t, roots, 1)
+	root := roots[0]
+	assert.Equal(t, "http.request", root.Span.OperationName())
+	require.Len(t, root.Children, 2)
+	assert.Equal(t, "SELECT 0", root.Children[0].Span.Tag(ext.ResourceName))
+	assert.Equal(t, "SELECT 1", root.Children[1].Span.Tag(ext.ResourceName))
+	assert.Equal(t, 3, root.Len())
+	assert.Equal(t, root.Children[0], root.Find("postgres.query"))
+	assert.Nil(t, root.Find("redis.command"))
+	assert.Equal(t, "http.request (resource: GET /users, kind: server)\n"+
+		"  postgres.query (resource: SELECT 0, kind: client)\n"+
+		"  postgres.query (resource: SELECT 1, kind: client)\n", root.String())
+}
+
+func TestAssertions(t *testing.T) {
+	spans := recordTrace(t)
+	root := Build(spans)[0]
+
+	assert.True(t, AssertTree(t, spans, Node{
+		Operation: "http.request",
+		Tags:      map[string]interface{}{ext.HTTPCode: 200, ext.SpanKind: ext.SpanKindServer},
+		Children: []Node{
+			{Operation: "postgres.query", Tags: map[string]interface{}{ext.ResourceName: "SELECT 0"}},
+			{Operation: "postgres.query", Tags: map[string]interface{}{ext.ResourceName: "SELECT 1"}},
+		},
+	}))
+	assert.True(t, AssertParentChild(t, root.Span, root.Children[1].Span))
+	assert.True(t, AssertOrder(t, root.Children[0].Span, root.Children[1].Span))
+	assert.True(t, AssertRequiredTags(t, spans, nil))
+
+	// failures are reported to the given testing.TB
+	for name, fn := range map[string]func(t testing.TB) bool{
+		"tree-operation": func(t testing.TB) bool {
+			return AssertTree(t, spans, Node{Operation: "grpc.server"})
+		},
+		"tree-tag": func(t testing.TB) bool {
+			return AssertTree(t, spans, Node{Operation: "http.request", Tags: map[string]interface{}{ext.HTTPCode: 500}})
+		},
+		"tree-children": func(t testing.TB) bool {
+			return AssertTree(t, spans, Node{Operation: "http.request", Children: []Node{{Operation: "postgres.query"}}})
+		},
+		"tree-roots": func(t testing.TB) bool {
+			return AssertTree(t, spans[:2], Node{Operation: "postgres.query"})
+		},
+		"parent-child": func(t testing.TB) bool {
+			return AssertParentChild(t, root.Children[0].Span, root.Children[1].Span)
+		},
+		"order": func(t testing.TB) bool {
+			return AssertOrder(t, root.Children[1].Span, root.Children[0].Span)
+		},
+		"required-tags": func(t testing.TB) bool {
+			return AssertRequiredTags(t, spans, map[string][]string{ext.SpanKindClient: {"db.system"}})
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := &recorder{TB: t}
+			assert.False(t, fn(rec))
+			assert.True(t, rec.failed)
+		})
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+	spans := recordTrace(t)
+	assert.True(t, AssertGolden(t, "testdata/trace.json", spans, IgnoreTags("_dd.*", ext.SamplingPriority)))
+
+	t.Run("mismatch", func(t *testing.T) {
+		t.Setenv(UpdateGoldenEnvVar, "false")
+		rec := &recorder{TB: t}
+		assert.False(t, AssertGolden(rec, "testdata/trace.json", spans[1:]))
+		assert.True(t, rec.failed)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		t.Setenv(UpdateGoldenEnvVar, "true")
+		path := filepath.Join(t.TempDir(), "golden", "trace.json")
+		assert.True(t, AssertGolden(t, path, spans, IgnoreTags("_dd.*", ext.SamplingPriority), IgnoreOrder()))
+		got, err := os.ReadFile(path)
+		require.NoError(t, err)
+		want, err := os.ReadFile("testdata/trace.json")
+		require.NoError(t, err)
+		assert.JSONEq(t, string(want), string(got))
+	})
+}
+
+// recorder is a testing.TB which records failures instead of failing the
+// test, to check that assertions fail when they should.
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Errorf(string, ...interface{}) { r.failed = true }
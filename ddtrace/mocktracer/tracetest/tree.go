@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+// Package tracetest provides helpers to assert on the spans recorded by the
+// mock tracer. It builds trace trees out of mock spans and offers tree-shaped
+// assertions, as well as comparisons against golden JSON files.
+package tracetest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+)
+
+// Tree is a span along with its children, ordered by start time.
+type Tree struct {
+	Span     mocktracer.Span
+	Children []*Tree
+}
+
+// Build builds the trees formed by spans, as returned by the mock tracer's
+// FinishedSpans or OpenSpans. It returns the roots, i.e. the spans whose
+// parent is not part of spans, ordered by start time.
+func Build(spans []mocktracer.Span) []*Tree {
+	nodes := make(map[uint64]*Tree, len(spans))
+	for _, s := range spans {
+		nodes[s.SpanID()] = &Tree{Span: s}
+	}
+	var roots []*Tree
+	for _, s := range spans {
+		node := nodes[s.SpanID()]
+		if parent, ok := nodes[s.ParentID()]; ok && s.ParentID() != s.SpanID() {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	for _, n := range nodes {
+		sortTrees(n.Children)
+	}
+	sortTrees(roots)
+	return roots
+}
+
+// sortTrees sorts trees by start time, falling back to the operation name
+// for spans started at the same time.
+func sortTrees(trees []*Tree) {
+	sort.SliceStable(trees, func(i, j int) bool {
+		a, b := trees[i].Span, trees[j].Span
+		if !a.StartTime().Equal(b.StartTime()) {
+			return a.StartTime().Before(b.StartTime())
+		}
+		return a.OperationName() < b.OperationName()
+	})
+}
+
+// Walk calls fn for t and all of its descendants, depth first, along with
+// their depth relative to t. Children are not visited if fn returns false.
+func (t *Tree) Walk(fn func(t *Tree, depth int) bool) {
+	t.walk(fn, 0)
+}
+
+func (t *Tree) walk(fn func(t *Tree, depth int) bool, depth int) {
+	if !fn(t, depth) {
+		return
+	}
+	for _, c := range t.Children {
+		c.walk(fn, depth+1)
+	}
+}
+
+// Find returns the first span of t, depth first, with the given operation
+// name, or nil if there is none.
+func (t *Tree) Find(operation string) *Tree {
+	var found *Tree
+	t.Walk(func(n *Tree, _ int) bool {
+		if found != nil {
+			return false
+		}
+		if n.Span.OperationName() == operation {
+			found = n
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// Len returns the number of spans in t.
+func (t *Tree) Len() int {
+	n := 0
+	t.Walk(func(*Tree, int) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// String returns an indented representation of t, one span per line, which
+// is useful when debugging failing assertions.
+func (t *Tree) String() string {
+	var sb strings.Builder
+	t.Walk(func(n *Tree, depth int) bool {
+		fmt.Fprintf(&sb, "%s%s (resource: %v, kind: %v)\n",
+			strings.Repeat("  ", depth), n.Span.OperationName(), n.Span.Tag(ext.ResourceName), n.Span.Tag(ext.SpanKind))
+		return true
+	})
+	return sb.String()
+}
@@ -72,3 +72,17 @@ func TrackKafkaHighWatermarkOffset(cluster string, topic string, partition int32
 		}
 	}
 }
+
+// TrackDataStreamsTransaction tracks the given checkpoint of the transaction
+// identified by transactionID, such as a business identifier like an order ID,
+// so that individual transactions can be followed across services. The
+// transactions are sampled out of their IDs at the rate set with
+// DD_DATA_STREAMS_TRANSACTION_SAMPLE_RATE, and the checkpoints listed in
+// DD_DATA_STREAMS_TRANSACTION_CHECKPOINTS_DISABLED aren't tracked.
+func TrackDataStreamsTransaction(transactionID, checkpointName string) {
+	if t, ok := internal.GetGlobalTracer().(dataStreamsContainer); ok {
+		if p := t.GetDataStreamsProcessor(); p != nil {
+			p.TrackTransaction(transactionID, checkpointName)
+		}
+	}
+}
@@ -80,11 +80,17 @@ var contribIntegrations = map[string]struct {
 	"k8s.io/client-go/kubernetes":                   {"Kubernetes", false},
 	"github.com/labstack/echo":                      {"echo", false},
 	"github.com/labstack/echo/v4":                   {"echo v4", false},
+	"log/slog":                                      {"log/slog", false},
 	"github.com/miekg/dns":                          {"miekg/dns", false},
+	"github.com/nats-io/nats.go":                    {"NATS", false},
 	"net/http":                                      {"HTTP", false},
+	"os":                                            {"os", false},
+	"os/exec":                                       {"os/exec", false},
 	"gopkg.in/olivere/elastic.v5":                   {"Elasticsearch v5", false},
 	"gopkg.in/olivere/elastic.v3":                   {"Elasticsearch v3", false},
+	"github.com/rabbitmq/amqp091-go":                {"RabbitMQ", false},
 	"github.com/redis/go-redis/v9":                  {"Redis v9", false},
+	"github.com/rs/zerolog":                         {"Zerolog", false},
 	"github.com/segmentio/kafka-go":                 {"Kafka v0", false},
 	"github.com/IBM/sarama":                         {"IBM sarama", false},
 	"github.com/Shopify/sarama":                     {"Shopify sarama", false},
@@ -95,6 +101,7 @@ var contribIntegrations = map[string]struct {
 	"github.com/urfave/negroni":                     {"Negroni", false},
 	"github.com/valyala/fasthttp":                   {"FastHTTP", false},
 	"github.com/zenazn/goji":                        {"Goji", false},
+	"go.uber.org/zap":                               {"Zap", false},
 }
 
 var (
@@ -255,6 +262,17 @@ type config struct {
 	// dataStreamsMonitoringEnabled specifies whether the tracer should enable monitoring of data streams
 	dataStreamsMonitoringEnabled bool
 
+	// dataStreamsConsumerLagEnabled specifies whether the data streams processor should report
+	// the lag of the Kafka consumer groups as DogStatsD metrics
+	dataStreamsConsumerLagEnabled bool
+
+	// dataStreamsTransactionSampleRate specifies the rate at which the data streams transactions are sampled
+	dataStreamsTransactionSampleRate float64
+
+	// dataStreamsDisabledTransactionCheckpoints specifies the names of the data streams transaction checkpoints
+	// which aren't tracked
+	dataStreamsDisabledTransactionCheckpoints []string
+
 	// orchestrionCfg holds Orchestrion (aka auto-instrumentation) configuration.
 	// Only used for telemetry currently.
 	orchestrionCfg orchestrionConfig
@@ -390,6 +408,15 @@ func newConfig(opts ...StartOption) *config {
 	}
 	c.statsComputationEnabled = internal.BoolEnv("DD_TRACE_STATS_COMPUTATION_ENABLED", false)
 	c.dataStreamsMonitoringEnabled = internal.BoolEnv("DD_DATA_STREAMS_ENABLED", false)
+	c.dataStreamsConsumerLagEnabled = internal.BoolEnv("DD_DATA_STREAMS_CONSUMER_LAG_ENABLED", false)
+	c.dataStreamsTransactionSampleRate = internal.FloatEnv("DD_DATA_STREAMS_TRANSACTION_SAMPLE_RATE", 1)
+	if v := os.Getenv("DD_DATA_STREAMS_TRANSACTION_CHECKPOINTS_DISABLED"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				c.dataStreamsDisabledTransactionCheckpoints = append(c.dataStreamsDisabledTransactionCheckpoints, name)
+			}
+		}
+	}
 	c.partialFlushEnabled = internal.BoolEnv("DD_TRACE_PARTIAL_FLUSH_ENABLED", false)
 	c.partialFlushMinSpans = internal.IntEnv("DD_TRACE_PARTIAL_FLUSH_MIN_SPANS", partialFlushMinSpansDefault)
 	if c.partialFlushMinSpans <= 0 {
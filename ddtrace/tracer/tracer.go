@@ -264,6 +264,10 @@ func newUnstartedTracer(opts ...StartOption) *tracer {
 	var dataStreamsProcessor *datastreams.Processor
 	if c.dataStreamsMonitoringEnabled {
 		dataStreamsProcessor = datastreams.NewProcessor(statsd, c.env, c.serviceName, c.version, c.agentURL, c.httpClient)
+		if c.dataStreamsConsumerLagEnabled {
+			dataStreamsProcessor.EnableConsumerLagMetrics()
+		}
+		dataStreamsProcessor.ConfigureTransactions(c.dataStreamsTransactionSampleRate, c.dataStreamsDisabledTransactionCheckpoints)
 	}
 	t := &tracer{
 		config:           c,
@@ -33,6 +33,17 @@ func RASPEnabled() bool {
 	return activeAppSec != nil && activeAppSec.started && activeAppSec.cfg.RASP
 }
 
+// BodyParsingSizeLimit returns the maximum size of the HTTP request bodies automatically parsed by the HTTP
+// integrations, or zero if request bodies must not be parsed. Granted that AppSec is enabled.
+func BodyParsingSizeLimit() int {
+	mu.RLock()
+	defer mu.RUnlock()
+	if activeAppSec == nil || !activeAppSec.started {
+		return 0
+	}
+	return activeAppSec.cfg.BodyParsingSizeLimit
+}
+
 // Start AppSec when enabled is enabled by both using the appsec build tag and
 // setting the environment variable DD_APPSEC_ENABLED to true.
 func Start(opts ...config.StartOption) {
@@ -47,6 +47,12 @@ const (
 	EnvEnabled = "DD_APPSEC_ENABLED"
 	// EnvSCAEnabled controls ASM Software Composition Analysis (SCA)'s enablement.
 	EnvSCAEnabled = "DD_APPSEC_SCA_ENABLED"
+	// EnvBodyParsingSizeLimit is the maximum size, in bytes, of the HTTP request bodies automatically parsed by the
+	// HTTP integrations, e.g. 1048576. The automatic parsing of request bodies is disabled by default, or with a zero
+	// or negative value, as handlers may already monitor the bodies they parse with appsec.MonitorParsedHTTPBody.
+	// Bodies of unknown length (e.g. chunked) are never parsed automatically, so that streamed requests aren't held
+	// back.
+	EnvBodyParsingSizeLimit = "DD_APPSEC_BODY_PARSING_SIZE_LIMIT"
 )
 
 // StartOption is used to customize the AppSec configuration when invoked with appsec.Start()
@@ -68,6 +74,9 @@ type Config struct {
 	// RC is the remote configuration client used to receive product configuration updates. Nil if RC is disabled (default)
 	RC   *remoteconfig.ClientConfig
 	RASP bool
+	// BodyParsingSizeLimit is the maximum size of the HTTP request bodies automatically parsed. Zero, the default,
+	// disables it.
+	BodyParsingSizeLimit int
 }
 
 // WithRCConfig sets the AppSec remote config client configuration to the specified cfg
@@ -117,5 +126,25 @@ func NewConfig() (*Config, error) {
 		Obfuscator:     internal.NewObfuscatorConfig(),
 		APISec:         internal.NewAPISecConfig(),
 		RASP:           internal.RASPEnabled(),
+
+		BodyParsingSizeLimit: bodyParsingSizeLimitFromEnv(),
 	}, nil
 }
+
+// bodyParsingSizeLimitFromEnv reads and parses the request body parsing size limit set through the env var
+// DD_APPSEC_BODY_PARSING_SIZE_LIMIT. If not set or invalid, it defaults to DefaultBodyParsingSizeLimit.
+func bodyParsingSizeLimitFromEnv() int {
+	str := os.Getenv(EnvBodyParsingSizeLimit)
+	if str == "" {
+		return 0
+	}
+	limit, err := strconv.Atoi(str)
+	if err != nil {
+		log.Error("appsec: could not parse %s value `%s` as an integer value, request bodies won't be parsed automatically", EnvBodyParsingSizeLimit, str)
+		return 0
+	}
+	if limit < 0 {
+		return 0
+	}
+	return limit
+}
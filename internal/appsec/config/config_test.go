@@ -63,3 +63,26 @@ func TestSCAEnabled(t *testing.T) {
 		})
 	}
 }
+
+func TestBodyParsingSizeLimit(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		envVar   string
+		expected int
+	}{
+		{name: "undefined", expected: 0},
+		{name: "set", envVar: "1024", expected: 1024},
+		{name: "disabled", envVar: "0", expected: 0},
+		{name: "negative", envVar: "-1", expected: 0},
+		{name: "parsing error", envVar: "1MB", expected: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.envVar != "" {
+				t.Setenv(EnvBodyParsingSizeLimit, tc.envVar)
+			}
+			if got := bodyParsingSizeLimitFromEnv(); got != tc.expected {
+				t.Fatalf("expected %d, got %d", tc.expected, got)
+			}
+		})
+	}
+}
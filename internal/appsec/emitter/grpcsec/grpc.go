@@ -14,6 +14,7 @@ import (
 
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/grpcsec/types"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/sharedsec"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/trace"
 )
@@ -28,6 +29,11 @@ func StartHandlerOperation(ctx context.Context, args types.HandlerOperationArgs,
 		TagsHolder: trace.NewTagsHolder(),
 	}
 	newCtx := context.WithValue(ctx, listener.ContextKey{}, op)
+	var clientIP string
+	if args.ClientIP.IsValid() {
+		clientIP = args.ClientIP.String()
+	}
+	sharedsec.OnSecurityEvents(op, ctx, clientIP, args.Method)
 	for _, cb := range setup {
 		cb(op)
 	}
@@ -15,6 +15,35 @@ type Config struct {
 	// apply synchronization if they allow http.ResponseWriter objects to be
 	// accessed by multiple goroutines.
 	ResponseHeaderCopier func(http.ResponseWriter) http.Header
+	// Route is the route matched by the request, if known, reported along with the security events to the
+	// security event callback. The request path is reported instead when empty.
+	Route string
+	// ResponseBody, when not nil, is the response writer given to the handler
+	// holding back its response so that the response body can be inspected,
+	// and the response blocked or redacted, once the handler returns.
+	ResponseBody ResponseBodyBuffer
+	// PathParams, when not nil, returns the path parameters of the request
+	// once the handler returns. It is used by the frameworks matching the
+	// route after their middlewares, in which case the path parameters are
+	// only known once the handler returned, and is only called when the
+	// response is held back by ResponseBody so that it can still be blocked.
+	PathParams func() map[string]string
+}
+
+// ResponseBodyBuffer is implemented by response writers holding back the
+// response until it is committed.
+type ResponseBodyBuffer interface {
+	// Body returns the held back response body along with its content type.
+	// It returns false if the response is not being held back.
+	Body() (body []byte, contentType string, ok bool)
+	// SetBody replaces the held back response body.
+	SetBody(body []byte)
+	// Discard drops the held back response and stops holding back the
+	// response.
+	Discard()
+	// Commit writes the held back response and stops holding back the
+	// response.
+	Commit() error
 }
 
 var defaultWrapHandlerConfig = &Config{
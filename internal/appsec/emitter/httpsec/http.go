@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/httpsec/types"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/sharedsec"
@@ -41,6 +42,12 @@ func MonitorParsedBody(ctx context.Context, body any) error {
 		log.Error("appsec: parsed http body monitoring ignored: could not find the http handler instrumentation metadata in the request context: the request handler is not being monitored by a middleware function or the provided context is not the expected request context")
 		return nil
 	}
+	if parent.BodyMonitored() {
+		// The request body was automatically parsed and monitored before the handler was called, and it would
+		// otherwise report the same security events twice.
+		log.Debug("appsec: parsed http body monitoring ignored: the request body was already monitored")
+		return nil
+	}
 
 	return ExecuteSDKBodyOperation(parent, types.SDKBodyOperationArgs{Body: body})
 }
@@ -81,20 +88,40 @@ func WrapHandler(handler http.Handler, span ddtrace.Span, pathParams map[string]
 		var bypassHandler http.Handler
 		var blocking bool
 		var stackTrace *stacktrace.Event
+		var redact *sharedsec.RedactAction
 		args := MakeHandlerOperationArgs(r, clientIP, pathParams)
+		route := opts.Route
+		if route == "" {
+			route = r.URL.Path
+		}
 		ctx, op := StartOperation(r.Context(), args, func(op *types.Operation) {
+			sharedsec.OnSecurityEvents(op, r.Context(), clientIPString(clientIP), route)
 			dyngo.OnData(op, func(a *sharedsec.HTTPAction) {
 				blocking = true
-				bypassHandler = a.Handler
+				bypassHandler = a.BlockingHandler(route)
 			})
 			dyngo.OnData(op, func(a *sharedsec.StackTraceAction) {
 				stackTrace = &a.Event
 			})
+			dyngo.OnData(op, func(a *sharedsec.RedactAction) {
+				redact = a
+			})
 		})
 		r = r.WithContext(ctx)
 
 		defer func() {
-			events := op.Finish(MakeHandlerOperationRes(w))
+			res := MakeHandlerOperationRes(w)
+			var contentType string
+			if opts.ResponseBody != nil {
+				var body []byte
+				if body, contentType, _ = opts.ResponseBody.Body(); body != nil {
+					res.Body = parseResponseBody(body, contentType)
+				}
+				if opts.PathParams != nil {
+					res.PathParams = opts.PathParams()
+				}
+			}
+			events := op.Finish(res)
 
 			// Execute the onBlock functions to make sure blocking works properly
 			// in case we are instrumenting the Gin framework
@@ -111,7 +138,18 @@ func WrapHandler(handler http.Handler, span ddtrace.Span, pathParams map[string]
 			}
 
 			if bypassHandler != nil {
+				if opts.ResponseBody != nil {
+					// Replace the held back response with the blocking one
+					opts.ResponseBody.Discard()
+				}
 				bypassHandler.ServeHTTP(w, r)
+			} else if redact != nil && res.Body != nil {
+				redactResponse(opts.ResponseBody, res.Body, contentType, redact)
+			}
+			if opts.ResponseBody != nil {
+				if err := opts.ResponseBody.Commit(); err != nil {
+					log.Debug("appsec: could not write the held back response: %v", err)
+				}
 			}
 
 			// Add the request headers span tags out of args.Headers instead of r.Header as it was normalized and some
@@ -125,7 +163,21 @@ func WrapHandler(handler http.Handler, span ddtrace.Span, pathParams map[string]
 			}
 		}()
 
+		// Parse the request body, unless the request is already blocked, so that it is monitored before the
+		// handler runs.
+		if limit := appsec.BodyParsingSizeLimit(); bypassHandler == nil && limit > 0 {
+			if body := parseRequestBody(r, limit); body != nil {
+				// Blocking is handled by the data listeners set up above
+				ExecuteSDKBodyOperation(op, types.SDKBodyOperationArgs{Body: body})
+				op.SetBodyMonitored()
+			}
+		}
+
 		if bypassHandler != nil {
+			if opts.ResponseBody != nil {
+				// The blocking response doesn't need to be inspected
+				opts.ResponseBody.Discard()
+			}
 			handler = bypassHandler
 			bypassHandler = nil
 		}
@@ -133,6 +185,29 @@ func WrapHandler(handler http.Handler, span ddtrace.Span, pathParams map[string]
 	})
 }
 
+// redactResponse redacts the values of the held back response body matched by the WAF.
+func redactResponse(buf ResponseBodyBuffer, body any, contentType string, a *sharedsec.RedactAction) {
+	if !redactResponseBody(body, a.KeyPaths, a.Replacement) {
+		return
+	}
+	redacted, err := encodeResponseBody(body, contentType)
+	if err != nil {
+		log.Debug("appsec: could not encode the redacted response body: %v", err)
+		// Don't leak the values that were supposed to be redacted
+		redacted = nil
+	}
+	buf.SetBody(redacted)
+}
+
+// clientIPString returns the string representation of the given client IP address, or an empty string when it is
+// unknown.
+func clientIPString(ip netip.Addr) string {
+	if !ip.IsValid() {
+		return ""
+	}
+	return ip.String()
+}
+
 // MakeHandlerOperationArgs creates the HandlerOperationArgs value.
 func MakeHandlerOperationArgs(r *http.Request, clientIP netip.Addr, pathParams map[string]string) types.HandlerOperationArgs {
 	cookies := makeCookies(r) // TODO(Julio-Guerra): avoid actively parsing the cookies thanks to dynamic instrumentation
@@ -0,0 +1,32 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package httpsec
+
+import (
+	"context"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/httpsec/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonitorParsedBody(t *testing.T) {
+	var bodies []any
+	ctx, op := StartOperation(context.Background(), types.HandlerOperationArgs{}, func(op *types.Operation) {
+		dyngo.On(op, func(_ *types.SDKBodyOperation, args types.SDKBodyOperationArgs) {
+			bodies = append(bodies, args.Body)
+		})
+	})
+	defer op.Finish(types.HandlerOperationRes{})
+
+	require.NoError(t, MonitorParsedBody(ctx, "monitored"))
+	// Once the request body was automatically monitored, the handler calls are ignored.
+	op.SetBodyMonitored()
+	require.NoError(t, MonitorParsedBody(ctx, "ignored"))
+	require.Equal(t, []any{"monitored"}, bodies)
+}
@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package httpsec
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+// parseRequestBody reads and parses the JSON, URL-encoded form or multipart
+// form body of r into the value of the `server.request.body` address. Bodies
+// larger than limit bytes are not parsed, nor are bodies of unknown length
+// (e.g. chunked) so that streamed requests aren't held back before the handler
+// runs. The body of r is replaced so that the handler can still read it
+// entirely. It returns nil when the body is not parsed.
+func parseRequestBody(r *http.Request, limit int) any {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength <= 0 || r.ContentLength > int64(limit) {
+		return nil
+	}
+	contentType := r.Header.Get("Content-Type")
+	mt, params, err := mime.ParseMediaType(contentType)
+	if err != nil || (responseBodyEncodingOf(contentType) == responseBodyUnsupported && mt != "multipart/form-data") {
+		return nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+	r.Body = &replayedBody{Reader: io.MultiReader(bytes.NewReader(data), r.Body), Closer: r.Body}
+	if err != nil {
+		log.Debug("appsec: could not read the request body: %v", err)
+		return nil
+	}
+	if len(data) > limit {
+		return nil
+	}
+
+	if mt == "multipart/form-data" {
+		return parseMultipartForm(data, params["boundary"])
+	}
+	// JSON and URL-encoded form bodies are parsed like response bodies.
+	return parseResponseBody(data, contentType)
+}
+
+// replayedBody is a request body whose beginning was already read by AppSec.
+type replayedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// parseMultipartForm returns the values of the fields of the given multipart
+// form body. File contents are ignored.
+func parseMultipartForm(body []byte, boundary string) any {
+	if boundary == "" {
+		return nil
+	}
+	values := make(map[string][]string)
+	mr := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Debug("appsec: could not parse the multipart form body: %v", err)
+			return nil
+		}
+		name := part.FormName()
+		if name == "" || part.FileName() != "" {
+			continue
+		}
+		value, err := io.ReadAll(part)
+		if err != nil {
+			log.Debug("appsec: could not parse the multipart form body: %v", err)
+			return nil
+		}
+		values[name] = append(values[name], string(value))
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
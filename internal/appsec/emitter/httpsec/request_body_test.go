@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package httpsec
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestBody(t *testing.T) {
+	for name, tc := range map[string]struct {
+		contentType string
+		body        string
+		limit       int
+		expected    any
+	}{
+		"json": {
+			contentType: "application/json",
+			body:        `{"a":[1,"b"]}`,
+			expected:    map[string]any{"a": []any{json.Number("1"), "b"}},
+		},
+		"form": {
+			contentType: "application/x-www-form-urlencoded",
+			body:        `a=1&a=2&b=3`,
+			expected:    map[string][]string{"a": {"1", "2"}, "b": {"3"}},
+		},
+		"multipart": {
+			contentType: "multipart/form-data; boundary=xxx",
+			body: "--xxx\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\n1\r\n" +
+				"--xxx\r\nContent-Disposition: form-data; name=\"f\"; filename=\"f.txt\"\r\n\r\ncontent\r\n" +
+				"--xxx--\r\n",
+			expected: map[string][]string{"a": {"1"}},
+		},
+		"too-large": {
+			contentType: "application/json",
+			body:        `{"a":1}`,
+			limit:       4,
+		},
+		"unsupported": {
+			contentType: "text/plain",
+			body:        `{"a":1}`,
+		},
+		"malformed": {
+			contentType: "application/json",
+			body:        `{"a":`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			limit := tc.limit
+			if limit == 0 {
+				limit = 1024
+			}
+			r := httptest.NewRequest("POST", "/", strings.NewReader(tc.body))
+			r.Header.Set("Content-Type", tc.contentType)
+			require.Equal(t, tc.expected, parseRequestBody(r, limit))
+
+			// The handler can still read the whole body
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.Equal(t, tc.body, string(body))
+			require.NoError(t, r.Body.Close())
+		})
+	}
+
+	t.Run("chunked", func(t *testing.T) {
+		// Bodies of unknown length are not read ahead of the handler
+		r := httptest.NewRequest("POST", "/", strings.NewReader(`{"a":"b"}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.ContentLength = -1
+		body := r.Body
+		require.Nil(t, parseRequestBody(r, 1024))
+		require.Equal(t, body, r.Body)
+	})
+}
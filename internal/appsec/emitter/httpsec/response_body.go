@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package httpsec
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+// responseBodyEncoding is the encoding of an inspected response body.
+type responseBodyEncoding int
+
+const (
+	responseBodyUnsupported responseBodyEncoding = iota
+	responseBodyJSON
+	responseBodyForm
+)
+
+func responseBodyEncodingOf(contentType string) responseBodyEncoding {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return responseBodyUnsupported
+	}
+	switch {
+	case mt == "application/json" || strings.HasSuffix(mt, "+json"):
+		return responseBodyJSON
+	case mt == "application/x-www-form-urlencoded":
+		return responseBodyForm
+	default:
+		return responseBodyUnsupported
+	}
+}
+
+// parseResponseBody parses the given response body according to its content
+// type into the value of the `server.response.body` address. It returns nil
+// when the body is empty, malformed or of an unsupported content type.
+func parseResponseBody(body []byte, contentType string) any {
+	if len(body) == 0 {
+		return nil
+	}
+	switch responseBodyEncodingOf(contentType) {
+	case responseBodyJSON:
+		// Numbers are kept as is so that re-encoding a redacted body doesn't alter them
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			log.Debug("appsec: could not parse the JSON response body: %v", err)
+			return nil
+		}
+		return v
+	case responseBodyForm:
+		v, err := url.ParseQuery(string(body))
+		if err != nil {
+			log.Debug("appsec: could not parse the form response body: %v", err)
+			return nil
+		}
+		return map[string][]string(v)
+	default:
+		return nil
+	}
+}
+
+// encodeResponseBody is the inverse of parseResponseBody.
+func encodeResponseBody(body any, contentType string) ([]byte, error) {
+	if responseBodyEncodingOf(contentType) == responseBodyForm {
+		v, _ := body.(map[string][]string)
+		return []byte(url.Values(v).Encode()), nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(body); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// redactResponseBody replaces the values found at the given key paths of the
+// parsed response body with replacement. It returns false if no value was
+// redacted.
+func redactResponseBody(body any, keyPaths [][]string, replacement string) bool {
+	var redacted bool
+	for _, path := range keyPaths {
+		redacted = redactValue(body, path, replacement) || redacted
+	}
+	return redacted
+}
+
+func redactValue(v any, path []string, replacement string) bool {
+	if len(path) == 0 {
+		return false
+	}
+	key, last := path[0], len(path) == 1
+	switch v := v.(type) {
+	case map[string]any:
+		child, ok := v[key]
+		if !ok {
+			return false
+		}
+		if last {
+			v[key] = replacement
+			return true
+		}
+		return redactValue(child, path[1:], replacement)
+	case []any:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(v) {
+			return false
+		}
+		if last {
+			v[i] = replacement
+			return true
+		}
+		return redactValue(v[i], path[1:], replacement)
+	case map[string][]string:
+		values, ok := v[key]
+		if !ok {
+			return false
+		}
+		if last {
+			for i := range values {
+				values[i] = replacement
+			}
+			return true
+		}
+		i, err := strconv.Atoi(path[1])
+		if err != nil || i < 0 || i >= len(values) || len(path) > 2 {
+			return false
+		}
+		values[i] = replacement
+		return true
+	default:
+		return false
+	}
+}
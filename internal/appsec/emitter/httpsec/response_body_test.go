@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package httpsec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseBody(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		body := parseResponseBody([]byte(`{"user":{"id":9007199254740993,"name":"john","cards":["4111111111111111","5500000000000004"]}}`), "application/json")
+		require.NotNil(t, body)
+		redacted := redactResponseBody(body, [][]string{
+			{"user", "cards", "1"},
+			{"user", "missing"},
+			{"user", "cards", "9"},
+		}, "<Redacted>")
+		require.True(t, redacted)
+		encoded, err := encodeResponseBody(body, "application/json")
+		require.NoError(t, err)
+		require.JSONEq(t, `{"user":{"id":9007199254740993,"name":"john","cards":["4111111111111111","<Redacted>"]}}`, string(encoded))
+	})
+
+	t.Run("form", func(t *testing.T) {
+		body := parseResponseBody([]byte(`token=abc&token=def&name=john`), "application/x-www-form-urlencoded; charset=utf-8")
+		require.Equal(t, map[string][]string{"token": {"abc", "def"}, "name": {"john"}}, body)
+		require.True(t, redactResponseBody(body, [][]string{{"token"}}, "x"))
+		encoded, err := encodeResponseBody(body, "application/x-www-form-urlencoded")
+		require.NoError(t, err)
+		require.Equal(t, `name=john&token=x&token=x`, string(encoded))
+	})
+
+	t.Run("not-redacted", func(t *testing.T) {
+		body := parseResponseBody([]byte(`"hello"`), "application/problem+json")
+		require.Equal(t, "hello", body)
+		require.False(t, redactResponseBody(body, [][]string{{"a"}}, "x"))
+	})
+
+	t.Run("unparsable", func(t *testing.T) {
+		require.Nil(t, parseResponseBody([]byte(`{`), "application/json"))
+		require.Nil(t, parseResponseBody([]byte(`{}`), "text/plain"))
+		require.Nil(t, parseResponseBody(nil, "application/json"))
+	})
+}
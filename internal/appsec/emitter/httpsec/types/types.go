@@ -8,6 +8,7 @@ package types
 import (
 	"net/netip"
 	"sync"
+	"sync/atomic"
 
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/trace"
@@ -21,6 +22,8 @@ type (
 		trace.TagsHolder
 		trace.SecurityEventsHolder
 		mu sync.RWMutex
+		// bodyMonitored reports whether the request body was already monitored.
+		bodyMonitored atomic.Bool
 	}
 
 	// SDKBodyOperation type representing an SDK body
@@ -33,6 +36,17 @@ type (
 	}
 )
 
+// SetBodyMonitored records that the request body was monitored, i.e. that it
+// was sent to the WAF by an SDK body operation.
+func (op *Operation) SetBodyMonitored() {
+	op.bodyMonitored.Store(true)
+}
+
+// BodyMonitored returns true when the request body was already monitored.
+func (op *Operation) BodyMonitored() bool {
+	return op.bodyMonitored.Load()
+}
+
 // Finish the HTTP handler operation, along with the given results and emits a
 // finish event up in the operation stack.
 func (op *Operation) Finish(res HandlerOperationRes) []any {
@@ -65,6 +79,12 @@ type (
 		Headers map[string][]string
 		// Status corresponds to the address `server.response.status`.
 		Status int
+		// Body corresponds to the address `server.response.body`. It is only set when the response was held back by
+		// the handler instrumentation, in which case the response can still be blocked or redacted.
+		Body any
+		// PathParams corresponds to the address `server.request.path_params` when the path parameters are only known
+		// once the handler returned. It is only set when the response was held back by the handler instrumentation.
+		PathParams map[string]string
 	}
 
 	// SDKBodyOperationArgs is the SDK body operation arguments.
@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package nosqlsec
+
+import (
+	"context"
+	"sync"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec/events"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/nosqlsec/types"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+var badNoSQLContextOnce sync.Once
+
+// ProtectNoSQLOperation runs the ASM RASP NoSQL injection checks on the
+// database command described by args. If executing it is unsafe, an
+// *events.BlockingSecurityEvent is returned.
+func ProtectNoSQLOperation(ctx context.Context, args types.NoSQLOperationArgs) error {
+	parent, _ := ctx.Value(listener.ContextKey{}).(dyngo.Operation)
+	if parent == nil { // No parent operation => we can't monitor the request
+		badNoSQLContextOnce.Do(func() {
+			log.Debug("appsec: outgoing NoSQL operation monitoring ignored: could not find the handler " +
+				"instrumentation metadata in the request context: the request handler is not being monitored by a " +
+				"middleware function or the incoming request context has not be forwarded correctly to the NoSQL command")
+		})
+		return nil
+	}
+
+	op := &types.NoSQLOperation{
+		Operation: dyngo.NewOperation(parent),
+	}
+
+	var err *events.BlockingSecurityEvent
+	dyngo.OnData(op, func(e *events.BlockingSecurityEvent) {
+		err = e
+	})
+
+	dyngo.StartOperation(op, args)
+	dyngo.FinishOperation(op, types.NoSQLOperationRes{})
+
+	if err != nil {
+		log.Debug("appsec: outgoing NoSQL operation blocked by the WAF")
+		return err
+	}
+
+	return nil
+}
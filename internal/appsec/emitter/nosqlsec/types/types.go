@@ -0,0 +1,31 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package types
+
+import (
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+)
+
+type (
+	// NoSQLOperation type embodies any kind of NoSQL database command filtering documents
+	NoSQLOperation struct {
+		dyngo.Operation
+	}
+
+	NoSQLOperationArgs struct {
+		// Filter is the filter document of the command, decoded into maps, slices and scalar values. It corresponds
+		// to the address `server.db.nosql.filter`.
+		Filter any
+		// Command is the name of the database command, such as find, aggregate or update.
+		Command string
+		// System corresponds to the address `server.db.system`
+		System string
+	}
+	NoSQLOperationRes struct{}
+)
+
+func (NoSQLOperationArgs) IsArgOf(*NoSQLOperation)   {}
+func (NoSQLOperationRes) IsResultOf(*NoSQLOperation) {}
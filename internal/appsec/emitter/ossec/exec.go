@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package ossec
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec/events"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/ossec/types"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+var badExecContextOnce sync.Once
+
+// shells lists the programs whose -c argument is a command line interpreted by a shell.
+var shells = map[string]struct{}{
+	"sh":   {},
+	"bash": {},
+	"dash": {},
+	"ash":  {},
+	"ksh":  {},
+	"mksh": {},
+	"zsh":  {},
+	"csh":  {},
+	"tcsh": {},
+	"fish": {},
+}
+
+// ProtectExec runs the ASM RASP command and shell injection checks on the
+// process about to be started with the command line cmd, cmd[0] being the
+// program name. If starting it is unsafe, an *events.BlockingSecurityEvent is
+// returned.
+func ProtectExec(ctx context.Context, cmd []string) error {
+	if len(cmd) == 0 {
+		return nil
+	}
+
+	parent, _ := ctx.Value(listener.ContextKey{}).(dyngo.Operation)
+	if parent == nil { // No parent operation => we can't monitor the request
+		badExecContextOnce.Do(func() {
+			log.Debug("appsec: command execution monitoring ignored: could not find the handler " +
+				"instrumentation metadata in the request context: the request handler is not being monitored by a " +
+				"middleware function or the incoming request context has not be forwarded correctly to the command")
+		})
+		return nil
+	}
+
+	op := &types.ExecOperation{
+		Operation: dyngo.NewOperation(parent),
+	}
+
+	var err *events.BlockingSecurityEvent
+	dyngo.OnData(op, func(e *events.BlockingSecurityEvent) {
+		err = e
+	})
+
+	dyngo.StartOperation(op, types.ExecOperationArgs{
+		Cmd:   cmd,
+		Shell: shellCommand(cmd),
+	})
+	dyngo.FinishOperation(op, types.ExecOperationRes{})
+
+	if err != nil {
+		log.Debug("appsec: command execution blocked by the WAF: %s", cmd[0])
+		return err
+	}
+
+	return nil
+}
+
+// shellCommand returns the command line passed to the shell if cmd invokes a
+// shell with the -c option, such as `sh -c "echo hello"`, or an empty string
+// otherwise.
+func shellCommand(cmd []string) string {
+	if _, ok := shells[filepath.Base(cmd[0])]; !ok {
+		return ""
+	}
+	for i := 1; i < len(cmd); i++ {
+		switch arg := cmd[i]; {
+		case arg == "-o" || arg == "+o":
+			// The next argument is the option name
+			i++
+		case strings.HasPrefix(arg, "--") && arg != "--":
+			// Long options such as --login
+		case strings.HasPrefix(arg, "-") && arg != "-" && arg != "--":
+			if strings.ContainsRune(arg, 'c') && i+1 < len(cmd) {
+				return cmd[i+1]
+			}
+		default:
+			// End of the shell options: the remaining arguments are a script and its arguments
+			return ""
+		}
+	}
+	return ""
+}
@@ -0,0 +1,31 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package ossec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellCommand(t *testing.T) {
+	for _, tc := range []struct {
+		cmd      []string
+		expected string
+	}{
+		{cmd: []string{"sh", "-c", "ls -l"}, expected: "ls -l"},
+		{cmd: []string{"/bin/bash", "-ec", "ls -l"}, expected: "ls -l"},
+		{cmd: []string{"bash", "--login", "-c", "ls -l", "bash"}, expected: "ls -l"},
+		{cmd: []string{"bash", "-o", "pipefail", "-c", "ls -l"}, expected: "ls -l"},
+		{cmd: []string{"sh", "script.sh", "-c", "ls -l"}},
+		{cmd: []string{"sh", "-c"}},
+		{cmd: []string{"sh", "--", "-c", "ls -l"}},
+		{cmd: []string{"ls", "-c", "ls -l"}},
+		{cmd: []string{"convert", "in.png", "out.jpg"}},
+	} {
+		require.Equal(t, tc.expected, shellCommand(tc.cmd), "%v", tc.cmd)
+	}
+}
@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package ossec
+
+import (
+	"context"
+	"io/fs"
+	"sync"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec/events"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/ossec/types"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+var badInputContextOnce sync.Once
+
+// ProtectOpen runs the ASM RASP LFI checks on the file about to be opened. If
+// opening it is unsafe, an *events.BlockingSecurityEvent is returned.
+func ProtectOpen(ctx context.Context, path string, flags int, perms fs.FileMode) error {
+	opArgs := types.OpenOperationArgs{
+		Path:  path,
+		Flags: flags,
+		Perms: perms,
+	}
+
+	parent, _ := ctx.Value(listener.ContextKey{}).(dyngo.Operation)
+	if parent == nil { // No parent operation => we can't monitor the request
+		badInputContextOnce.Do(func() {
+			log.Debug("appsec: file opening monitoring ignored: could not find the handler " +
+				"instrumentation metadata in the request context: the request handler is not being monitored by a " +
+				"middleware function or the incoming request context has not be forwarded correctly to the file opening function")
+		})
+		return nil
+	}
+
+	op := &types.OpenOperation{
+		Operation: dyngo.NewOperation(parent),
+	}
+
+	var err *events.BlockingSecurityEvent
+	dyngo.OnData(op, func(e *events.BlockingSecurityEvent) {
+		err = e
+	})
+
+	dyngo.StartOperation(op, opArgs)
+	dyngo.FinishOperation(op, types.OpenOperationRes{})
+
+	if err != nil {
+		log.Debug("appsec: file opening blocked by the WAF on path: %s", path)
+		return err
+	}
+
+	return nil
+}
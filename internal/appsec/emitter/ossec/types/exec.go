@@ -0,0 +1,30 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package types
+
+import (
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+)
+
+type (
+	// ExecOperation type embodies any kind of function call that will result in the execution of a new process
+	ExecOperation struct {
+		dyngo.Operation
+	}
+
+	ExecOperationArgs struct {
+		// Cmd is the command line of the process, the first element being the program name. It corresponds to
+		// the address `server.sys.exec.cmd`.
+		Cmd []string
+		// Shell is the command run by a shell invoked with -c, if any. It corresponds to the address
+		// `server.sys.shell.cmd`.
+		Shell string
+	}
+	ExecOperationRes struct{}
+)
+
+func (ExecOperationArgs) IsArgOf(*ExecOperation)   {}
+func (ExecOperationRes) IsResultOf(*ExecOperation) {}
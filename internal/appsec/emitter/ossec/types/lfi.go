@@ -0,0 +1,30 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package types
+
+import (
+	"io/fs"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+)
+
+type (
+	// OpenOperation type embodies any kind of function call that will result in a call to an open(2) syscall
+	OpenOperation struct {
+		dyngo.Operation
+	}
+
+	OpenOperationArgs struct {
+		// Path corresponds to the address `server.io.fs.file`
+		Path  string
+		Flags int
+		Perms fs.FileMode
+	}
+	OpenOperationRes struct{}
+)
+
+func (OpenOperationArgs) IsArgOf(*OpenOperation)   {}
+func (OpenOperationRes) IsResultOf(*OpenOperation) {}
@@ -57,16 +57,29 @@ type (
 	// HTTPAction are actions that interact with an HTTP request flow (block, redirect...)
 	HTTPAction struct {
 		http.Handler
+		// Params are the parameters of the action, given to the custom blocking handler, if any.
+		Params events.BlockingAction
 	}
 	// GRPCAction are actions that interact with a GRPC request flow
 	GRPCAction struct {
 		GRPCWrapper
+		// Params are the parameters of the action, given to the custom blocking error function, if any.
+		Params events.BlockingAction
 	}
 	// StackTraceAction are actions that generate a stacktrace
 	StackTraceAction struct {
 		Event stacktrace.Event
 	}
 
+	// RedactAction are actions that redact the values of the response body matched by the WAF. They only apply to
+	// responses held back until the WAF inspected them.
+	RedactAction struct {
+		// KeyPaths are the paths of the values to redact in the response body.
+		KeyPaths [][]string
+		// Replacement is the value replacing the redacted values.
+		Replacement string
+	}
+
 	// GRPCWrapper is an opaque prototype abstraction for a gRPC handler (to avoid importing grpc)
 	// that returns a status code and an error
 	// TODO: rely on strongly typed actions (with the actual grpc types) by introducing WAF constructors
@@ -80,18 +93,30 @@ type (
 	blockActionParams struct {
 		// GRPCStatusCode is the gRPC status code to be returned. Since 0 is the OK status, the value is nullable to
 		// be able to distinguish between unset and defaulting to Abort (10), or set to OK (0).
-		GRPCStatusCode *int   `mapstructure:"grpc_status_code,omitempty"`
-		StatusCode     int    `mapstructure:"status_code"`
-		Type           string `mapstructure:"type,omitempty"`
+		GRPCStatusCode     *int   `mapstructure:"grpc_status_code,omitempty"`
+		StatusCode         int    `mapstructure:"status_code"`
+		Type               string `mapstructure:"type,omitempty"`
+		SecurityResponseID string `mapstructure:"security_response_id,omitempty"`
 	}
 	// redirectActionParams are the dynamic parameters to be provided to a "redirect_request"
 	// action type upon invocation
 	redirectActionParams struct {
-		Location   string `mapstructure:"location,omitempty"`
-		StatusCode int    `mapstructure:"status_code"`
+		Location           string `mapstructure:"location,omitempty"`
+		StatusCode         int    `mapstructure:"status_code"`
+		SecurityResponseID string `mapstructure:"security_response_id,omitempty"`
+	}
+
+	// redactActionParams are the dynamic parameters to be provided to a "redact_response"
+	// action type upon invocation
+	redactActionParams struct {
+		Replacement string `mapstructure:"replacement,omitempty"`
 	}
 )
 
+// DefaultRedactionReplacement is the value replacing the redacted response body values when the "redact_response"
+// action doesn't specify any.
+const DefaultRedactionReplacement = "<Redacted>"
+
 func (a *HTTPAction) Blocking() bool              { return true }
 func (a *HTTPAction) EmitData(op dyngo.Operation) { dyngo.EmitData(op, a) }
 
@@ -101,6 +126,9 @@ func (a *GRPCAction) EmitData(op dyngo.Operation) { dyngo.EmitData(op, a) }
 func (a *StackTraceAction) Blocking() bool              { return false }
 func (a *StackTraceAction) EmitData(op dyngo.Operation) { dyngo.EmitData(op, a) }
 
+func (a *RedactAction) Blocking() bool              { return false }
+func (a *RedactAction) EmitData(op dyngo.Operation) { dyngo.EmitData(op, a) }
+
 // NewStackTraceAction creates an action for the "stacktrace" action type
 func NewStackTraceAction(params map[string]any) Action {
 	id, ok := params["stack_id"]
@@ -127,10 +155,13 @@ func NewBlockAction(params map[string]any) []Action {
 		log.Debug("appsec: couldn't decode redirect action parameters")
 		return nil
 	}
-	return []Action{
-		newHTTPBlockRequestAction(p.StatusCode, p.Type),
-		newGRPCBlockRequestAction(*p.GRPCStatusCode),
-	}
+	httpAction := newHTTPBlockRequestAction(p.StatusCode, p.Type)
+	httpAction.Params.GRPCStatusCode = *p.GRPCStatusCode
+	httpAction.Params.SecurityResponseID = p.SecurityResponseID
+	grpcAction := newGRPCBlockRequestAction(*p.GRPCStatusCode)
+	grpcAction.Params.StatusCode = p.StatusCode
+	grpcAction.Params.SecurityResponseID = p.SecurityResponseID
+	return []Action{httpAction, grpcAction}
 }
 
 // NewRedirectAction creates an action for the "redirect_request" action type
@@ -140,16 +171,37 @@ func NewRedirectAction(params map[string]any) *HTTPAction {
 		log.Debug("appsec: couldn't decode redirect action parameters")
 		return nil
 	}
-	return newRedirectRequestAction(p.StatusCode, p.Location)
+	a := newRedirectRequestAction(p.StatusCode, p.Location)
+	a.Params.SecurityResponseID = p.SecurityResponseID
+	return a
+}
+
+// NewRedactAction creates an action for the "redact_response" action type, redacting the values found at the given
+// key paths of the response body
+func NewRedactAction(params map[string]any, keyPaths [][]string) *RedactAction {
+	var p redactActionParams
+	if err := mapstructure.WeakDecode(params, &p); err != nil {
+		log.Debug("appsec: couldn't decode redact action parameters")
+		return nil
+	}
+	if p.Replacement == "" {
+		p.Replacement = DefaultRedactionReplacement
+	}
+	return &RedactAction{KeyPaths: keyPaths, Replacement: p.Replacement}
 }
 
 func newHTTPBlockRequestAction(status int, template string) *HTTPAction {
-	return &HTTPAction{Handler: newBlockHandler(status, template)}
+	return &HTTPAction{
+		Handler: newBlockHandler(status, template),
+		Params:  events.BlockingAction{Type: "block_request", StatusCode: status},
+	}
 }
 
 func newGRPCBlockRequestAction(status int) *GRPCAction {
-	return &GRPCAction{GRPCWrapper: newGRPCBlockHandler(status)}
-
+	return &GRPCAction{
+		GRPCWrapper: newGRPCBlockHandler(status),
+		Params:      events.BlockingAction{Type: "block_request", GRPCStatusCode: status},
+	}
 }
 
 func newRedirectRequestAction(status int, loc string) *HTTPAction {
@@ -160,9 +212,15 @@ func newRedirectRequestAction(status int, loc string) *HTTPAction {
 
 	// If location is not set we fall back on a default block action
 	if loc == "" {
-		return &HTTPAction{Handler: newBlockHandler(403, string(blockedTemplateJSON))}
+		return &HTTPAction{
+			Handler: newBlockHandler(403, string(blockedTemplateJSON)),
+			Params:  events.BlockingAction{Type: "block_request", StatusCode: 403},
+		}
+	}
+	return &HTTPAction{
+		Handler: http.RedirectHandler(loc, status),
+		Params:  events.BlockingAction{Type: "redirect_request", StatusCode: status, Location: loc},
 	}
-	return &HTTPAction{Handler: http.RedirectHandler(loc, status)}
 }
 
 // newBlockHandler creates, initializes and returns a new BlockRequestAction
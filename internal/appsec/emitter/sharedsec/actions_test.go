@@ -6,11 +6,14 @@
 package sharedsec
 
 import (
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec/events"
+
 	"github.com/stretchr/testify/require"
 )
 
@@ -313,3 +316,85 @@ func TestNewRedirectParams(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockingActionParams(t *testing.T) {
+	t.Run("block", func(t *testing.T) {
+		actions := NewBlockAction(map[string]any{
+			"status_code":          "401",
+			"grpc_status_code":     "7",
+			"type":                 "json",
+			"security_response_id": "0123-4567",
+		})
+		require.Len(t, actions, 2)
+		expected := events.BlockingAction{
+			Type:               "block_request",
+			StatusCode:         401,
+			GRPCStatusCode:     7,
+			SecurityResponseID: "0123-4567",
+		}
+		require.Equal(t, expected, actions[0].(*HTTPAction).Params)
+		require.Equal(t, expected, actions[1].(*GRPCAction).Params)
+	})
+
+	t.Run("redirect", func(t *testing.T) {
+		a := NewRedirectAction(map[string]any{"status_code": 100, "location": "/blocked"})
+		require.Equal(t, events.BlockingAction{Type: "redirect_request", StatusCode: 303, Location: "/blocked"}, a.Params)
+	})
+
+	t.Run("redirect-no-location", func(t *testing.T) {
+		a := NewRedirectAction(map[string]any{"status_code": 302})
+		require.Equal(t, events.BlockingAction{Type: "block_request", StatusCode: 403}, a.Params)
+	})
+}
+
+func TestBlockingHandler(t *testing.T) {
+	a := newHTTPBlockRequestAction(403, "json")
+	a.Params.SecurityResponseID = "0123-4567"
+
+	serve := func(h http.Handler) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w
+	}
+
+	t.Run("default", func(t *testing.T) {
+		w := serve(a.BlockingHandler("/users/:id"))
+		require.Equal(t, 403, w.Code)
+		require.Equal(t, blockedTemplateJSON, w.Body.Bytes())
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		SetHTTPBlockingHandler(func(a events.BlockingAction) http.Handler {
+			if a.Route != "/users/:id" {
+				return nil
+			}
+			return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(a.StatusCode)
+				w.Write([]byte(`{"error":"forbidden","id":"` + a.SecurityResponseID + `"}`))
+			})
+		})
+		defer SetHTTPBlockingHandler(nil)
+
+		w := serve(a.BlockingHandler("/users/:id"))
+		require.Equal(t, 403, w.Code)
+		require.Equal(t, `{"error":"forbidden","id":"0123-4567"}`, w.Body.String())
+
+		// Fallback to the default response
+		w = serve(a.BlockingHandler("/"))
+		require.Equal(t, 403, w.Code)
+		require.Equal(t, blockedTemplateJSON, w.Body.Bytes())
+	})
+}
+
+func TestBlockingError(t *testing.T) {
+	a := newGRPCBlockRequestAction(10)
+	require.NoError(t, a.BlockingError("/grpc.Service/Method"))
+
+	customErr := errors.New("custom error")
+	SetGRPCBlockingError(func(a events.BlockingAction) error {
+		require.Equal(t, events.BlockingAction{Type: "block_request", GRPCStatusCode: 10, Route: "/grpc.Service/Method"}, a)
+		return customErr
+	})
+	defer SetGRPCBlockingError(nil)
+	require.Equal(t, customErr, a.BlockingError("/grpc.Service/Method"))
+}
@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package sharedsec
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec/events"
+)
+
+type (
+	// HTTPBlockingHandlerFunc returns the handler writing the response of the requests blocked by the given action,
+	// or nil to use the default blocking response.
+	HTTPBlockingHandlerFunc func(a events.BlockingAction) http.Handler
+	// GRPCBlockingErrorFunc returns the error returned by the RPCs blocked by the given action, or nil to use the
+	// default one.
+	GRPCBlockingErrorFunc func(a events.BlockingAction) error
+)
+
+var (
+	httpBlockingHandler atomic.Pointer[HTTPBlockingHandlerFunc]
+	grpcBlockingError   atomic.Pointer[GRPCBlockingErrorFunc]
+)
+
+// SetHTTPBlockingHandler sets the function customizing the HTTP blocking responses. A nil function removes the current
+// one.
+func SetHTTPBlockingHandler(fn HTTPBlockingHandlerFunc) {
+	if fn == nil {
+		httpBlockingHandler.Store(nil)
+		return
+	}
+	httpBlockingHandler.Store(&fn)
+}
+
+// SetGRPCBlockingError sets the function customizing the gRPC blocking errors. A nil function removes the current
+// one.
+func SetGRPCBlockingError(fn GRPCBlockingErrorFunc) {
+	if fn == nil {
+		grpcBlockingError.Store(nil)
+		return
+	}
+	grpcBlockingError.Store(&fn)
+}
+
+// BlockingHandler returns the handler writing the blocking response of the given request route: the one returned by
+// the custom HTTP blocking handler function if any, or the default one of the action otherwise.
+func (a *HTTPAction) BlockingHandler(route string) http.Handler {
+	if fn := httpBlockingHandler.Load(); fn != nil {
+		params := a.Params
+		params.Route = route
+		if h := (*fn)(params); h != nil {
+			return h
+		}
+	}
+	return a.Handler
+}
+
+// BlockingError returns the error returned by the custom gRPC blocking error function for the given RPC method, if
+// any, or nil otherwise.
+func (a *GRPCAction) BlockingError(method string) error {
+	fn := grpcBlockingError.Load()
+	if fn == nil {
+		return nil
+	}
+	params := a.Params
+	params.Route = method
+	return (*fn)(params)
+}
@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package sharedsec
+
+import (
+	"context"
+	"sort"
+	"sync/atomic"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec/events"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+)
+
+// SecurityEventCallback is the function called with every security event detected by the WAF.
+type SecurityEventCallback func(ctx context.Context, e events.SecurityEvent)
+
+var securityEventCallback atomic.Pointer[SecurityEventCallback]
+
+// SetSecurityEventCallback sets the function called with every security event detected by the WAF. A nil function
+// removes the current one.
+func SetSecurityEventCallback(fn SecurityEventCallback) {
+	if fn == nil {
+		securityEventCallback.Store(nil)
+		return
+	}
+	securityEventCallback.Store(&fn)
+}
+
+// SecurityEvents is the data emitted by the WAF listeners along with the security events returned by a WAF run,
+// regardless of the trace rate limiting.
+type SecurityEvents struct {
+	// Events are the security events returned by the WAF.
+	Events []any
+	// Actions are the actions returned by the WAF along with the security events, indexed by action type.
+	Actions map[string]any
+}
+
+// OnSecurityEvents registers a data listener on the given request handler operation reporting the security events
+// emitted by the WAF listeners to the security event callback, along with the given request metadata. Nothing is
+// registered when no callback is set.
+func OnSecurityEvents(op dyngo.Operation, ctx context.Context, clientIP string, route string) {
+	if securityEventCallback.Load() == nil {
+		return
+	}
+	dyngo.OnData(op, func(e *SecurityEvents) {
+		fn := securityEventCallback.Load()
+		if fn == nil {
+			return
+		}
+		actions := make([]string, 0, len(e.Actions))
+		for aType := range e.Actions {
+			actions = append(actions, aType)
+		}
+		sort.Strings(actions)
+		_, blocked := e.Actions["block_request"]
+		if _, redirected := e.Actions["redirect_request"]; redirected {
+			blocked = true
+		}
+		for _, event := range e.Events {
+			event, ok := makeSecurityEvent(event)
+			if !ok {
+				log.Debug("appsec: unexpected security event format: %v", event)
+				continue
+			}
+			event.ClientIP = clientIP
+			event.Route = route
+			event.Actions = actions
+			event.Blocked = blocked
+			(*fn)(ctx, event)
+		}
+	})
+}
+
+// makeSecurityEvent returns the SecurityEvent out of a security event returned by the WAF.
+func makeSecurityEvent(v any) (events.SecurityEvent, bool) {
+	var e events.SecurityEvent
+	event, ok := v.(map[string]any)
+	if !ok {
+		return e, false
+	}
+	rule, ok := event["rule"].(map[string]any)
+	if !ok {
+		return e, false
+	}
+	e.RuleID, _ = rule["id"].(string)
+	e.RuleName, _ = rule["name"].(string)
+	if tags, ok := rule["tags"].(map[string]any); ok {
+		e.Category, _ = tags["category"].(string)
+	}
+	matches, _ := event["rule_matches"].([]any)
+	for _, match := range matches {
+		match, _ := match.(map[string]any)
+		params, _ := match["parameters"].([]any)
+		for _, param := range params {
+			param, _ := param.(map[string]any)
+			if addr, ok := param["address"].(string); ok && !contains(e.Addresses, addr) {
+				e.Addresses = append(e.Addresses, addr)
+			}
+		}
+	}
+	return e, true
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
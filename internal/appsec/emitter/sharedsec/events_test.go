@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package sharedsec
+
+import (
+	"context"
+	"testing"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/appsec/events"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnSecurityEvents(t *testing.T) {
+	wafEvents := []any{
+		map[string]any{
+			"rule": map[string]any{
+				"id":       "crs-942-100",
+				"name":     "SQL Injection Attack Detected via libinjection",
+				"tags":     map[string]any{"category": "attack_attempt", "type": "sql_injection"},
+				"on_match": []any{"block"},
+			},
+			"rule_matches": []any{
+				map[string]any{
+					"operator": "is_sqli",
+					"parameters": []any{
+						map[string]any{"address": "server.request.query", "key_path": []any{"q"}, "value": "1' OR 1=1"},
+						map[string]any{"address": "server.request.body", "key_path": []any{}, "value": "1' OR 1=1"},
+					},
+				},
+				map[string]any{
+					"operator": "is_sqli",
+					"parameters": []any{
+						map[string]any{"address": "server.request.query", "key_path": []any{"p"}, "value": "1' OR 1=1"},
+					},
+				},
+			},
+		},
+		"unexpected",
+	}
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+
+	t.Run("no-callback", func(t *testing.T) {
+		op := dyngo.NewOperation(nil)
+		OnSecurityEvents(op, ctx, "1.2.3.4", "/")
+		// The callback set afterwards doesn't apply to the operation
+		called := false
+		SetSecurityEventCallback(func(context.Context, events.SecurityEvent) { called = true })
+		defer SetSecurityEventCallback(nil)
+		dyngo.EmitData(op, &SecurityEvents{Events: wafEvents})
+		require.False(t, called)
+	})
+
+	t.Run("callback", func(t *testing.T) {
+		var reported []events.SecurityEvent
+		SetSecurityEventCallback(func(ctx context.Context, e events.SecurityEvent) {
+			require.Equal(t, "request", ctx.Value(ctxKey{}))
+			reported = append(reported, e)
+		})
+		defer SetSecurityEventCallback(nil)
+
+		op := dyngo.NewOperation(nil)
+		OnSecurityEvents(op, ctx, "1.2.3.4", "/search")
+		// The events emitted by the child operations are reported too
+		dyngo.EmitData(dyngo.NewOperation(op), &SecurityEvents{
+			Events:  wafEvents,
+			Actions: map[string]any{"generate_stack": nil, "block_request": nil},
+		})
+		require.Equal(t, []events.SecurityEvent{{
+			RuleID:    "crs-942-100",
+			RuleName:  "SQL Injection Attack Detected via libinjection",
+			Category:  "attack_attempt",
+			Addresses: []string{"server.request.query", "server.request.body"},
+			ClientIP:  "1.2.3.4",
+			Route:     "/search",
+			Actions:   []string{"block_request", "generate_stack"},
+			Blocked:   true,
+		}}, reported)
+	})
+}
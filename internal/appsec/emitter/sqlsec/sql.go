@@ -18,7 +18,20 @@ import (
 
 var badInputContextOnce sync.Once
 
+// protectedQueryKey is the context key of the query already checked by ProtectSQLOperation.
+type protectedQueryKey struct{}
+
+// WithProtectedQuery returns a copy of ctx recording that query was already checked by ProtectSQLOperation, so that
+// it is not checked again when run through several integrations, such as gorm on top of database/sql.
+func WithProtectedQuery(ctx context.Context, query string) context.Context {
+	return context.WithValue(ctx, protectedQueryKey{}, query)
+}
+
 func ProtectSQLOperation(ctx context.Context, query, driver string) error {
+	if q, _ := ctx.Value(protectedQueryKey{}).(string); q == query {
+		return nil
+	}
+
 	opArgs := types.SQLOperationArgs{
 		Query:  query,
 		Driver: driver,
@@ -108,7 +108,7 @@ func (l *wafEventListener) onEvent(request *types.RequestOperation, _ types.Requ
 						},
 					},
 				)
-				shared.AddSecurityEvents(&field.SecurityEventsHolder, l.limiter, wafResult.Events)
+				shared.AddSecurityEvents(field, &field.SecurityEventsHolder, l.limiter, wafResult.Events, wafResult.Actions)
 			}
 
 			dyngo.OnFinish(field, func(field *types.ResolveOperation, res types.ResolveOperationRes) {
@@ -17,6 +17,8 @@ import (
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/sharedsec"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener/httpsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener/nosqlsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener/ossec"
 	shared "gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener/sharedsec"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/samplernames"
@@ -34,12 +36,16 @@ const (
 
 // List of gRPC rule addresses currently supported by the WAF
 var supportedAddresses = listener.AddressSet{
-	GRPCServerMethodAddr:          {},
-	GRPCServerRequestMessageAddr:  {},
-	GRPCServerRequestMetadataAddr: {},
-	httpsec.HTTPClientIPAddr:      {},
-	httpsec.UserIDAddr:            {},
-	httpsec.ServerIoNetURLAddr:    {},
+	GRPCServerMethodAddr:             {},
+	GRPCServerRequestMessageAddr:     {},
+	GRPCServerRequestMetadataAddr:    {},
+	httpsec.HTTPClientIPAddr:         {},
+	httpsec.UserIDAddr:               {},
+	httpsec.ServerIoNetURLAddr:       {},
+	ossec.ServerIOFSFileAddr:         {},
+	ossec.ServerSysExecCmdAddr:       {},
+	ossec.ServerSysShellCmdAddr:      {},
+	nosqlsec.ServerDBNoSQLFilterAddr: {},
 }
 
 // Install registers the gRPC WAF Event Listener on the given root operation.
@@ -89,7 +95,7 @@ func (l *wafEventListener) onEvent(op *types.HandlerOperation, handlerArgs types
 		nbEvents atomic.Uint32
 		logOnce  sync.Once // per request
 	)
-	addEvents := func(events []any) {
+	addEvents := func(events []any, actions map[string]any) {
 		const maxWAFEventsPerRequest = 10
 		if nbEvents.Load() >= maxWAFEventsPerRequest {
 			logOnce.Do(func() {
@@ -98,7 +104,7 @@ func (l *wafEventListener) onEvent(op *types.HandlerOperation, handlerArgs types
 			return
 		}
 		nbEvents.Add(uint32(len(events)))
-		shared.AddSecurityEvents(&op.SecurityEventsHolder, l.limiter, events)
+		shared.AddSecurityEvents(op, &op.SecurityEventsHolder, l.limiter, events, actions)
 	}
 
 	wafCtx, err := l.wafHandle.NewContextWithBudget(l.config.WAFTimeout)
@@ -116,6 +122,18 @@ func (l *wafEventListener) onEvent(op *types.HandlerOperation, handlerArgs types
 		httpsec.RegisterRoundTripperListener(op, &op.SecurityEventsHolder, wafCtx, l.limiter)
 	}
 
+	if _, ok := l.addresses[ossec.ServerIOFSFileAddr]; ok {
+		ossec.RegisterOpenListener(op, &op.SecurityEventsHolder, wafCtx, l.limiter)
+	}
+
+	if ossec.ExecAddressesPresent(l.addresses) {
+		ossec.RegisterExecListener(op, &op.SecurityEventsHolder, wafCtx, l.limiter)
+	}
+
+	if _, ok := l.addresses[nosqlsec.ServerDBNoSQLFilterAddr]; ok {
+		nosqlsec.RegisterNoSQLListener(op, &op.SecurityEventsHolder, wafCtx, l.limiter)
+	}
+
 	// Listen to the UserID address if the WAF rules are using it
 	if l.isSecAddressListened(httpsec.UserIDAddr) {
 		// UserIDOperation happens when appsec.SetUser() is called. We run the WAF and apply actions to
@@ -127,7 +145,7 @@ func (l *wafEventListener) onEvent(op *types.HandlerOperation, handlerArgs types
 			}
 			wafResult := shared.RunWAF(wafCtx, waf.RunAddressData{Persistent: values})
 			if wafResult.HasEvents() {
-				addEvents(wafResult.Events)
+				addEvents(wafResult.Events, wafResult.Actions)
 				log.Debug("appsec: WAF detected an authenticated user attack: %s", args.UserID)
 			}
 			if wafResult.HasActions() {
@@ -147,7 +165,7 @@ func (l *wafEventListener) onEvent(op *types.HandlerOperation, handlerArgs types
 
 	wafResult := shared.RunWAF(wafCtx, waf.RunAddressData{Persistent: values})
 	if wafResult.HasEvents() {
-		addEvents(wafResult.Events)
+		addEvents(wafResult.Events, wafResult.Actions)
 		log.Debug("appsec: WAF detected an attack before executing the request")
 	}
 	if wafResult.HasActions() {
@@ -180,7 +198,7 @@ func (l *wafEventListener) onEvent(op *types.HandlerOperation, handlerArgs types
 		wafResult := shared.RunWAF(wafCtx, values)
 		if wafResult.HasEvents() {
 			log.Debug("appsec: attack detected by the grpc waf")
-			addEvents(wafResult.Events)
+			addEvents(wafResult.Events, wafResult.Actions)
 		}
 		if wafResult.HasActions() {
 			shared.ProcessActions(op, wafResult.Actions)
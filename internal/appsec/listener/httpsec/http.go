@@ -16,6 +16,8 @@ import (
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/httpsec/types"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/sharedsec"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener/nosqlsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener/ossec"
 	shared "gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener/sharedsec"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener/sqlsec"
 	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
@@ -25,6 +27,9 @@ import (
 	waf "github.com/DataDog/go-libddwaf/v3"
 )
 
+// redactResponseActionType is the type of the WAF action redacting the response body values matched by the WAF
+const redactResponseActionType = "redact_response"
+
 // HTTP rule addresses currently supported by the WAF
 const (
 	ServerRequestMethodAddr            = "server.request.method"
@@ -36,6 +41,7 @@ const (
 	ServerRequestBodyAddr              = "server.request.body"
 	ServerResponseStatusAddr           = "server.response.status"
 	ServerResponseHeadersNoCookiesAddr = "server.response.headers.no_cookies"
+	ServerResponseBodyAddr             = "server.response.body"
 	HTTPClientIPAddr                   = "http.client_ip"
 	UserIDAddr                         = "usr.id"
 	ServerIoNetURLAddr                 = "server.io.net.url"
@@ -52,11 +58,16 @@ var supportedAddresses = listener.AddressSet{
 	ServerRequestBodyAddr:              {},
 	ServerResponseStatusAddr:           {},
 	ServerResponseHeadersNoCookiesAddr: {},
+	ServerResponseBodyAddr:             {},
 	HTTPClientIPAddr:                   {},
 	UserIDAddr:                         {},
 	ServerIoNetURLAddr:                 {},
 	sqlsec.ServerDBStatementAddr:       {},
 	sqlsec.ServerDBTypeAddr:            {},
+	ossec.ServerIOFSFileAddr:           {},
+	ossec.ServerSysExecCmdAddr:         {},
+	ossec.ServerSysShellCmdAddr:        {},
+	nosqlsec.ServerDBNoSQLFilterAddr:   {},
 }
 
 // Install registers the HTTP WAF Event Listener on the given root operation.
@@ -127,6 +138,18 @@ func (l *wafEventListener) onEvent(op *types.Operation, args types.HandlerOperat
 		sqlsec.RegisterSQLListener(op, &op.SecurityEventsHolder, wafCtx, l.limiter)
 	}
 
+	if _, ok := l.addresses[nosqlsec.ServerDBNoSQLFilterAddr]; ok {
+		nosqlsec.RegisterNoSQLListener(op, &op.SecurityEventsHolder, wafCtx, l.limiter)
+	}
+
+	if _, ok := l.addresses[ossec.ServerIOFSFileAddr]; ok {
+		ossec.RegisterOpenListener(op, &op.SecurityEventsHolder, wafCtx, l.limiter)
+	}
+
+	if ossec.ExecAddressesPresent(l.addresses) {
+		ossec.RegisterExecListener(op, &op.SecurityEventsHolder, wafCtx, l.limiter)
+	}
+
 	if _, ok := l.addresses[UserIDAddr]; ok {
 		// OnUserIDOperationStart happens when appsec.SetUser() is called. We run the WAF and apply actions to
 		// see if the associated user should be blocked. Since we don't control the execution flow in this case
@@ -135,7 +158,7 @@ func (l *wafEventListener) onEvent(op *types.Operation, args types.HandlerOperat
 			wafResult := shared.RunWAF(wafCtx, waf.RunAddressData{Persistent: map[string]any{UserIDAddr: args.UserID}})
 			if wafResult.HasActions() || wafResult.HasEvents() {
 				shared.ProcessActions(operation, wafResult.Actions)
-				shared.AddSecurityEvents(&op.SecurityEventsHolder, l.limiter, wafResult.Events)
+				shared.AddSecurityEvents(operation, &op.SecurityEventsHolder, l.limiter, wafResult.Events, wafResult.Actions)
 				log.Debug("appsec: WAF detected a suspicious user: %s", args.UserID)
 			}
 		})
@@ -182,7 +205,7 @@ func (l *wafEventListener) onEvent(op *types.Operation, args types.HandlerOperat
 	}
 	if wafResult.HasActions() || wafResult.HasEvents() {
 		interrupt := shared.ProcessActions(op, wafResult.Actions)
-		shared.AddSecurityEvents(&op.SecurityEventsHolder, l.limiter, wafResult.Events)
+		shared.AddSecurityEvents(op, &op.SecurityEventsHolder, l.limiter, wafResult.Events, wafResult.Actions)
 		log.Debug("appsec: WAF detected an attack before executing the request")
 		if interrupt {
 			wafCtx.Close()
@@ -198,7 +221,7 @@ func (l *wafEventListener) onEvent(op *types.Operation, args types.HandlerOperat
 			}
 			if wafResult.HasActions() || wafResult.HasEvents() {
 				shared.ProcessActions(sdkBodyOp, wafResult.Actions)
-				shared.AddSecurityEvents(&op.SecurityEventsHolder, l.limiter, wafResult.Events)
+				shared.AddSecurityEvents(sdkBodyOp, &op.SecurityEventsHolder, l.limiter, wafResult.Events, wafResult.Actions)
 				log.Debug("appsec: WAF detected a suspicious request body")
 			}
 		})
@@ -217,10 +240,23 @@ func (l *wafEventListener) onEvent(op *types.Operation, args types.HandlerOperat
 			values[ServerResponseHeadersNoCookiesAddr] = res.Headers
 		}
 
-		// Run the WAF, ignoring the returned actions - if any - since blocking after the request handler's
-		// response is not supported at the moment.
+		if _, ok := l.addresses[ServerResponseBodyAddr]; ok && res.Body != nil {
+			values[ServerResponseBodyAddr] = res.Body
+		}
+
+		if _, ok := l.addresses[ServerRequestPathParamsAddr]; ok && res.PathParams != nil {
+			values[ServerRequestPathParamsAddr] = res.PathParams
+		}
+
 		wafResult := shared.RunWAF(wafCtx, waf.RunAddressData{Persistent: values})
 
+		// The returned actions - if any - can only be applied when the response was held back by the handler
+		// instrumentation, which is the case when its body or the path parameters are provided. Otherwise the response
+		// was already sent.
+		if (res.Body != nil || res.PathParams != nil) && wafResult.HasActions() {
+			processResponseActions(op, wafResult)
+		}
+
 		// Add WAF metrics.
 		shared.AddWAFMonitoringTags(op, l.wafDiags.Version, wafCtx.Stats().Metrics())
 
@@ -233,7 +269,7 @@ func (l *wafEventListener) onEvent(op *types.Operation, args types.HandlerOperat
 		// Log the attacks if any
 		if wafResult.HasEvents() {
 			log.Debug("appsec: attack detected by the waf")
-			shared.AddSecurityEvents(&op.SecurityEventsHolder, l.limiter, wafResult.Events)
+			shared.AddSecurityEvents(op, &op.SecurityEventsHolder, l.limiter, wafResult.Events, wafResult.Actions)
 		}
 		for tag, value := range wafResult.Derivatives {
 			op.AddSerializableTag(tag, value)
@@ -241,6 +277,51 @@ func (l *wafEventListener) onEvent(op *types.Operation, args types.HandlerOperat
 	})
 }
 
+// processResponseActions sends the actions returned by the WAF for a held back response to the operation's data
+// listener. The "redact_response" action is built out of the response body values matched by the WAF.
+func processResponseActions(op dyngo.Operation, wafResult waf.Result) {
+	actions := wafResult.Actions
+	if params, ok := actions[redactResponseActionType]; ok {
+		p, _ := params.(map[string]any)
+		if a := sharedsec.NewRedactAction(p, responseBodyKeyPaths(wafResult.Events)); a != nil {
+			a.EmitData(op)
+		}
+		actions = make(map[string]any, len(wafResult.Actions)-1)
+		for aType, params := range wafResult.Actions {
+			if aType != redactResponseActionType {
+				actions[aType] = params
+			}
+		}
+	}
+	shared.ProcessActions(op, actions)
+}
+
+// responseBodyKeyPaths returns the key paths of the `server.response.body` values matched by the given WAF events.
+func responseBodyKeyPaths(events []any) [][]string {
+	var paths [][]string
+	for _, event := range events {
+		event, _ := event.(map[string]any)
+		matches, _ := event["rule_matches"].([]any)
+		for _, match := range matches {
+			match, _ := match.(map[string]any)
+			params, _ := match["parameters"].([]any)
+			for _, param := range params {
+				param, _ := param.(map[string]any)
+				if param["address"] != ServerResponseBodyAddr {
+					continue
+				}
+				keyPath, _ := param["key_path"].([]any)
+				path := make([]string, 0, len(keyPath))
+				for _, key := range keyPath {
+					path = append(path, fmt.Sprint(key))
+				}
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
 // canExtractSchemas checks that API Security is enabled and that sampling rate
 // allows extracting schemas
 func (l *wafEventListener) canExtractSchemas() bool {
@@ -27,6 +27,6 @@ func RegisterRoundTripperListener(op dyngo.Operation, events *trace.SecurityEven
 		log.Debug("appsec: WAF detected a suspicious outgoing request URL: %s", args.URL)
 
 		sharedsec.ProcessActions(op, wafResult.Actions)
-		sharedsec.AddSecurityEvents(events, limiter, wafResult.Events)
+		sharedsec.AddSecurityEvents(op, events, limiter, wafResult.Events, wafResult.Actions)
 	})
 }
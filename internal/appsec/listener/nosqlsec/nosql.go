@@ -0,0 +1,37 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package nosqlsec
+
+import (
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/nosqlsec/types"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener/sharedsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener/sqlsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/trace"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+
+	"github.com/DataDog/appsec-internal-go/limiter"
+	waf "github.com/DataDog/go-libddwaf/v3"
+)
+
+const ServerDBNoSQLFilterAddr = "server.db.nosql.filter"
+
+func RegisterNoSQLListener(op dyngo.Operation, events *trace.SecurityEventsHolder, wafCtx *waf.Context, limiter limiter.Limiter) {
+	dyngo.On(op, func(op *types.NoSQLOperation, args types.NoSQLOperationArgs) {
+		wafResult := sharedsec.RunWAF(wafCtx, waf.RunAddressData{Ephemeral: map[string]any{
+			ServerDBNoSQLFilterAddr: args.Filter,
+			sqlsec.ServerDBTypeAddr: args.System,
+		}})
+		if !wafResult.HasEvents() {
+			return
+		}
+
+		log.Debug("appsec: WAF detected a suspicious NoSQL operation")
+
+		sharedsec.ProcessActions(op, wafResult.Actions)
+		sharedsec.AddSecurityEvents(op, events, limiter, wafResult.Events, wafResult.Actions)
+	})
+}
@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package ossec
+
+import (
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/dyngo"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/emitter/ossec/types"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/listener/sharedsec"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/appsec/trace"
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+
+	"github.com/DataDog/appsec-internal-go/limiter"
+	waf "github.com/DataDog/go-libddwaf/v3"
+)
+
+const (
+	ServerSysExecCmdAddr  = "server.sys.exec.cmd"
+	ServerSysShellCmdAddr = "server.sys.shell.cmd"
+)
+
+// ExecAddressesPresent returns true if any of the command execution addresses is used by the WAF rules.
+func ExecAddressesPresent(addresses map[string]struct{}) bool {
+	_, execAddr := addresses[ServerSysExecCmdAddr]
+	_, shellAddr := addresses[ServerSysShellCmdAddr]
+
+	return execAddr || shellAddr
+}
+
+func RegisterExecListener(op dyngo.Operation, events *trace.SecurityEventsHolder, wafCtx *waf.Context, limiter limiter.Limiter) {
+	dyngo.On(op, func(op *types.ExecOperation, args types.ExecOperationArgs) {
+		// Every command is checked for command injections, and the commands run through a shell are also checked
+		// for shell injections, as the arguments of the shell can be used by the command line it runs.
+		data := map[string]any{ServerSysExecCmdAddr: args.Cmd}
+		if args.Shell != "" {
+			data[ServerSysShellCmdAddr] = args.Shell
+		}
+		wafResult := sharedsec.RunWAF(wafCtx, waf.RunAddressData{Ephemeral: data})
+		if !wafResult.HasEvents() {
+			return
+		}
+
+		log.Debug("appsec: WAF detected a suspicious command execution")
+
+		sharedsec.ProcessActions(op, wafResult.Actions)
+		sharedsec.AddSecurityEvents(op, events, limiter, wafResult.Events, wafResult.Actions)
+	})
+}
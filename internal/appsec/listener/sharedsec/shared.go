@@ -39,8 +39,14 @@ func RunWAF(wafCtx *waf.Context, values waf.RunAddressData) waf.Result {
 }
 
 // AddSecurityEvents is a helper function to add sec events to an operation taking into account the rate limiter.
-func AddSecurityEvents(holder *trace.SecurityEventsHolder, limiter limiter.Limiter, matches []any) {
-	if len(matches) > 0 && limiter.Allow() {
+// The events are also emitted on op, along with the actions returned by the WAF, for the security event callback
+// regardless of the rate limiter.
+func AddSecurityEvents(op dyngo.Operation, holder *trace.SecurityEventsHolder, limiter limiter.Limiter, matches []any, actions map[string]any) {
+	if len(matches) == 0 {
+		return
+	}
+	dyngo.EmitData(op, &sharedsec.SecurityEvents{Events: matches, Actions: actions})
+	if limiter.Allow() {
 		holder.AddSecurityEvents(matches)
 	}
 }
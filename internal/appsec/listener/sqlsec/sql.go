@@ -34,6 +34,6 @@ func RegisterSQLListener(op dyngo.Operation, events *trace.SecurityEventsHolder,
 		log.Debug("appsec: WAF detected a suspicious SQL operation")
 
 		sharedsec.ProcessActions(op, wafResult.Actions)
-		sharedsec.AddSecurityEvents(events, limiter, wafResult.Events)
+		sharedsec.AddSecurityEvents(op, events, limiter, wafResult.Events, wafResult.Actions)
 	})
 }
@@ -140,6 +140,7 @@ func TestPathway(t *testing.T) {
 			{"type::", true},
 			{":", false},
 			{"topic:arn:aws:sns:us-east-1:727006795293:dsm-dev-sns-topic", true},
+			{"has_routing_key:true", true},
 		} {
 			assert.Equal(t, isWellFormedEdgeTag(tc.s), tc.b)
 		}
@@ -44,6 +44,36 @@ type Backlog struct {
 	Value int64
 }
 
+// Schema represents a message schema sampled at the checkpoints of a topic.
+type Schema struct {
+	// Topic is the topic of the checkpoints.
+	Topic string
+	// Operation is either produce or consume, depending on the direction of
+	// the checkpoints.
+	Operation string
+	// Type is the type of the schema: avro, protobuf or json.
+	Type string
+	// ID identifies the schema.
+	ID string
+	// Name is the fully-qualified name of the schema.
+	Name string
+	// Definition is the definition of the schema fields.
+	Definition string
+	// Weight is the number of checkpoints the sample represents.
+	Weight int64
+}
+
+// Transaction represents a checkpoint of a transaction, such as a business
+// transaction identified by an order ID.
+type Transaction struct {
+	// ID identifies the transaction.
+	ID string
+	// Checkpoint is the name of the checkpoint.
+	Checkpoint string
+	// Timestamp is the time of the checkpoint in unix nanoseconds.
+	Timestamp int64
+}
+
 // StatsBucket specifies a set of stats computed over a duration.
 type StatsBucket struct {
 	// Start specifies the beginning of this bucket in unix nanoseconds.
@@ -54,6 +84,10 @@ type StatsBucket struct {
 	Stats []StatsPoint
 	// Backlogs store information used to compute queue backlog
 	Backlogs []Backlog
+	// Schemas holds the message schemas sampled during this bucket.
+	Schemas []Schema
+	// Transactions holds the transaction checkpoints tracked during this bucket.
+	Transactions []Transaction
 }
 
 // TimestampType can be either current or origin.
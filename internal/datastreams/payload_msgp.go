@@ -297,6 +297,159 @@ func (z ProduceOffset) Msgsize() (s int) {
 	return
 }
 
+// DecodeMsg implements msgp.Decodable
+func (z *Schema) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Topic":
+			z.Topic, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Topic")
+				return
+			}
+		case "Operation":
+			z.Operation, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Operation")
+				return
+			}
+		case "Type":
+			z.Type, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Type")
+				return
+			}
+		case "ID":
+			z.ID, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "ID")
+				return
+			}
+		case "Name":
+			z.Name, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Name")
+				return
+			}
+		case "Definition":
+			z.Definition, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Definition")
+				return
+			}
+		case "Weight":
+			z.Weight, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "Weight")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *Schema) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 7
+	// write "Topic"
+	err = en.Append(0x87, 0xa5, 0x54, 0x6f, 0x70, 0x69, 0x63)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Topic)
+	if err != nil {
+		err = msgp.WrapError(err, "Topic")
+		return
+	}
+	// write "Operation"
+	err = en.Append(0xa9, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Operation)
+	if err != nil {
+		err = msgp.WrapError(err, "Operation")
+		return
+	}
+	// write "Type"
+	err = en.Append(0xa4, 0x54, 0x79, 0x70, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Type)
+	if err != nil {
+		err = msgp.WrapError(err, "Type")
+		return
+	}
+	// write "ID"
+	err = en.Append(0xa2, 0x49, 0x44)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.ID)
+	if err != nil {
+		err = msgp.WrapError(err, "ID")
+		return
+	}
+	// write "Name"
+	err = en.Append(0xa4, 0x4e, 0x61, 0x6d, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Name)
+	if err != nil {
+		err = msgp.WrapError(err, "Name")
+		return
+	}
+	// write "Definition"
+	err = en.Append(0xaa, 0x44, 0x65, 0x66, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x6f, 0x6e)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Definition)
+	if err != nil {
+		err = msgp.WrapError(err, "Definition")
+		return
+	}
+	// write "Weight"
+	err = en.Append(0xa6, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.Weight)
+	if err != nil {
+		err = msgp.WrapError(err, "Weight")
+		return
+	}
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *Schema) Msgsize() (s int) {
+	s = 1 + 6 + msgp.StringPrefixSize + len(z.Topic) + 10 + msgp.StringPrefixSize + len(z.Operation) + 5 + msgp.StringPrefixSize + len(z.Type) + 3 + msgp.StringPrefixSize + len(z.ID) + 5 + msgp.StringPrefixSize + len(z.Name) + 11 + msgp.StringPrefixSize + len(z.Definition) + 7 + msgp.Int64Size
+	return
+}
+
 // DecodeMsg implements msgp.Decodable
 func (z *StatsBucket) DecodeMsg(dc *msgp.Reader) (err error) {
 	var field []byte
@@ -407,6 +560,79 @@ func (z *StatsBucket) DecodeMsg(dc *msgp.Reader) (err error) {
 					}
 				}
 			}
+		case "Schemas":
+			var zb0006 uint32
+			zb0006, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Schemas")
+				return
+			}
+			if cap(z.Schemas) >= int(zb0006) {
+				z.Schemas = (z.Schemas)[:zb0006]
+			} else {
+				z.Schemas = make([]Schema, zb0006)
+			}
+			for za0004 := range z.Schemas {
+				err = z.Schemas[za0004].DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Schemas", za0004)
+					return
+				}
+			}
+		case "Transactions":
+			var zb0007 uint32
+			zb0007, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Transactions")
+				return
+			}
+			if cap(z.Transactions) >= int(zb0007) {
+				z.Transactions = (z.Transactions)[:zb0007]
+			} else {
+				z.Transactions = make([]Transaction, zb0007)
+			}
+			for za0005 := range z.Transactions {
+				var zb0008 uint32
+				zb0008, err = dc.ReadMapHeader()
+				if err != nil {
+					err = msgp.WrapError(err, "Transactions", za0005)
+					return
+				}
+				for zb0008 > 0 {
+					zb0008--
+					field, err = dc.ReadMapKeyPtr()
+					if err != nil {
+						err = msgp.WrapError(err, "Transactions", za0005)
+						return
+					}
+					switch msgp.UnsafeString(field) {
+					case "ID":
+						z.Transactions[za0005].ID, err = dc.ReadString()
+						if err != nil {
+							err = msgp.WrapError(err, "Transactions", za0005, "ID")
+							return
+						}
+					case "Checkpoint":
+						z.Transactions[za0005].Checkpoint, err = dc.ReadString()
+						if err != nil {
+							err = msgp.WrapError(err, "Transactions", za0005, "Checkpoint")
+							return
+						}
+					case "Timestamp":
+						z.Transactions[za0005].Timestamp, err = dc.ReadInt64()
+						if err != nil {
+							err = msgp.WrapError(err, "Transactions", za0005, "Timestamp")
+							return
+						}
+					default:
+						err = dc.Skip()
+						if err != nil {
+							err = msgp.WrapError(err, "Transactions", za0005)
+							return
+						}
+					}
+				}
+			}
 		default:
 			err = dc.Skip()
 			if err != nil {
@@ -420,9 +646,9 @@ func (z *StatsBucket) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *StatsBucket) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 4
+	// map header, size 6
 	// write "Start"
-	err = en.Append(0x84, 0xa5, 0x53, 0x74, 0x61, 0x72, 0x74)
+	err = en.Append(0x86, 0xa5, 0x53, 0x74, 0x61, 0x72, 0x74)
 	if err != nil {
 		return
 	}
@@ -498,6 +724,66 @@ func (z *StatsBucket) EncodeMsg(en *msgp.Writer) (err error) {
 			return
 		}
 	}
+	// write "Schemas"
+	err = en.Append(0xa7, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Schemas)))
+	if err != nil {
+		err = msgp.WrapError(err, "Schemas")
+		return
+	}
+	for za0004 := range z.Schemas {
+		err = z.Schemas[za0004].EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Schemas", za0004)
+			return
+		}
+	}
+	// write "Transactions"
+	err = en.Append(0xac, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Transactions)))
+	if err != nil {
+		err = msgp.WrapError(err, "Transactions")
+		return
+	}
+	for za0005 := range z.Transactions {
+		// map header, size 3
+		// write "ID"
+		err = en.Append(0x83, 0xa2, 0x49, 0x44)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.Transactions[za0005].ID)
+		if err != nil {
+			err = msgp.WrapError(err, "Transactions", za0005, "ID")
+			return
+		}
+		// write "Checkpoint"
+		err = en.Append(0xaa, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.Transactions[za0005].Checkpoint)
+		if err != nil {
+			err = msgp.WrapError(err, "Transactions", za0005, "Checkpoint")
+			return
+		}
+		// write "Timestamp"
+		err = en.Append(0xa9, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70)
+		if err != nil {
+			return
+		}
+		err = en.WriteInt64(z.Transactions[za0005].Timestamp)
+		if err != nil {
+			err = msgp.WrapError(err, "Transactions", za0005, "Timestamp")
+			return
+		}
+	}
 	return
 }
 
@@ -515,6 +801,14 @@ func (z *StatsBucket) Msgsize() (s int) {
 		}
 		s += 6 + msgp.Int64Size
 	}
+	s += 8 + msgp.ArrayHeaderSize
+	for za0004 := range z.Schemas {
+		s += z.Schemas[za0004].Msgsize()
+	}
+	s += 13 + msgp.ArrayHeaderSize
+	for za0005 := range z.Transactions {
+		s += 1 + 3 + msgp.StringPrefixSize + len(z.Transactions[za0005].ID) + 11 + msgp.StringPrefixSize + len(z.Transactions[za0005].Checkpoint) + 10 + msgp.Int64Size
+	}
 	return
 }
 
@@ -905,3 +1199,92 @@ func (z TimestampType) Msgsize() (s int) {
 	s = msgp.StringPrefixSize + len(string(z))
 	return
 }
+
+// DecodeMsg implements msgp.Decodable
+func (z *Transaction) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "ID":
+			z.ID, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "ID")
+				return
+			}
+		case "Checkpoint":
+			z.Checkpoint, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Checkpoint")
+				return
+			}
+		case "Timestamp":
+			z.Timestamp, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "Timestamp")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z Transaction) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 3
+	// write "ID"
+	err = en.Append(0x83, 0xa2, 0x49, 0x44)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.ID)
+	if err != nil {
+		err = msgp.WrapError(err, "ID")
+		return
+	}
+	// write "Checkpoint"
+	err = en.Append(0xaa, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Checkpoint)
+	if err != nil {
+		err = msgp.WrapError(err, "Checkpoint")
+		return
+	}
+	// write "Timestamp"
+	err = en.Append(0xa9, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.Timestamp)
+	if err != nil {
+		err = msgp.WrapError(err, "Timestamp")
+		return
+	}
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z Transaction) Msgsize() (s int) {
+	s = 1 + 3 + msgp.StringPrefixSize + len(z.ID) + 11 + msgp.StringPrefixSize + len(z.Checkpoint) + 10 + msgp.Int64Size
+	return
+}
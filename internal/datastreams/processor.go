@@ -11,6 +11,7 @@ import (
 	"math"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -29,6 +30,13 @@ import (
 const (
 	bucketDuration     = time.Second * 10
 	defaultServiceName = "unnamed-go-service"
+	// schemaSampleInterval is the minimum interval between two samples of the
+	// message schema of a topic and operation.
+	schemaSampleInterval = time.Second * 30
+	// consumerLagExpiry is the duration after which the consumer lag of a
+	// partition is no longer reported when its committed offset isn't
+	// updated, such as when the partition was revoked from the consumer.
+	consumerLagExpiry = time.Minute * 5
 )
 
 // use the same gamma and index offset as the Datadog backend, to avoid doing any conversions in
@@ -43,6 +51,7 @@ type statsPoint struct {
 	pathwayLatency int64
 	edgeLatency    int64
 	payloadSize    int64
+	schema         *options.Schema
 }
 
 type statsGroup struct {
@@ -60,6 +69,8 @@ type bucket struct {
 	latestCommitOffsets        map[partitionConsumerKey]int64
 	latestProduceOffsets       map[partitionKey]int64
 	latestHighWatermarkOffsets map[partitionKey]int64
+	schemas                    []Schema
+	transactions               []Transaction
 	start                      uint64
 	duration                   uint64
 }
@@ -105,10 +116,12 @@ func (b bucket) export(timestampType TimestampType) StatsBucket {
 		})
 	}
 	exported := StatsBucket{
-		Start:    b.start,
-		Duration: b.duration,
-		Stats:    stats,
-		Backlogs: make([]Backlog, 0, len(b.latestCommitOffsets)+len(b.latestProduceOffsets)+len(b.latestHighWatermarkOffsets)),
+		Start:        b.start,
+		Duration:     b.duration,
+		Stats:        stats,
+		Backlogs:     make([]Backlog, 0, len(b.latestCommitOffsets)+len(b.latestProduceOffsets)+len(b.latestHighWatermarkOffsets)),
+		Schemas:      b.schemas,
+		Transactions: b.transactions,
 	}
 	for key, offset := range b.latestProduceOffsets {
 		exported.Backlogs = append(exported.Backlogs, Backlog{Tags: []string{fmt.Sprintf("partition:%d", key.partition), fmt.Sprintf("topic:%s", key.topic), "type:kafka_produce"}, Value: offset})
@@ -127,11 +140,13 @@ type pointType int
 const (
 	pointTypeStats pointType = iota
 	pointTypeKafkaOffset
+	pointTypeTransaction
 )
 
 type processorInput struct {
 	point       statsPoint
 	kafkaOffset kafkaOffset
+	transaction transaction
 	typ         pointType
 	queuePos    int64
 }
@@ -155,6 +170,30 @@ type partitionConsumerKey struct {
 	group     string
 }
 
+type schemaKey struct {
+	topic     string
+	operation string
+}
+
+// schemaSampler keeps track of the checkpoints with a schema of a topic and
+// operation since the last sample.
+type schemaSampler struct {
+	lastSample int64
+	weight     int64
+}
+
+// consumerLag keeps track of the latest offsets used to compute the consumer
+// lag metrics.
+type consumerLag struct {
+	commits        map[partitionConsumerKey]committedOffset
+	highWatermarks map[partitionKey]int64
+}
+
+type committedOffset struct {
+	offset    int64
+	timestamp int64
+}
+
 type offsetType int
 
 const (
@@ -178,6 +217,9 @@ type Processor struct {
 	inKafka              chan kafkaOffset
 	tsTypeCurrentBuckets map[int64]bucket
 	tsTypeOriginBuckets  map[int64]bucket
+	schemaSamplers       map[schemaKey]*schemaSampler
+	consumerLag          *consumerLag // nil unless enabled with EnableConsumerLagMetrics
+	transactions         transactionConfig
 	wg                   sync.WaitGroup
 	stopped              uint64
 	stop                 chan struct{} // closing this channel triggers shutdown
@@ -207,6 +249,8 @@ func NewProcessor(statsd internal.StatsdClient, env, service, version string, ag
 	p := &Processor{
 		tsTypeCurrentBuckets: make(map[int64]bucket),
 		tsTypeOriginBuckets:  make(map[int64]bucket),
+		schemaSamplers:       make(map[schemaKey]*schemaSampler),
+		transactions:         defaultTransactionConfig(),
 		hashCache:            newHashCache(),
 		in:                   newFastQueue(),
 		stopped:              1,
@@ -263,9 +307,55 @@ func (p *Processor) add(point statsPoint) {
 	originTimestamp := point.timestamp - point.pathwayLatency
 	originBucketTime := alignTs(originTimestamp, bucketDuration.Nanoseconds())
 	p.addToBuckets(point, originBucketTime, p.tsTypeOriginBuckets)
+	if point.schema != nil {
+		p.sampleSchema(point, currentBucketTime)
+	}
+}
+
+// sampleSchema samples the schema of the given point at most once every
+// schemaSampleInterval per topic and operation. The weight of a sample is the
+// number of checkpoints with a schema since the previous sample.
+func (p *Processor) sampleSchema(point statsPoint, btime int64) {
+	var key schemaKey
+	for _, tag := range point.edgeTags {
+		switch {
+		case strings.HasPrefix(tag, "topic:"):
+			key.topic = strings.TrimPrefix(tag, "topic:")
+		case tag == "direction:out":
+			key.operation = "produce"
+		case tag == "direction:in":
+			key.operation = "consume"
+		}
+	}
+	sampler, ok := p.schemaSamplers[key]
+	if !ok {
+		sampler = &schemaSampler{}
+		p.schemaSamplers[key] = sampler
+	}
+	sampler.weight++
+	if ok && point.timestamp-sampler.lastSample < schemaSampleInterval.Nanoseconds() {
+		return
+	}
+	b := p.getBucket(btime, p.tsTypeCurrentBuckets)
+	b.schemas = append(b.schemas, Schema{
+		Topic:      key.topic,
+		Operation:  key.operation,
+		Type:       string(point.schema.Type),
+		ID:         point.schema.ID,
+		Name:       point.schema.Name,
+		Definition: point.schema.Definition,
+		Weight:     sampler.weight,
+	})
+	// buckets are stored by value
+	p.tsTypeCurrentBuckets[btime] = b
+	sampler.lastSample = point.timestamp
+	sampler.weight = 0
 }
 
 func (p *Processor) addKafkaOffset(o kafkaOffset) {
+	if p.consumerLag != nil {
+		p.trackConsumerLag(o)
+	}
 	btime := alignTs(o.timestamp, bucketDuration.Nanoseconds())
 	b := p.getBucket(btime, p.tsTypeCurrentBuckets)
 	if o.offsetType == produceOffset {
@@ -289,12 +379,65 @@ func (p *Processor) addKafkaOffset(o kafkaOffset) {
 	}] = o.offset
 }
 
+// EnableConsumerLagMetrics enables the computation of the lag of the Kafka
+// consumer groups out of the tracked high watermark and committed offsets, and
+// its report as DogStatsD gauges. It must be called before Start.
+func (p *Processor) EnableConsumerLagMetrics() {
+	p.consumerLag = &consumerLag{
+		commits:        make(map[partitionConsumerKey]committedOffset),
+		highWatermarks: make(map[partitionKey]int64),
+	}
+}
+
+func (p *Processor) trackConsumerLag(o kafkaOffset) {
+	switch o.offsetType {
+	case commitOffset:
+		p.consumerLag.commits[partitionConsumerKey{
+			partition: o.partition,
+			group:     o.group,
+			topic:     o.topic,
+		}] = committedOffset{offset: o.offset, timestamp: o.timestamp}
+	case highWatermarkOffset:
+		p.consumerLag.highWatermarks[partitionKey{
+			partition: o.partition,
+			topic:     o.topic,
+		}] = o.offset
+	}
+}
+
+// reportConsumerLag reports the lag of every consumer group partition whose
+// high watermark and committed offsets are known, as the difference between
+// the two.
+func (p *Processor) reportConsumerLag(now time.Time) {
+	if p.consumerLag == nil || p.statsd == nil {
+		return
+	}
+	for key, commit := range p.consumerLag.commits {
+		if now.UnixNano()-commit.timestamp > consumerLagExpiry.Nanoseconds() {
+			delete(p.consumerLag.commits, key)
+			continue
+		}
+		highWatermark, ok := p.consumerLag.highWatermarks[partitionKey{partition: key.partition, topic: key.topic}]
+		if !ok {
+			continue
+		}
+		lag := highWatermark - commit.offset
+		if lag < 0 {
+			lag = 0
+		}
+		tags := []string{"consumer_group:" + key.group, "topic:" + key.topic, fmt.Sprintf("partition:%d", key.partition)}
+		p.statsd.Gauge("datadog.datastreams.kafka.consumer_lag", float64(lag), tags, 1)
+	}
+}
+
 func (p *Processor) processInput(in *processorInput) {
 	atomic.AddInt64(&p.stats.payloadsIn, 1)
 	if in.typ == pointTypeStats {
 		p.add(in.point)
 	} else if in.typ == pointTypeKafkaOffset {
 		p.addKafkaOffset(in.kafkaOffset)
+	} else if in.typ == pointTypeTransaction {
+		p.addTransaction(in.transaction)
 	}
 }
 
@@ -313,6 +456,7 @@ func (p *Processor) run(tick <-chan time.Time) {
 		select {
 		case now := <-tick:
 			p.sendToAgent(p.flush(now))
+			p.reportConsumerLag(now)
 		case done := <-p.flushRequest:
 			p.flushInput()
 			p.sendToAgent(p.flush(time.Now().Add(bucketDuration * 10)))
@@ -450,6 +594,7 @@ func (p *Processor) SetCheckpointWithParams(ctx context.Context, params options.
 		pathwayLatency: now.Sub(pathwayStart).Nanoseconds(),
 		edgeLatency:    now.Sub(edgeStart).Nanoseconds(),
 		payloadSize:    params.PayloadSize,
+		schema:         params.Schema,
 	}})
 	if dropped {
 		atomic.AddInt64(&p.stats.dropped, 1)
@@ -7,6 +7,7 @@ package datastreams
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/url"
 	"sort"
@@ -244,6 +245,75 @@ func TestKafkaLag(t *testing.T) {
 	assert.Equal(t, expectedBacklogs, point.Stats[0].Backlogs)
 }
 
+func TestSchemaSampling(t *testing.T) {
+	p := NewProcessor(nil, "env", "service", "v1", &url.URL{Scheme: "http", Host: "agent-address"}, nil)
+	tp1 := time.Now().Truncate(bucketDuration)
+	schema1 := &options.Schema{Type: options.SchemaTypeAvro, ID: "1", Name: "com.example.Order", Definition: `{"type":"record"}`}
+	schema2 := &options.Schema{Type: options.SchemaTypeAvro, ID: "2", Name: "com.example.Order", Definition: `{"type":"record","fields":[]}`}
+	add := func(ts time.Time, schema *options.Schema, edgeTags ...string) {
+		p.add(statsPoint{edgeTags: edgeTags, hash: 1, timestamp: ts.UnixNano(), schema: schema})
+	}
+
+	add(tp1, schema1, "direction:out", "topic:topic1", "type:kafka")
+	add(tp1.Add(time.Second), schema1, "direction:out", "topic:topic1", "type:kafka")
+	add(tp1.Add(time.Second), nil, "direction:out", "topic:topic1", "type:kafka")
+	add(tp1.Add(time.Second), schema1, "direction:in", "topic:topic1", "type:kafka")
+	add(tp1.Add(schemaSampleInterval), schema2, "direction:out", "topic:topic1", "type:kafka")
+
+	got := p.flush(tp1.Add(schemaSampleInterval + bucketDuration))
+	var schemas []Schema
+	for _, b := range got.Stats {
+		schemas = append(schemas, b.Schemas...)
+	}
+	sort.SliceStable(schemas, func(i, j int) bool { return schemas[i].ID < schemas[j].ID })
+	assert.Equal(t, []Schema{
+		{Topic: "topic1", Operation: "produce", Type: "avro", ID: "1", Name: "com.example.Order", Definition: `{"type":"record"}`, Weight: 1},
+		{Topic: "topic1", Operation: "consume", Type: "avro", ID: "1", Name: "com.example.Order", Definition: `{"type":"record"}`, Weight: 1},
+		{Topic: "topic1", Operation: "produce", Type: "avro", ID: "2", Name: "com.example.Order", Definition: `{"type":"record","fields":[]}`, Weight: 2},
+	}, schemas)
+}
+
+type gaugeRecorder struct {
+	statsd.NoOpClient
+	gauges map[string]float64
+}
+
+func (r *gaugeRecorder) Gauge(name string, value float64, tags []string, _ float64) error {
+	r.gauges[name+"|"+strings.Join(tags, ",")] = value
+	return nil
+}
+
+func TestConsumerLag(t *testing.T) {
+	recorder := &gaugeRecorder{gauges: make(map[string]float64)}
+	p := NewProcessor(recorder, "env", "service", "v1", &url.URL{Scheme: "http", Host: "agent-address"}, nil)
+	now := time.Now()
+
+	// disabled by default
+	p.addKafkaOffset(kafkaOffset{offset: 10, topic: "topic1", partition: 1, offsetType: highWatermarkOffset, timestamp: now.UnixNano()})
+	p.addKafkaOffset(kafkaOffset{offset: 5, topic: "topic1", partition: 1, group: "group1", offsetType: commitOffset, timestamp: now.UnixNano()})
+	p.reportConsumerLag(now)
+	assert.Empty(t, recorder.gauges)
+
+	p.EnableConsumerLagMetrics()
+	p.addKafkaOffset(kafkaOffset{offset: 10, topic: "topic1", partition: 1, offsetType: highWatermarkOffset, timestamp: now.UnixNano()})
+	p.addKafkaOffset(kafkaOffset{offset: 20, topic: "topic1", partition: 2, offsetType: highWatermarkOffset, timestamp: now.UnixNano()})
+	p.addKafkaOffset(kafkaOffset{offset: 5, topic: "topic1", partition: 1, group: "group1", offsetType: commitOffset, timestamp: now.UnixNano()})
+	p.addKafkaOffset(kafkaOffset{offset: 7, topic: "topic1", partition: 1, group: "group2", offsetType: commitOffset, timestamp: now.UnixNano()})
+	p.addKafkaOffset(kafkaOffset{offset: 20, topic: "topic1", partition: 2, group: "group1", offsetType: commitOffset, timestamp: now.UnixNano()})
+	// no high watermark
+	p.addKafkaOffset(kafkaOffset{offset: 3, topic: "topic2", partition: 1, group: "group1", offsetType: commitOffset, timestamp: now.UnixNano()})
+	// expired
+	p.addKafkaOffset(kafkaOffset{offset: 1, topic: "topic1", partition: 1, group: "group3", offsetType: commitOffset, timestamp: now.Add(-consumerLagExpiry - time.Second).UnixNano()})
+	p.reportConsumerLag(now)
+
+	assert.Equal(t, map[string]float64{
+		"datadog.datastreams.kafka.consumer_lag|consumer_group:group1,topic:topic1,partition:1": 5,
+		"datadog.datastreams.kafka.consumer_lag|consumer_group:group2,topic:topic1,partition:1": 3,
+		"datadog.datastreams.kafka.consumer_lag|consumer_group:group1,topic:topic1,partition:2": 0,
+	}, recorder.gauges)
+	assert.Len(t, p.consumerLag.commits, 4)
+}
+
 type noOpTransport struct{}
 
 // RoundTrip does nothing and returns a dummy response.
@@ -271,3 +341,67 @@ func BenchmarkSetCheckpoint(b *testing.B) {
 	}
 	p.Stop()
 }
+
+func TestTransactions(t *testing.T) {
+	tp1 := time.Now().Truncate(bucketDuration)
+	newProcessor := func(sampleRate float64, disabledCheckpoints ...string) *Processor {
+		p := NewProcessor(nil, "env", "service", "v1", &url.URL{Scheme: "http", Host: "agent-address"}, nil)
+		p.timeSource = func() time.Time { return tp1 }
+		p.ConfigureTransactions(sampleRate, disabledCheckpoints)
+		return p
+	}
+	flush := func(p *Processor) []Transaction {
+		for in := p.in.pop(); in != nil; in = p.in.pop() {
+			p.processInput(in)
+		}
+		var transactions []Transaction
+		for _, b := range p.flush(tp1.Add(bucketDuration)).Stats {
+			transactions = append(transactions, b.Transactions...)
+		}
+		return transactions
+	}
+
+	t.Run("tracked", func(t *testing.T) {
+		p := newProcessor(1, "disabled")
+		p.TrackTransaction("order-1", "created")
+		p.TrackTransaction("order-1", "disabled")
+		p.TrackTransaction(strings.Repeat("a", maxTransactionIDLength+1), "shipped")
+
+		assert.Equal(t, []Transaction{
+			{ID: "order-1", Checkpoint: "created", Timestamp: tp1.UnixNano()},
+			{ID: strings.Repeat("a", maxTransactionIDLength), Checkpoint: "shipped", Timestamp: tp1.UnixNano()},
+		}, flush(p))
+	})
+
+	t.Run("sampled", func(t *testing.T) {
+		p := newProcessor(0)
+		p.TrackTransaction("order-1", "created")
+		assert.Empty(t, flush(p))
+
+		p = newProcessor(0.5)
+		ids := make(map[string]bool)
+		for i := 0; i < 100; i++ {
+			id := fmt.Sprintf("order-%d", i)
+			p.TrackTransaction(id, "created")
+			p.TrackTransaction(id, "shipped")
+		}
+		transactions := flush(p)
+		assert.NotEmpty(t, transactions)
+		assert.Less(t, len(transactions), 200)
+		for _, tr := range transactions {
+			ids[tr.ID] = !ids[tr.ID]
+		}
+		for id, odd := range ids {
+			assert.False(t, odd, "transaction %s is sampled at one checkpoint only", id)
+		}
+	})
+
+	t.Run("max-per-bucket", func(t *testing.T) {
+		p := newProcessor(1)
+		for i := 0; i < maxTransactionsPerBucket+1; i++ {
+			p.addTransaction(transaction{id: "order-1", checkpoint: "created", timestamp: tp1.UnixNano()})
+		}
+		assert.Len(t, flush(p), maxTransactionsPerBucket)
+		assert.Equal(t, int64(1), p.stats.dropped)
+	})
+}
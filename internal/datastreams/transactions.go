@@ -0,0 +1,107 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package datastreams
+
+import (
+	"hash/fnv"
+	"math"
+	"sync/atomic"
+)
+
+const (
+	// maxTransactionsPerBucket bounds the number of transaction checkpoints
+	// kept per bucket. The checkpoints tracked beyond it are dropped.
+	maxTransactionsPerBucket = 10000
+	// maxTransactionIDLength is the maximum length of the transaction IDs,
+	// which are truncated beyond it.
+	maxTransactionIDLength = 256
+)
+
+type transaction struct {
+	id         string
+	checkpoint string
+	timestamp  int64
+}
+
+// transactionConfig holds the transaction tracking configuration.
+type transactionConfig struct {
+	// sampleThreshold is compared to the hash of the transaction IDs, so that
+	// every service samples the same transactions.
+	sampleThreshold     uint64
+	disabledCheckpoints map[string]struct{}
+}
+
+func defaultTransactionConfig() transactionConfig {
+	return transactionConfig{sampleThreshold: math.MaxUint64}
+}
+
+// ConfigureTransactions configures the tracking of the transaction
+// checkpoints. Transactions are sampled out of their IDs at the given rate, so
+// that a sampled transaction is sampled at every checkpoint of every service
+// using the same rate. The tracking of the given checkpoint names is disabled.
+// It must be called before Start.
+func (p *Processor) ConfigureTransactions(sampleRate float64, disabledCheckpoints []string) {
+	cfg := transactionConfig{disabledCheckpoints: make(map[string]struct{}, len(disabledCheckpoints))}
+	switch {
+	case sampleRate >= 1:
+		cfg.sampleThreshold = math.MaxUint64
+	case sampleRate > 0:
+		cfg.sampleThreshold = uint64(sampleRate * math.MaxUint64)
+	}
+	for _, name := range disabledCheckpoints {
+		cfg.disabledCheckpoints[name] = struct{}{}
+	}
+	p.transactions = cfg
+}
+
+// TrackTransaction tracks the given checkpoint of the transaction identified
+// by transactionID, such as a business identifier like an order ID, when it is
+// sampled and the checkpoint isn't disabled. The transaction checkpoints are
+// reported along with the stats of the bucket they fall in.
+func (p *Processor) TrackTransaction(transactionID, checkpointName string) {
+	if _, ok := p.transactions.disabledCheckpoints[checkpointName]; ok {
+		return
+	}
+	if !p.transactions.sampled(transactionID) {
+		return
+	}
+	if len(transactionID) > maxTransactionIDLength {
+		transactionID = transactionID[:maxTransactionIDLength]
+	}
+	dropped := p.in.push(&processorInput{typ: pointTypeTransaction, transaction: transaction{
+		id:         transactionID,
+		checkpoint: checkpointName,
+		timestamp:  p.time().UnixNano(),
+	}})
+	if dropped {
+		atomic.AddInt64(&p.stats.dropped, 1)
+	}
+}
+
+func (c transactionConfig) sampled(transactionID string) bool {
+	if c.sampleThreshold == math.MaxUint64 {
+		return true
+	}
+	h := fnv.New64a()
+	h.Write([]byte(transactionID))
+	return h.Sum64() < c.sampleThreshold
+}
+
+func (p *Processor) addTransaction(t transaction) {
+	btime := alignTs(t.timestamp, bucketDuration.Nanoseconds())
+	b := p.getBucket(btime, p.tsTypeCurrentBuckets)
+	if len(b.transactions) >= maxTransactionsPerBucket {
+		atomic.AddInt64(&p.stats.dropped, 1)
+		return
+	}
+	b.transactions = append(b.transactions, Transaction{
+		ID:         t.id,
+		Checkpoint: t.checkpoint,
+		Timestamp:  t.timestamp,
+	})
+	// buckets are stored by value
+	p.tsTypeCurrentBuckets[btime] = b
+}
@@ -27,6 +27,10 @@ const (
 	KafkaInbound
 	GCPPubSubInbound
 	GCPPubSubOutbound
+	RabbitMQOutbound
+	RabbitMQInbound
+	NATSOutbound
+	NATSInbound
 
 	// cache
 	MemcachedOutbound
@@ -69,6 +73,14 @@ func opV1(t IntegrationType) string {
 		return "gcp.pubsub.process"
 	case GCPPubSubOutbound:
 		return "gcp.pubsub.send"
+	case RabbitMQOutbound:
+		return "rabbitmq.send"
+	case RabbitMQInbound:
+		return "rabbitmq.process"
+	case NATSOutbound:
+		return "nats.send"
+	case NATSInbound:
+		return "nats.process"
 
 	// Cache
 	case MemcachedOutbound:
@@ -117,6 +129,14 @@ func opV0(t IntegrationType) string {
 		return "pubsub.receive"
 	case GCPPubSubOutbound:
 		return "pubsub.publish"
+	case RabbitMQOutbound:
+		return "amqp.publish"
+	case RabbitMQInbound:
+		return "amqp.consume"
+	case NATSOutbound:
+		return "nats.publish"
+	case NATSInbound:
+		return "nats.consume"
 	case MemcachedOutbound:
 		return "memcached.query"
 	case RedisOutbound:
@@ -54,6 +54,38 @@ func TestOpName(t *testing.T) {
 			wantV0: "pubsub.receive",
 			wantV1: "gcp.pubsub.process",
 		},
+		{
+			name: "rabbitmq outbound",
+			newSchema: func() string {
+				return namingschema.OpName(namingschema.RabbitMQOutbound)
+			},
+			wantV0: "amqp.publish",
+			wantV1: "rabbitmq.send",
+		},
+		{
+			name: "rabbitmq inbound",
+			newSchema: func() string {
+				return namingschema.OpName(namingschema.RabbitMQInbound)
+			},
+			wantV0: "amqp.consume",
+			wantV1: "rabbitmq.process",
+		},
+		{
+			name: "nats outbound",
+			newSchema: func() string {
+				return namingschema.OpName(namingschema.NATSOutbound)
+			},
+			wantV0: "nats.publish",
+			wantV1: "nats.send",
+		},
+		{
+			name: "nats inbound",
+			newSchema: func() string {
+				return namingschema.OpName(namingschema.NATSInbound)
+			},
+			wantV0: "nats.consume",
+			wantV1: "nats.process",
+		},
 		{
 			name: "override",
 			newSchema: func() string {
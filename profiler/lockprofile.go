@@ -0,0 +1,224 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package profiler
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+	"gopkg.in/DataDog/dd-trace-go.v1/profiler/internal"
+
+	pprofile "github.com/google/pprof/profile"
+)
+
+const (
+	// maxAdaptiveMutexFraction is the coarsest mutex profile fraction the
+	// adaptive lock profiler will back off to.
+	maxAdaptiveMutexFraction = 100000
+	// maxAdaptiveBlockRate is the coarsest block profile rate (in ns) the
+	// adaptive lock profiler will back off to.
+	maxAdaptiveBlockRate = int(time.Second)
+	// lockSampleCalibrationRounds is the number of stack unwinds timed when
+	// estimating the cost of recording a single lock profile sample.
+	lockSampleCalibrationRounds = 1000
+)
+
+// lockProfileController adjusts the mutex and block profile rates at the end
+// of every profiling period so that the estimated CPU overhead of lock
+// contention profiling stays within a budget.
+//
+// The rates configured with MutexProfileFraction and BlockProfileRate are the
+// most detailed rates the controller will use. When the budget is exceeded the
+// controller samples fewer events, and it moves back towards the configured
+// rates once the overhead drops well below the budget.
+//
+// Since Go 1.21 the runtime up-scales mutex and block events by the sampling
+// rate in effect when each event is recorded, so the values of a delta profile
+// are unbiased estimates of the real contention no matter how the rate
+// changed. The controller only changes a rate right after the profile of that
+// type has been collected, which guarantees that every period is sampled with
+// a single rate and that cross-period comparisons remain valid.
+type lockProfileController struct {
+	mu sync.Mutex
+	// budget is the maximum fraction of the available CPU time that may be
+	// spent recording and processing lock profile samples.
+	budget float64
+	// sampleCost is the estimated CPU time needed to record one sample.
+	sampleCost time.Duration
+	// minRates holds the configured, most detailed, rate per profile type.
+	minRates map[ProfileType]int
+	// rates holds the rate currently in effect per profile type.
+	rates map[ProfileType]int
+	// lastTotals holds the cumulative sample totals seen in the previous
+	// period, used when delta profiles are disabled.
+	lastTotals map[ProfileType]lockProfileTotals
+	// setRate applies a rate to the runtime; replaced in tests.
+	setRate func(pt ProfileType, rate int)
+}
+
+// lockProfileTotals holds the summed values of a mutex or block profile.
+type lockProfileTotals struct {
+	contentions float64
+	delay       float64 // nanoseconds
+}
+
+func newLockProfileController(budget float64, mutexFraction, blockRate int) *lockProfileController {
+	c := &lockProfileController{
+		budget:     budget,
+		sampleCost: calibrateLockSampleCost(),
+		minRates: map[ProfileType]int{
+			MutexProfile: max(mutexFraction, 1),
+			BlockProfile: max(blockRate, 1),
+		},
+		lastTotals: make(map[ProfileType]lockProfileTotals),
+		setRate:    setLockProfileRate,
+	}
+	c.rates = map[ProfileType]int{
+		MutexProfile: c.minRates[MutexProfile],
+		BlockProfile: c.minRates[BlockProfile],
+	}
+	return c
+}
+
+// calibrateLockSampleCost measures how long it takes to unwind a stack, which
+// dominates the cost of recording a mutex or block profile sample.
+func calibrateLockSampleCost() time.Duration {
+	pcs := make([]uintptr, 32)
+	sw := internal.NewStopwatch()
+	for i := 0; i < lockSampleCalibrationRounds; i++ {
+		runtime.Callers(0, pcs)
+	}
+	cost := sw.Tick() / lockSampleCalibrationRounds
+	if cost <= 0 {
+		cost = time.Microsecond
+	}
+	return cost
+}
+
+func setLockProfileRate(pt ProfileType, rate int) {
+	switch pt {
+	case MutexProfile:
+		runtime.SetMutexProfileFraction(rate)
+	case BlockProfile:
+		runtime.SetBlockProfileRate(rate)
+	}
+}
+
+// rate returns the sampling rate currently in effect for pt.
+func (c *lockProfileController) rate(pt ProfileType) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rates[pt]
+}
+
+// tags returns the tags describing the sampling rates in effect, so that the
+// profiles of a batch can be related to the rates they were collected with.
+func (c *lockProfileController) tags(types map[ProfileType]struct{}) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var tags []string
+	if _, ok := types[MutexProfile]; ok {
+		tags = append(tags, fmt.Sprintf("_dd.profiler.go_mutex_profile_fraction:%d", c.rates[MutexProfile]))
+	}
+	if _, ok := types[BlockProfile]; ok {
+		tags = append(tags, fmt.Sprintf("_dd.profiler.go_block_profile_rate:%d", c.rates[BlockProfile]))
+	}
+	return tags
+}
+
+// observe records the profile collected for pt at the end of a period, along
+// with the time spent collecting it, and applies the rate to use for the next
+// period. If cumulative is true, data holds the totals since the program
+// started rather than a delta profile.
+func (c *lockProfileController) observe(pt ProfileType, data []byte, cumulative bool, processing, period time.Duration) error {
+	totals, err := lockProfileSummary(data)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cumulative {
+		last := c.lastTotals[pt]
+		c.lastTotals[pt] = totals
+		totals.contentions -= last.contentions
+		totals.delay -= last.delay
+	}
+	rate := c.rates[pt]
+	samples := estimateLockSamples(pt, totals, rate)
+	available := float64(period) * float64(runtime.GOMAXPROCS(0))
+	if available <= 0 {
+		return nil
+	}
+	spent := float64(processing) + samples*float64(c.sampleCost)
+	overhead := spent / available
+
+	next := rate
+	switch ratio := overhead / c.budget; {
+	case ratio > 1:
+		next = int(math.Ceil(float64(rate) * ratio))
+	case ratio < 0.5:
+		next = rate / 2
+	}
+	maxRate := maxAdaptiveMutexFraction
+	if pt == BlockProfile {
+		maxRate = maxAdaptiveBlockRate
+	}
+	next = min(max(next, c.minRates[pt]), max(maxRate, c.minRates[pt]))
+	if next != rate {
+		log.Debug("profiler: adjusting %s profile rate from %d to %d (overhead %.4f, budget %.4f)", pt, rate, next, overhead, c.budget)
+		c.rates[pt] = next
+		c.setRate(pt, next)
+	}
+	return nil
+}
+
+// estimateLockSamples estimates how many events were sampled by the runtime
+// for the given profile totals. Mutex events are up-scaled by the fraction, so
+// the number of samples is exact. Block events are up-scaled to at least rate
+// nanoseconds of delay each, which gives an upper bound.
+func estimateLockSamples(pt ProfileType, totals lockProfileTotals, rate int) float64 {
+	if rate <= 0 || totals.contentions <= 0 {
+		return 0
+	}
+	switch pt {
+	case MutexProfile:
+		return totals.contentions / float64(rate)
+	case BlockProfile:
+		return math.Min(totals.contentions, totals.delay/float64(rate))
+	}
+	return 0
+}
+
+// lockProfileSummary sums the contentions and delay values of a mutex or
+// block profile in pprof format.
+func lockProfileSummary(data []byte) (lockProfileTotals, error) {
+	var totals lockProfileTotals
+	prof, err := pprofile.ParseData(data)
+	if err != nil {
+		return totals, fmt.Errorf("parsing lock profile: %v", err)
+	}
+	contentions, delay := -1, -1
+	for i, st := range prof.SampleType {
+		switch st.Type {
+		case "contentions":
+			contentions = i
+		case "delay":
+			delay = i
+		}
+	}
+	if contentions < 0 || delay < 0 {
+		return totals, fmt.Errorf("lock profile is missing contentions or delay values")
+	}
+	for _, s := range prof.Sample {
+		totals.contentions += float64(s.Value[contentions])
+		totals.delay += float64(s.Value[delay])
+	}
+	return totals, nil
+}
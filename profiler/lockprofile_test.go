@@ -0,0 +1,105 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024 Datadog, Inc.
+
+package profiler
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lockProfile(contentions, delay int64) []byte {
+	return textProfile{Text: fmt.Sprintf(`
+contentions/count delay/nanoseconds
+main;lock %d %d
+`, contentions, delay)}.Protobuf()
+}
+
+func TestLockProfileController(t *testing.T) {
+	newController := func(budget float64, mutexFraction, blockRate int) (*lockProfileController, map[ProfileType]int) {
+		applied := make(map[ProfileType]int)
+		c := newLockProfileController(budget, mutexFraction, blockRate)
+		c.sampleCost = time.Microsecond
+		c.setRate = func(pt ProfileType, rate int) { applied[pt] = rate }
+		return c, applied
+	}
+	period := time.Second
+	procs := float64(runtime.GOMAXPROCS(0))
+
+	t.Run("back-off", func(t *testing.T) {
+		c, applied := newController(0.01, 10, DefaultBlockRate)
+		// 4x the budget worth of samples.
+		samples := int64(4 * 0.01 * procs * float64(period/time.Microsecond))
+		err := c.observe(MutexProfile, lockProfile(samples*10, 1), false, 0, period)
+		require.NoError(t, err)
+		assert.InDelta(t, 40, c.rate(MutexProfile), 1)
+		assert.Equal(t, c.rate(MutexProfile), applied[MutexProfile])
+		assert.Equal(t, DefaultBlockRate, c.rate(BlockProfile))
+	})
+
+	t.Run("recover", func(t *testing.T) {
+		c, applied := newController(0.01, 10, DefaultBlockRate)
+		c.rates[MutexProfile] = 80
+		require.NoError(t, c.observe(MutexProfile, lockProfile(80, 1), false, 0, period))
+		assert.Equal(t, 40, c.rate(MutexProfile))
+		assert.Equal(t, 40, applied[MutexProfile])
+		c.rates[MutexProfile] = 12
+		require.NoError(t, c.observe(MutexProfile, lockProfile(12, 1), false, 0, period))
+		// never more detailed than the configured rate
+		assert.Equal(t, 10, c.rate(MutexProfile))
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		c, _ := newController(0.0001, 10, DefaultBlockRate)
+		samples := int64(100 * float64(period/time.Microsecond))
+		rate := int64(DefaultBlockRate)
+		require.NoError(t, c.observe(BlockProfile, lockProfile(samples, samples*rate), false, 0, period))
+		assert.Equal(t, maxAdaptiveBlockRate, c.rate(BlockProfile))
+	})
+
+	t.Run("steady", func(t *testing.T) {
+		c, applied := newController(0.01, 10, DefaultBlockRate)
+		require.NoError(t, c.observe(BlockProfile, lockProfile(0, 0), false, 0, period))
+		assert.Equal(t, DefaultBlockRate, c.rate(BlockProfile))
+		assert.Empty(t, applied)
+	})
+
+	t.Run("cumulative", func(t *testing.T) {
+		c, _ := newController(0.01, 10, DefaultBlockRate)
+		samples := int64(4 * 0.01 * procs * float64(period/time.Microsecond))
+		require.NoError(t, c.observe(MutexProfile, lockProfile(samples*10, 1), true, 0, period))
+		rate := c.rate(MutexProfile)
+		require.Greater(t, rate, 10)
+		// the cumulative profile did not grow, so no samples were taken
+		// during the second period and the controller recovers.
+		require.NoError(t, c.observe(MutexProfile, lockProfile(samples*10, 1), true, 0, period))
+		assert.Less(t, c.rate(MutexProfile), rate)
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		c, _ := newController(0.01, 10, DefaultBlockRate)
+		types := map[ProfileType]struct{}{MutexProfile: {}}
+		assert.Equal(t, []string{"_dd.profiler.go_mutex_profile_fraction:10"}, c.tags(types))
+	})
+}
+
+func TestAdaptiveLockProfiling(t *testing.T) {
+	p, err := unstartedProfiler(
+		MutexProfileFraction(10),
+		WithAdaptiveLockProfiling(0.01),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, p.lockProfiles)
+	assert.Equal(t, 10, p.lockProfiles.rate(MutexProfile))
+
+	p, err = unstartedProfiler(MutexProfileFraction(10))
+	require.NoError(t, err)
+	assert.Nil(t, p.lockProfiles)
+}
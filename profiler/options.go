@@ -106,6 +106,7 @@ type config struct {
 	maxGoroutinesWait    int
 	mutexFraction        int
 	blockRate            int
+	lockOverheadBudget   float64
 	outputDir            string
 	deltaProfiles        bool
 	logStartup           bool
@@ -139,6 +140,7 @@ func logStartup(c *config) {
 		"cpu_profile_rate":           c.cpuProfileRate,
 		"block_profile_rate":         c.blockRate,
 		"mutex_profile_fraction":     c.mutexFraction,
+		"lock_overhead_budget":       c.lockOverheadBudget,
 		"max_goroutines_wait":        c.maxGoroutinesWait,
 		"upload_timeout":             c.uploadTimeout.String(),
 		"execution_trace_enabled":    c.traceConfig.Enabled,
@@ -390,6 +392,19 @@ func BlockProfileRate(rate int) Option {
 	}
 }
 
+// WithAdaptiveLockProfiling turns on adaptive sampling for the mutex and block
+// profiles, if enabled. At the end of every profiling period the sampling rates
+// are adjusted so that the estimated CPU overhead of recording and collecting
+// lock contention samples stays below budget, given as a fraction of the
+// available CPU time (e.g. 0.01 for 1%). The rates set via MutexProfileFraction
+// and BlockProfileRate are the most detailed rates that will be used. A budget
+// of 0 or less disables adaptive sampling.
+func WithAdaptiveLockProfiling(budget float64) Option {
+	return func(cfg *config) {
+		cfg.lockOverheadBudget = budget
+	}
+}
+
 // WithProfileTypes specifies the profile types to be collected by the profiler.
 func WithProfileTypes(types ...ProfileType) Option {
 	return func(cfg *config) {
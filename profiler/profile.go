@@ -16,6 +16,8 @@ import (
 	"runtime/trace"
 	"time"
 
+	"gopkg.in/DataDog/dd-trace-go.v1/internal/log"
+	"gopkg.in/DataDog/dd-trace-go.v1/profiler/internal"
 	"gopkg.in/DataDog/dd-trace-go.v1/profiler/internal/fastdelta"
 	"gopkg.in/DataDog/dd-trace-go.v1/profiler/internal/pprofutils"
 
@@ -260,11 +262,15 @@ func collectGenericProfile(name string, pt ProfileType) func(p *profiler) ([]byt
 	return func(p *profiler) ([]byte, error) {
 		p.interruptibleSleep(p.cfg.period)
 
+		sw := internal.NewStopwatch()
 		var buf bytes.Buffer
 		err := p.lookupProfile(name, &buf, 0)
 		data := buf.Bytes()
 		dp, ok := p.deltas[pt]
 		if !ok || !p.cfg.deltaProfiles {
+			if err == nil {
+				p.adaptLockProfile(pt, data, true, sw)
+			}
 			return data, err
 		}
 
@@ -275,10 +281,23 @@ func collectGenericProfile(name string, pt ProfileType) func(p *profiler) ([]byt
 		if err != nil {
 			return nil, fmt.Errorf("delta profile error: %s", err)
 		}
+		p.adaptLockProfile(pt, delta, false, sw)
 		return delta, err
 	}
 }
 
+// adaptLockProfile lets the adaptive lock profiler, if enabled, adjust the
+// sampling rate of pt based on the profile collected for the period. The
+// stopwatch sw must have been started when the profile collection started.
+func (p *profiler) adaptLockProfile(pt ProfileType, data []byte, cumulative bool, sw *internal.Stopwatch) {
+	if p.lockProfiles == nil || (pt != MutexProfile && pt != BlockProfile) {
+		return
+	}
+	if err := p.lockProfiles.observe(pt, data, cumulative, sw.Tick(), p.cfg.period); err != nil {
+		log.Error("Error adapting %s profile rate: %v", pt, err)
+	}
+}
+
 // lookup returns t's profileType implementation.
 func (t ProfileType) lookup() profileType {
 	c, ok := profileTypes[t]
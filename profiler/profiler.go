@@ -83,8 +83,9 @@ type profiler struct {
 	wg              sync.WaitGroup    // wg waits for all goroutines to exit when stopping.
 	met             *metrics          // metric collector state
 	deltas          map[ProfileType]*fastDeltaProfiler
-	seq             uint64         // seq is the value of the profile_seq tag
-	pendingProfiles sync.WaitGroup // signal that profile collection is done, for stopping CPU profiling
+	seq             uint64                 // seq is the value of the profile_seq tag
+	pendingProfiles sync.WaitGroup         // signal that profile collection is done, for stopping CPU profiling
+	lockProfiles    *lockProfileController // adjusts mutex/block profile rates; nil unless adaptive lock profiling is enabled
 
 	testHooks testHooks
 
@@ -231,6 +232,9 @@ func newProfiler(opts ...Option) (*profiler, error) {
 			p.deltas[pt] = newFastDeltaProfiler(d...)
 		}
 	}
+	if cfg.lockOverheadBudget > 0 {
+		p.lockProfiles = newLockProfileController(cfg.lockOverheadBudget, cfg.mutexFraction, cfg.blockRate)
+	}
 	p.uploadFunc = p.upload
 	return &p, nil
 }
@@ -300,6 +304,11 @@ func (p *profiler) collect(ticker <-chan time.Time) {
 			},
 			customAttributes: p.cfg.customProfilerLabels,
 		}
+		if p.lockProfiles != nil {
+			// Record the rates this period is sampled with. They are only
+			// adjusted once the period's lock profiles have been collected.
+			bat.extraTags = append(bat.extraTags, p.lockProfiles.tags(p.cfg.types)...)
+		}
 		p.seq++
 
 		completed = completed[:0]